@@ -0,0 +1,88 @@
+// createadmin 创建第一个管理员，或将已有用户设为管理员
+//
+// 用法:
+//
+//	go run ./cmd/createadmin -username admin -email admin@example.com -password 'S3cure!pass'
+//	go run ./cmd/createadmin -username existing_user
+package main
+
+import (
+	"errors"
+	"flag"
+	"log"
+
+	"depression_go/inits"
+	"depression_go/internal/models"
+	"depression_go/pkg/utils"
+	"depression_go/services"
+
+	"github.com/joho/godotenv"
+	"gorm.io/gorm"
+)
+
+func main() {
+	username := flag.String("username", "", "用户名（必填）")
+	email := flag.String("email", "", "邮箱，创建新用户时必填")
+	password := flag.String("password", "", "密码，创建新用户时必填")
+	flag.Parse()
+
+	if *username == "" {
+		flag.Usage()
+		log.Fatal("缺少 -username")
+	}
+
+	// 加载环境变量 - 与服务端一致
+	if err := godotenv.Load("config.env"); err != nil {
+		if err := godotenv.Load(); err != nil {
+			log.Println("未找到config.env或.env文件，使用系统环境变量")
+		}
+	}
+	inits.InitDatabase()
+	inits.InitConfig()
+	defer inits.CloseDatabase()
+
+	if err := services.NewRBACService(inits.DB).EnsureDefaults(); err != nil {
+		log.Fatalf("初始化角色和权限失败: %v", err)
+	}
+
+	var user models.User
+	err := inits.DB.Where("username = ?", *username).First(&user).Error
+	switch {
+	case err == nil:
+		// 已有用户：设为管理员并启用，令牌版本递增使新角色立即生效
+		if err := inits.DB.Model(&user).Updates(map[string]interface{}{
+			"role":          models.RoleAdmin,
+			"status":        1,
+			"token_version": gorm.Expr("token_version + 1"),
+		}).Error; err != nil {
+			log.Fatalf("设置管理员失败: %v", err)
+		}
+		log.Printf("已将用户 %s（ID %d）设为管理员", user.Username, user.ID)
+
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if *email == "" || *password == "" {
+			log.Fatal("用户不存在，创建新用户需要 -email 和 -password")
+		}
+		if valid, msg := utils.ValidatePassword(*password, *username, *email); !valid {
+			log.Fatal(msg)
+		}
+		hashedPassword, err := utils.HashPassword(*password)
+		if err != nil {
+			log.Fatalf("密码加密失败: %v", err)
+		}
+		user = models.User{
+			Username: *username,
+			Email:    *email,
+			Password: hashedPassword,
+			Status:   1,
+			Role:     models.RoleAdmin,
+		}
+		if err := inits.DB.Create(&user).Error; err != nil {
+			log.Fatalf("创建管理员失败: %v", err)
+		}
+		log.Printf("已创建管理员 %s（ID %d）", user.Username, user.ID)
+
+	default:
+		log.Fatalf("查询用户失败: %v", err)
+	}
+}
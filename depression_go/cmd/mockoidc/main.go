@@ -0,0 +1,258 @@
+// mockoidc 本地模拟的OIDC提供方，用于在没有校园统一认证的环境下调试单点登录。
+// 授权端点不做身份认证，直接以配置的（或login_hint指定的）用户身份签发授权码；
+// 令牌端点校验client_id/client_secret、redirect_uri和PKCE。签名密钥每次启动时重新生成。
+//
+// 用法:
+//
+//	go run ./cmd/mockoidc -addr :9000 -client-id depression -client-secret secret
+//	go run ./cmd/mockoidc -email student@example.edu -name 张三 -email-verified=false
+//
+// 对应的服务端配置:
+//
+//	OIDC_PROVIDERS=mock
+//	OIDC_MOCK_ISSUER=http://localhost:9000
+//	OIDC_MOCK_CLIENT_ID=depression
+//	OIDC_MOCK_CLIENT_SECRET=secret
+//	OIDC_MOCK_REDIRECT_URL=http://localhost:3000/oidc/callback/mock
+package main
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"flag"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"depression_go/pkg/oidc"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// grant 已签发、尚未兑换的授权码
+type grant struct {
+	clientID      string
+	redirectURI   string
+	nonce         string
+	codeChallenge string
+	email         string
+	authTime      time.Time
+	expiresAt     time.Time
+}
+
+type provider struct {
+	issuer        string
+	clientID      string
+	clientSecret  string
+	email         string
+	name          string
+	emailVerified bool
+	key           *rsa.PrivateKey
+	kid           string
+
+	mu     sync.Mutex
+	grants map[string]grant
+	tokens map[string]string // 访问令牌 -> 邮箱
+}
+
+func main() {
+	addr := flag.String("addr", ":9000", "监听地址")
+	issuer := flag.String("issuer", "http://localhost:9000", "发行方地址，需与服务端OIDC_<名称>_ISSUER一致")
+	clientID := flag.String("client-id", "depression", "客户端ID")
+	clientSecret := flag.String("client-secret", "secret", "客户端密钥，为空时作为公共客户端")
+	email := flag.String("email", "student@example.edu", "登录用户的邮箱，可用授权请求的login_hint覆盖")
+	name := flag.String("name", "测试学生", "登录用户的姓名")
+	emailVerified := flag.Bool("email-verified", true, "邮箱是否已验证")
+	flag.Parse()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		log.Fatalf("生成签名密钥失败: %v", err)
+	}
+	kid, _ := oidc.NewState()
+
+	p := &provider{
+		issuer:        strings.TrimSuffix(*issuer, "/"),
+		clientID:      *clientID,
+		clientSecret:  *clientSecret,
+		email:         *email,
+		name:          *name,
+		emailVerified: *emailVerified,
+		key:           key,
+		kid:           kid[:8],
+		grants:        make(map[string]grant),
+		tokens:        make(map[string]string),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", p.discovery)
+	mux.HandleFunc("/jwks", p.jwks)
+	mux.HandleFunc("/authorize", p.authorize)
+	mux.HandleFunc("/token", p.token)
+	mux.HandleFunc("/userinfo", p.userinfo)
+
+	log.Printf("模拟OIDC提供方启动在 %s，issuer=%s", *addr, p.issuer)
+	log.Fatal(http.ListenAndServe(*addr, mux))
+}
+
+func (p *provider) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                p.issuer,
+		"authorization_endpoint":                p.issuer + "/authorize",
+		"token_endpoint":                        p.issuer + "/token",
+		"userinfo_endpoint":                     p.issuer + "/userinfo",
+		"jwks_uri":                              p.issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+		"scopes_supported":                      []string{"openid", "email", "profile"},
+	})
+}
+
+func (p *provider) jwks(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{oidc.RSAJWK(p.kid, &p.key.PublicKey)},
+	})
+}
+
+// authorize 不做认证，直接签发授权码并重定向回redirect_uri
+func (p *provider) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("response_type") != "code" || q.Get("client_id") != p.clientID {
+		http.Error(w, "unsupported response_type or unknown client_id", http.StatusBadRequest)
+		return
+	}
+	if q.Get("code_challenge") == "" || q.Get("code_challenge_method") != "S256" {
+		http.Error(w, "PKCE (S256) is required", http.StatusBadRequest)
+		return
+	}
+	redirect, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil || redirect.Scheme == "" {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+
+	email := p.email
+	if hint := q.Get("login_hint"); hint != "" {
+		email = hint
+	}
+	code, _ := oidc.NewState()
+	p.mu.Lock()
+	p.grants[code] = grant{
+		clientID:      p.clientID,
+		redirectURI:   q.Get("redirect_uri"),
+		nonce:         q.Get("nonce"),
+		codeChallenge: q.Get("code_challenge"),
+		email:         email,
+		authTime:      time.Now(),
+		expiresAt:     time.Now().Add(time.Minute),
+	}
+	p.mu.Unlock()
+
+	params := redirect.Query()
+	params.Set("code", code)
+	params.Set("state", q.Get("state"))
+	redirect.RawQuery = params.Encode()
+	log.Printf("以 %s 的身份签发授权码，重定向到 %s", email, redirect.Host+redirect.Path)
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+// token 兑换授权码：授权码只能使用一次
+func (p *provider) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.ParseForm() != nil {
+		tokenError(w, "invalid_request")
+		return
+	}
+	clientID, clientSecret, ok := r.BasicAuth()
+	if ok {
+		clientID, _ = url.QueryUnescape(clientID)
+		clientSecret, _ = url.QueryUnescape(clientSecret)
+	} else {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if clientID != p.clientID || (p.clientSecret != "" && clientSecret != p.clientSecret) {
+		tokenError(w, "invalid_client")
+		return
+	}
+
+	code := r.PostForm.Get("code")
+	p.mu.Lock()
+	g, found := p.grants[code]
+	delete(p.grants, code)
+	p.mu.Unlock()
+	if r.PostForm.Get("grant_type") != "authorization_code" || !found || time.Now().After(g.expiresAt) ||
+		g.redirectURI != r.PostForm.Get("redirect_uri") ||
+		oidc.CodeChallenge(r.PostForm.Get("code_verifier")) != g.codeChallenge {
+		tokenError(w, "invalid_grant")
+		return
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":            p.issuer,
+		"sub":            p.subjectFor(g.email),
+		"aud":            p.clientID,
+		"exp":            now.Add(5 * time.Minute).Unix(),
+		"iat":            now.Unix(),
+		"auth_time":      g.authTime.Unix(),
+		"nonce":          g.nonce,
+		"email":          g.email,
+		"email_verified": p.emailVerified,
+		"name":           p.name,
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = p.kid
+	idToken, err := token.SignedString(p.key)
+	if err != nil {
+		tokenError(w, "server_error")
+		return
+	}
+
+	accessToken, _ := oidc.NewState()
+	p.mu.Lock()
+	p.tokens[accessToken] = g.email
+	p.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     idToken,
+	})
+}
+
+func (p *provider) userinfo(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	email, ok := p.tokens[strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")]
+	p.mu.Unlock()
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"sub":            p.subjectFor(email),
+		"email":          email,
+		"email_verified": p.emailVerified,
+		"name":           p.name,
+	})
+}
+
+// subjectFor 同一邮箱始终对应同一个sub，模拟提供方的稳定用户标识
+func (p *provider) subjectFor(email string) string {
+	return "mock-" + strings.ToLower(email)
+}
+
+func tokenError(w http.ResponseWriter, code string) {
+	writeJSON(w, http.StatusBadRequest, map[string]string{"error": code})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
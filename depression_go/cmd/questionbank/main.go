@@ -0,0 +1,91 @@
+// questionbank 题库导入导出命令行工具
+//
+// 用法:
+//
+//	go run ./cmd/questionbank export -format csv -o questions.csv
+//	go run ./cmd/questionbank import -format fhir -f questionnaire.json
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"depression_go/inits"
+	"depression_go/services"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	command := os.Args[1]
+
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	format := flags.String("format", services.FormatJSON, "格式: json、csv、fhir")
+	input := flags.String("f", "", "导入文件路径（默认读取标准输入）")
+	output := flags.String("o", "", "导出文件路径（默认输出到标准输出）")
+	flags.Parse(os.Args[2:])
+
+	// 加载环境变量 - 与服务端一致
+	if err := godotenv.Load("config.env"); err != nil {
+		if err := godotenv.Load(); err != nil {
+			log.Println("未找到config.env或.env文件，使用系统环境变量")
+		}
+	}
+	inits.InitDatabase()
+	inits.InitConfig()
+
+	questionBankService := services.NewQuestionBankService(inits.DB)
+
+	switch command {
+	case "export":
+		var w io.Writer = os.Stdout
+		if *output != "" {
+			file, err := os.Create(*output)
+			if err != nil {
+				log.Fatalf("创建导出文件失败: %v", err)
+			}
+			defer file.Close()
+			w = file
+		}
+		if err := questionBankService.Export(*format, w); err != nil {
+			log.Fatalf("导出失败: %v", err)
+		}
+
+	case "import":
+		var r io.Reader = os.Stdin
+		if *input != "" {
+			file, err := os.Open(*input)
+			if err != nil {
+				log.Fatalf("打开导入文件失败: %v", err)
+			}
+			defer file.Close()
+			r = file
+		}
+		report, err := questionBankService.Import(services.AuditContext{IP: "cli"}, *format, r)
+		if err != nil {
+			log.Fatalf("导入失败: %v", err)
+		}
+		data, _ := json.MarshalIndent(report, "", "  ")
+		fmt.Println(string(data))
+		if len(report.Errors) > 0 {
+			log.Println("导入数据校验失败，未写入任何数据")
+			os.Exit(1)
+		}
+
+	default:
+		usage()
+		os.Exit(2)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "用法: questionbank <export|import> [-format json|csv|fhir] [-f 导入文件] [-o 导出文件]")
+}
@@ -2,11 +2,24 @@ package configs
 
 // Config 全局配置结构体
 type Config struct {
-	Database DatabaseConfig
-	JWT      JWTConfig
-	BaiduAI  BaiduAIConfig
-	Server   ServerConfig
-	Upload   UploadConfig
+	Database   DatabaseConfig
+	JWT        JWTConfig
+	BaiduAI    BaiduAIConfig
+	Server     ServerConfig
+	Upload     UploadConfig
+	Assessment AssessmentConfig
+	Crisis     CrisisConfig
+	Quality    QualityConfig
+	Journal    JournalConfig
+	Checkin    CheckinConfig
+	Trend      TrendConfig
+	Fusion     FusionConfig
+	Lockout    LockoutConfig
+	Mail       MailConfig
+	MFA        MFAConfig
+	OIDC       OIDCConfig
+	Password   PasswordConfig
+	Deletion   DeletionConfig
 }
 
 // DatabaseConfig 数据库配置
@@ -20,8 +33,9 @@ type DatabaseConfig struct {
 
 // JWTConfig JWT配置
 type JWTConfig struct {
-	Secret      string
-	ExpireHours int
+	Secret           string
+	AccessTTLMinutes int // 访问令牌有效期（分钟）
+	RefreshTTLHours  int // 刷新令牌有效期（小时）
 }
 
 // BaiduAIConfig 百度AI配置
@@ -33,8 +47,9 @@ type BaiduAIConfig struct {
 
 // ServerConfig 服务器配置
 type ServerConfig struct {
-	Port string
-	Mode string
+	Port           string
+	Mode           string
+	TrustedProxies []string // 信任其X-Forwarded-For的反向代理地址或网段，为空时不信任任何代理
 }
 
 // UploadConfig 文件上传配置
@@ -43,5 +58,146 @@ type UploadConfig struct {
 	MaxFileSize int64
 }
 
+// AssessmentConfig 评估会话配置
+type AssessmentConfig struct {
+	DraftTTLHours       int // 草稿无操作多久后过期（小时）
+	ExpiryCheckInterval int // 过期草稿清理间隔（分钟）
+
+	CATTargetSE float64 // 自适应测验的目标标准误，达到后停止出题
+	CATMinItems int     // 自适应测验最少题数
+	CATMaxItems int     // 自适应测验最多题数
+}
+
+// QualityConfig 作答质量检查配置
+type QualityConfig struct {
+	MinMsPerItem         int // 每题作答用时中位数低于该值（毫秒）视为作答过快
+	StraightLineMinItems int // 至少作答多少道选择题才检查一律选同一选项
+	ConsistencyTolerance int // 配对题目选项值相差超过该值视为前后矛盾
+}
+
+// JournalConfig 心情日记配置
+type JournalConfig struct {
+	Analyzer   string // 情感分析器，默认lexicon（内置离线词典）
+	MaxLength  int    // 单篇日记的最大字数
+	WindowDays int    // 综合评估使用最近多少天的日记
+}
+
+// CheckinConfig 每日打卡配置
+type CheckinConfig struct {
+	MaxPerDay int // 每天最多打卡次数
+}
+
+// TrendConfig 纵向趋势配置
+type TrendConfig struct {
+	DeteriorationWindow int // 检查最近多少次评估的持续恶化
+}
+
+// FusionConfig 综合评估（多模态融合）配置
+type FusionConfig struct {
+	Modalities  []string           // 参与融合的模态：questionnaire, face, journal，为空时全部参与
+	Weights     map[string]float64 // 各模态权重，为空时使用默认权重
+	MaxGapHours int                // 各输入之间允许的最大时间间隔（小时），超出的较旧输入不参与融合，0表示不限制
+}
+
+// LockoutConfig 登录失败锁定配置
+type LockoutConfig struct {
+	Store         string // 失败计数存储：memory(默认，单实例), db, redis
+	RedisAddr     string // Redis地址，如127.0.0.1:6379
+	RedisPassword string
+	RedisDB       int
+
+	MaxAccountFailures int // 同一账户连续失败多少次后锁定
+	MaxIPFailures      int // 同一IP连续失败多少次后拒绝该IP的登录
+	BaseLockSeconds    int // 首次锁定时长（秒），之后每多失败一次翻倍
+	MaxLockMinutes     int // 锁定时长上限（分钟）
+	WindowMinutes      int // 最后一次失败多久后失败次数清零（分钟）
+}
+
+// MailConfig 邮件配置
+type MailConfig struct {
+	Driver string // 发送方式：log(默认，写入日志), file(保存为.eml文件), smtp
+	From   string // 发件人，如 抑郁倾向检测系统 <noreply@example.com>
+	Dir    string // file方式的保存目录
+
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	SMTPSecurity string // 空(支持时使用STARTTLS), starttls, tls, none
+
+	AppName         string // 邮件中显示的系统名称
+	LinkBaseURL     string // 邮件中链接的前端地址，如 https://example.com
+	VerifyTTLHours  int    // 邮箱验证链接有效期（小时）
+	ResetTTLMinutes int    // 重置密码链接有效期（分钟）
+}
+
+// MFAConfig 两步验证配置
+type MFAConfig struct {
+	Issuer            string // 身份验证器中显示的发行方
+	EncryptionKey     string // 加密TOTP密钥的口令，未配置时两步验证不可用
+	PendingTTLMinutes int    // 两步验证令牌有效期（分钟）
+}
+
+// PasswordConfig 密码策略和哈希参数
+type PasswordConfig struct {
+	MinLength      int    // 最短长度（字符）
+	MaxLength      int    // 最长长度（字符）
+	MinClasses     int    // 至少包含的字符类别数（小写字母、大写字母、数字、符号），1表示不限
+	RejectUserInfo bool   // 拒绝包含用户名或邮箱的密码
+	Blocklist      bool   // 拒绝常见弱密码
+	BlocklistFile  string // 额外的弱密码列表文件，每行一个，与内置列表合并
+	// argon2id参数，修改后旧参数的哈希在下次登录时自动升级
+	Argon2MemoryKB      int
+	Argon2Iterations    int
+	Argon2Parallelism   int
+	Argon2MaxConcurrent int // 同时计算argon2id哈希的最大数量，超出的请求排队
+}
+
+// DeletionConfig 账户注销配置
+type DeletionConfig struct {
+	GraceDays int // 申请后等待的天数，期间可以撤销，到期后删除全部数据
+}
+
+// OIDCConfig 单点登录配置，可同时配置多个OIDC提供方
+type OIDCConfig struct {
+	Providers []OIDCProviderConfig
+}
+
+// OIDCProviderConfig 一个OIDC提供方（发行方）
+type OIDCProviderConfig struct {
+	Name           string // 提供方名称，用于接口路径，如campus
+	DisplayName    string // 登录页显示的名称
+	Issuer         string
+	ClientID       string
+	ClientSecret   string
+	RedirectURL    string   // 提供方回调的前端地址
+	Scopes         []string // 为空时使用 openid email profile
+	AutoCreate     bool     // 首次登录且没有匹配的账户时自动创建
+	LinkByEmail    bool     // 按已验证的邮箱关联已有账户
+	AllowedDomains []string // 允许的邮箱域名，为空时不限制
+}
+
+// CrisisConfig 危机干预配置
+type CrisisConfig struct {
+	Notifier   string            // 通知方式：log(仅记录日志), webhook
+	WebhookURL string            // webhook通知地址
+	Responders []CrisisResponder // 需要通知的响应人员
+	Resources  []CrisisResource  // 返回给用户的求助资源，为空时使用内置热线
+}
+
+// CrisisResponder 危机响应人员
+type CrisisResponder struct {
+	Role    string // clinician(临床医生), counselor(值班咨询师)
+	Name    string
+	Contact string
+}
+
+// CrisisResource 求助热线等资源
+type CrisisResource struct {
+	Name        string
+	Phone       string
+	Description string
+}
+
 // GlobalConfig 全局配置实例
 var GlobalConfig *Config
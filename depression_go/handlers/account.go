@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"errors"
+
+	"depression_go/inits"
+	"depression_go/internal/models"
+	"depression_go/middleware"
+	"depression_go/pkg/response"
+	"depression_go/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AccountHandler 邮箱验证和找回密码处理器
+type AccountHandler struct {
+	userService    *services.UserService
+	accountService *services.AccountService
+}
+
+// NewAccountHandler 创建邮箱验证和找回密码处理器
+func NewAccountHandler() *AccountHandler {
+	return &AccountHandler{
+		userService:    services.NewUserService(inits.DB),
+		accountService: services.NewAccountService(inits.DB),
+	}
+}
+
+// VerifyEmail 用邮件中的令牌验证邮箱
+func (h *AccountHandler) VerifyEmail(c *gin.Context) {
+	var req models.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	user, err := h.accountService.VerifyEmail(req.Token)
+	if err != nil {
+		handleAccountError(c, err, "验证失败")
+		return
+	}
+
+	response.SuccessWithMessage(c, "邮箱已验证", toUserResponse(*user))
+}
+
+// ResendVerification 重新发送邮箱验证邮件
+func (h *AccountHandler) ResendVerification(c *gin.Context) {
+	user, err := h.userService.Get(middleware.GetUserID(c))
+	if err != nil {
+		handleUserError(c, err, "发送失败")
+		return
+	}
+
+	if err := h.accountService.SendVerification(user, middleware.GetLocale(c)); err != nil {
+		handleAccountError(c, err, "发送失败")
+		return
+	}
+
+	response.SuccessWithMessage(c, "验证邮件已发送", nil)
+}
+
+// ForgotPassword 申请重置密码，无论邮箱是否注册都返回成功
+func (h *AccountHandler) ForgotPassword(c *gin.Context) {
+	var req models.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	if err := h.accountService.RequestPasswordReset(req.Email, middleware.GetLocale(c)); err != nil {
+		response.InternalServerError(c, "申请失败")
+		return
+	}
+
+	response.SuccessWithMessage(c, "如果该邮箱已注册，重置密码的邮件已发送", nil)
+}
+
+// ResetPassword 用邮件中的令牌设置新密码，之后需要重新登录
+func (h *AccountHandler) ResetPassword(c *gin.Context) {
+	var req models.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	if err := h.accountService.ResetPassword(req.Token, req.NewPassword); err != nil {
+		handleAccountError(c, err, "重置密码失败")
+		return
+	}
+
+	response.SuccessWithMessage(c, "密码已重置，请使用新密码登录", nil)
+}
+
+// handleAccountError 将邮箱验证和找回密码的错误转换为响应
+func handleAccountError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrInvalidUserToken),
+		errors.Is(err, services.ErrEmailAlreadyVerified):
+		response.BadRequest(c, err.Error())
+	case errors.Is(err, services.ErrMailTooFrequent):
+		response.Error(c, 429, err.Error())
+	case errors.Is(err, services.ErrUserDisabled):
+		response.Forbidden(c, err.Error())
+	default:
+		handleUserError(c, err, fallback)
+	}
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"errors"
+
+	"depression_go/inits"
+	"depression_go/internal/models"
+	"depression_go/middleware"
+	"depression_go/pkg/response"
+	"depression_go/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AccountDeletionHandler 账户注销处理器
+type AccountDeletionHandler struct {
+	userService     *services.UserService
+	deletionService *services.AccountDeletionService
+}
+
+// NewAccountDeletionHandler 创建账户注销处理器
+func NewAccountDeletionHandler() *AccountDeletionHandler {
+	return &AccountDeletionHandler{
+		userService:     services.NewUserService(inits.DB),
+		deletionService: services.NewAccountDeletionService(inits.DB),
+	}
+}
+
+// GetDeletion 当前等待中的注销申请，没有时data为null
+func (h *AccountDeletionHandler) GetDeletion(c *gin.Context) {
+	receipt, err := h.deletionService.Status(middleware.GetUserID(c))
+	if err != nil {
+		response.InternalServerError(c, "查询失败")
+		return
+	}
+
+	response.Success(c, receipt)
+}
+
+// RequestDeletion 申请注销账户，等待期结束后删除账户和全部数据
+func (h *AccountDeletionHandler) RequestDeletion(c *gin.Context) {
+	var req models.AccountDeletionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	user, err := h.userService.Get(middleware.GetUserID(c))
+	if err != nil {
+		handleUserError(c, err, "申请失败")
+		return
+	}
+
+	receipt, err := h.deletionService.Request(auditContext(c), user, req, middleware.GetLocale(c))
+	if err != nil {
+		handleDeletionError(c, err, "申请失败")
+		return
+	}
+
+	response.SuccessWithMessage(c, "注销申请已受理，到期前可以撤销", receipt)
+}
+
+// CancelDeletion 撤销等待中的注销申请
+func (h *AccountDeletionHandler) CancelDeletion(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if err := h.deletionService.Cancel(auditContext(c), userID); err != nil {
+		handleDeletionError(c, err, "撤销失败")
+		return
+	}
+
+	response.SuccessWithMessage(c, "注销申请已撤销", nil)
+}
+
+// GetReceipt 按回执编号查询删除回执，不需要登录
+func (h *AccountDeletionHandler) GetReceipt(c *gin.Context) {
+	receipt, err := h.deletionService.Receipt(c.Param("id"))
+	if err != nil {
+		handleDeletionError(c, err, "查询失败")
+		return
+	}
+
+	response.Success(c, receipt)
+}
+
+// handleDeletionError 将账户注销的错误转换为响应
+func handleDeletionError(c *gin.Context, err error, fallback string) {
+	var lockErr *services.LockoutError
+	switch {
+	case errors.As(err, &lockErr):
+		handleLockoutError(c, err)
+	case errors.Is(err, services.ErrDeletionPending),
+		errors.Is(err, services.ErrDeletionNotPending),
+		errors.Is(err, services.ErrSelfManagement),
+		errors.Is(err, services.ErrDeletionReauth),
+		errors.Is(err, services.ErrDeletionSSOReauth),
+		errors.Is(err, services.ErrInvalidMFACode),
+		errors.Is(err, services.ErrMFANotEnabled):
+		response.BadRequest(c, err.Error())
+	case errors.Is(err, services.ErrDeletionReceiptNotFound):
+		response.NotFound(c, err.Error())
+	default:
+		handleUserError(c, err, fallback)
+	}
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"depression_go/inits"
+	"depression_go/middleware"
+	"depression_go/pkg/response"
+	"depression_go/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminAssessmentHandler 评估管理处理器（仅管理员）
+type AdminAssessmentHandler struct {
+	assessmentService   *services.AssessmentService
+	localizationService *services.LocalizationService
+}
+
+// NewAdminAssessmentHandler 创建评估管理处理器
+func NewAdminAssessmentHandler() *AdminAssessmentHandler {
+	return &AdminAssessmentHandler{
+		assessmentService:   services.NewAssessmentService(inits.DB),
+		localizationService: services.NewLocalizationService(inits.DB),
+	}
+}
+
+// RescoreAssessment 按作答时的问卷版本重新计分
+func (h *AdminAssessmentHandler) RescoreAssessment(c *gin.Context) {
+	assessmentID, ok := parseIDParam(c, "id", "无效的评估ID")
+	if !ok {
+		return
+	}
+
+	outcome, err := h.assessmentService.Rescore(auditContext(c), assessmentID)
+	if err != nil {
+		handleAssessmentError(c, err, "重新计分失败")
+		return
+	}
+
+	h.localizationService.LocalizeResult(middleware.GetLocale(c), &outcome.Result)
+	response.SuccessWithMessage(c, "重新计分完成", finalizeResponse(outcome))
+}
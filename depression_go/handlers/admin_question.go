@@ -0,0 +1,391 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"depression_go/inits"
+	"depression_go/internal/models"
+	"depression_go/middleware"
+	"depression_go/pkg/response"
+	"depression_go/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// maxImportSize 导入文件大小上限
+const maxImportSize = 10 << 20
+
+// AdminQuestionHandler 题库管理处理器（仅管理员）
+type AdminQuestionHandler struct {
+	db                  *gorm.DB
+	questionBankService *services.QuestionBankService
+	versionService      *services.QuestionnaireVersionService
+}
+
+// NewAdminQuestionHandler 创建题库管理处理器
+func NewAdminQuestionHandler() *AdminQuestionHandler {
+	return &AdminQuestionHandler{
+		db:                  inits.DB,
+		questionBankService: services.NewQuestionBankService(inits.DB),
+		versionService:      services.NewQuestionnaireVersionService(inits.DB),
+	}
+}
+
+// ListQuestions 获取题库（包含禁用的问题，可选包含已删除的问题）
+func (h *AdminQuestionHandler) ListQuestions(c *gin.Context) {
+	filter := services.QuestionFilter{
+		Category:       c.Query("category"),
+		IncludeDeleted: c.Query("include_deleted") == "1" || c.Query("include_deleted") == "true",
+	}
+	if status := c.Query("status"); status != "" {
+		if statusInt, err := strconv.Atoi(status); err == nil {
+			filter.Status = &statusInt
+		}
+	}
+
+	questions, err := h.questionBankService.List(filter)
+	if err != nil {
+		response.InternalServerError(c, "查询失败")
+		return
+	}
+
+	responses := make([]models.QuestionResponse, 0, len(questions))
+	for _, question := range questions {
+		responses = append(responses, toQuestionResponse(question))
+	}
+
+	response.Success(c, responses)
+}
+
+// CreateQuestion 创建问题
+func (h *AdminQuestionHandler) CreateQuestion(c *gin.Context) {
+	var req models.QuestionCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	question, err := h.questionBankService.Create(auditContext(c), req)
+	if err != nil {
+		handleQuestionBankError(c, err, "创建问题失败")
+		return
+	}
+
+	response.SuccessWithMessage(c, "问题创建成功", toQuestionResponse(*question))
+}
+
+// UpdateQuestion 更新问题
+func (h *AdminQuestionHandler) UpdateQuestion(c *gin.Context) {
+	questionID, ok := parseIDParam(c, "id", "无效的问题ID")
+	if !ok {
+		return
+	}
+
+	var req models.QuestionUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	question, err := h.questionBankService.Update(auditContext(c), questionID, req)
+	if err != nil {
+		handleQuestionBankError(c, err, "更新问题失败")
+		return
+	}
+
+	response.SuccessWithMessage(c, "问题更新成功", toQuestionResponse(*question))
+}
+
+// SetQuestionStatus 启用或禁用问题
+func (h *AdminQuestionHandler) SetQuestionStatus(c *gin.Context) {
+	questionID, ok := parseIDParam(c, "id", "无效的问题ID")
+	if !ok {
+		return
+	}
+
+	var req models.QuestionStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	question, err := h.questionBankService.SetStatus(auditContext(c), questionID, *req.Status)
+	if err != nil {
+		handleQuestionBankError(c, err, "更新状态失败")
+		return
+	}
+
+	response.SuccessWithMessage(c, "状态更新成功", toQuestionResponse(*question))
+}
+
+// ReorderQuestions 批量调整问题排序
+func (h *AdminQuestionHandler) ReorderQuestions(c *gin.Context) {
+	var req models.QuestionReorderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	if err := h.questionBankService.Reorder(auditContext(c), req.Items); err != nil {
+		handleQuestionBankError(c, err, "调整排序失败")
+		return
+	}
+
+	response.SuccessWithMessage(c, "排序已更新", nil)
+}
+
+// ExportQuestions 导出题库，format可选json、csv、fhir
+func (h *AdminQuestionHandler) ExportQuestions(c *gin.Context) {
+	format := c.DefaultQuery("format", services.FormatJSON)
+
+	var buf bytes.Buffer
+	if err := h.questionBankService.Export(format, &buf); err != nil {
+		handleQuestionBankError(c, err, "导出失败")
+		return
+	}
+
+	contentType, ext := "application/json; charset=utf-8", "json"
+	switch format {
+	case services.FormatCSV:
+		contentType, ext = "text/csv; charset=utf-8", "csv"
+	case services.FormatFHIR:
+		contentType, ext = "application/fhir+json; charset=utf-8", "fhir.json"
+	}
+	filename := fmt.Sprintf("question_bank_%s.%s", time.Now().Format("20060102150405"), ext)
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Data(http.StatusOK, contentType, buf.Bytes())
+}
+
+// ImportQuestions 导入题库，支持multipart文件（字段file）或直接以请求体上传；
+// 任一行校验失败时不写入任何数据，返回逐行错误
+func (h *AdminQuestionHandler) ImportQuestions(c *gin.Context) {
+	format := c.DefaultQuery("format", services.FormatJSON)
+
+	var reader io.Reader = c.Request.Body
+	if file, err := c.FormFile("file"); err == nil {
+		src, err := file.Open()
+		if err != nil {
+			response.BadRequest(c, "读取上传文件失败")
+			return
+		}
+		defer src.Close()
+		reader = src
+	}
+
+	report, err := h.questionBankService.Import(auditContext(c), format, io.LimitReader(reader, maxImportSize))
+	if err != nil {
+		handleQuestionBankError(c, err, "导入失败")
+		return
+	}
+	if len(report.Errors) > 0 {
+		response.ErrorWithData(c, 422, "导入数据校验失败，未写入任何数据", report)
+		return
+	}
+
+	response.SuccessWithMessage(c, "导入成功", report)
+}
+
+// DeleteQuestion 删除问题（软删除，可恢复）
+func (h *AdminQuestionHandler) DeleteQuestion(c *gin.Context) {
+	questionID, ok := parseIDParam(c, "id", "无效的问题ID")
+	if !ok {
+		return
+	}
+
+	if err := h.questionBankService.Delete(auditContext(c), questionID); err != nil {
+		handleQuestionBankError(c, err, "删除问题失败")
+		return
+	}
+
+	response.SuccessWithMessage(c, "问题已删除", nil)
+}
+
+// RestoreQuestion 恢复已删除的问题
+func (h *AdminQuestionHandler) RestoreQuestion(c *gin.Context) {
+	questionID, ok := parseIDParam(c, "id", "无效的问题ID")
+	if !ok {
+		return
+	}
+
+	question, err := h.questionBankService.Restore(auditContext(c), questionID)
+	if err != nil {
+		handleQuestionBankError(c, err, "恢复问题失败")
+		return
+	}
+
+	response.SuccessWithMessage(c, "问题已恢复", toQuestionResponse(*question))
+}
+
+// ListVersions 获取问卷版本列表
+func (h *AdminQuestionHandler) ListVersions(c *gin.Context) {
+	versions, err := h.versionService.List()
+	if err != nil {
+		response.InternalServerError(c, "查询失败")
+		return
+	}
+
+	responses := make([]models.QuestionnaireVersionResponse, 0, len(versions))
+	for _, version := range versions {
+		responses = append(responses, models.QuestionnaireVersionResponse{
+			ID:        version.ID,
+			Version:   version.Version,
+			Note:      version.Note,
+			CreatedBy: version.CreatedBy,
+			CreatedAt: version.CreatedAt,
+		})
+	}
+
+	response.Success(c, responses)
+}
+
+// GetVersion 获取问卷版本详情（按当时的修订还原题目）
+func (h *AdminQuestionHandler) GetVersion(c *gin.Context) {
+	versionID, ok := parseIDParam(c, "id", "无效的版本ID")
+	if !ok {
+		return
+	}
+
+	version, err := h.versionService.Get(versionID)
+	if err != nil {
+		handleAssessmentError(c, err, "查询失败")
+		return
+	}
+
+	questions, err := h.versionService.Questions(h.db, version.ID)
+	if err != nil {
+		response.InternalServerError(c, "查询失败")
+		return
+	}
+
+	questionResponses := make([]models.QuestionResponse, 0, len(questions))
+	for _, item := range questions {
+		question := toQuestionResponse(item.Question)
+		question.OrderNum = item.OrderNum
+		questionResponses = append(questionResponses, question)
+	}
+
+	response.Success(c, models.QuestionnaireVersionResponse{
+		ID:        version.ID,
+		Version:   version.Version,
+		Note:      version.Note,
+		CreatedBy: version.CreatedBy,
+		CreatedAt: version.CreatedAt,
+		Questions: questionResponses,
+	})
+}
+
+// GetAuditLogs 查询审计日志
+func (h *AdminQuestionHandler) GetAuditLogs(c *gin.Context) {
+	// 获取分页参数
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	query := h.db.Model(&models.AuditLog{})
+	if resourceType := c.Query("resource_type"); resourceType != "" {
+		query = query.Where("resource_type = ?", resourceType)
+	}
+	if resourceID := c.Query("resource_id"); resourceID != "" {
+		query = query.Where("resource_id = ?", resourceID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		response.InternalServerError(c, "查询失败")
+		return
+	}
+
+	var logs []models.AuditLog
+	if err := query.Order("id DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&logs).Error; err != nil {
+		response.InternalServerError(c, "查询失败")
+		return
+	}
+
+	response.SuccessWithPage(c, logs, total, page, pageSize)
+}
+
+// auditContext 从请求中提取审计上下文
+func auditContext(c *gin.Context) services.AuditContext {
+	return services.AuditContext{
+		ActorID: middleware.GetUserID(c),
+		IP:      c.ClientIP(),
+	}
+}
+
+// handleQuestionBankError 将题库服务的错误映射为统一响应
+func handleQuestionBankError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrQuestionNotFound):
+		response.NotFound(c, err.Error())
+	case errors.Is(err, services.ErrInvalidQuestion), errors.Is(err, services.ErrQuestionNotDeleted),
+		errors.Is(err, services.ErrUnsupportedFormat), errors.Is(err, services.ErrInvalidImportFile):
+		response.BadRequest(c, err.Error())
+	default:
+		response.InternalServerError(c, fallback)
+	}
+}
+
+// toQuestionResponse 转换为问题响应格式
+func toQuestionResponse(question models.Question) models.QuestionResponse {
+	var deletedAt *time.Time
+	if question.DeletedAt.Valid {
+		deletedAt = &question.DeletedAt.Time
+	}
+	return models.QuestionResponse{
+		ID:           question.ID,
+		Title:        question.Title,
+		Description:  question.Description,
+		Type:         question.Type,
+		Category:     question.Category,
+		Options:      question.Options,
+		Score:        question.Score,
+		OrderNum:     question.OrderNum,
+		Status:       question.Status,
+		RiskItem:     question.RiskItem,
+		RiskBaseline: question.RiskBaseline,
+		CreatedAt:    question.CreatedAt,
+		UpdatedAt:    question.UpdatedAt,
+		DeletedAt:    deletedAt,
+
+		IRTDiscrimination: question.IRTDiscrimination,
+		IRTThresholds:     question.IRTThresholds,
+
+		ConsistencyPairID:   question.ConsistencyPairID,
+		ConsistencyReversed: question.ConsistencyReversed,
+	}
+}
+
+// toPublicQuestionResponse 转换为答题接口的问题响应格式，不含计分一致性配对和IRT参数
+func toPublicQuestionResponse(question models.Question) models.PublicQuestionResponse {
+	return models.PublicQuestionResponse{
+		ID:           question.ID,
+		Title:        question.Title,
+		Description:  question.Description,
+		Type:         question.Type,
+		Category:     question.Category,
+		Options:      question.Options,
+		Score:        question.Score,
+		OrderNum:     question.OrderNum,
+		Status:       question.Status,
+		RiskItem:     question.RiskItem,
+		RiskBaseline: question.RiskBaseline,
+		CreatedAt:    question.CreatedAt,
+		UpdatedAt:    question.UpdatedAt,
+	}
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"depression_go/inits"
+	"depression_go/internal/models"
+	"depression_go/pkg/response"
+	"depression_go/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminRiskEventHandler 风险事件处理器（响应人员查看和处理危机事件）
+type AdminRiskEventHandler struct {
+	crisisService *services.CrisisService
+}
+
+// NewAdminRiskEventHandler 创建风险事件处理器
+func NewAdminRiskEventHandler() *AdminRiskEventHandler {
+	return &AdminRiskEventHandler{
+		crisisService: services.NewCrisisService(inits.DB),
+	}
+}
+
+// ListRiskEvents 分页查询风险事件，默认只看未解决的，可按状态、用户、来源和是否已送达通知筛选
+func (h *AdminRiskEventHandler) ListRiskEvents(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	filter := services.RiskEventFilter{Status: c.DefaultQuery("status", "unresolved"), Source: c.Query("source")}
+	if filter.Status == "all" {
+		filter.Status = ""
+	}
+	if userID, err := strconv.ParseUint(c.Query("user_id"), 10, 64); err == nil {
+		filter.UserID = uint(userID)
+	}
+	if undelivered, err := strconv.ParseBool(c.Query("undelivered")); err == nil {
+		filter.Undelivered = undelivered
+	}
+
+	events, total, err := h.crisisService.List(filter, page, pageSize)
+	if err != nil {
+		response.InternalServerError(c, "查询失败")
+		return
+	}
+
+	response.SuccessWithPage(c, events, total, page, pageSize)
+}
+
+// SetRiskEventStatus 接手或解决风险事件
+func (h *AdminRiskEventHandler) SetRiskEventStatus(c *gin.Context) {
+	eventID, ok := parseIDParam(c, "id", "无效的风险事件ID")
+	if !ok {
+		return
+	}
+
+	var req models.RiskEventStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	event, err := h.crisisService.SetStatus(auditContext(c), eventID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrRiskEventNotFound):
+			response.NotFound(c, err.Error())
+		case errors.Is(err, services.ErrRiskEventTransition):
+			response.Error(c, 409, err.Error())
+		default:
+			response.InternalServerError(c, "修改风险事件状态失败")
+		}
+		return
+	}
+
+	response.SuccessWithMessage(c, "风险事件状态已更新", event)
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"errors"
+
+	"depression_go/inits"
+	"depression_go/internal/models"
+	"depression_go/pkg/response"
+	"depression_go/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminScoringHandler 计分方案管理处理器（仅管理员）
+type AdminScoringHandler struct {
+	scoringService *services.ScoringService
+}
+
+// NewAdminScoringHandler 创建计分方案管理处理器
+func NewAdminScoringHandler() *AdminScoringHandler {
+	return &AdminScoringHandler{
+		scoringService: services.NewScoringService(inits.DB),
+	}
+}
+
+// ListProfiles 列出所有计分方案版本
+func (h *AdminScoringHandler) ListProfiles(c *gin.Context) {
+	profiles, err := h.scoringService.List()
+	if err != nil {
+		response.InternalServerError(c, "查询失败")
+		return
+	}
+
+	responses := make([]models.ScoringProfileResponse, 0, len(profiles))
+	for _, profile := range profiles {
+		responses = append(responses, toScoringProfileResponse(profile))
+	}
+	response.Success(c, responses)
+}
+
+// GetCurrentProfile 获取当前生效的计分方案
+func (h *AdminScoringHandler) GetCurrentProfile(c *gin.Context) {
+	profile, err := h.scoringService.Current(inits.DB)
+	if err != nil {
+		response.InternalServerError(c, "查询失败")
+		return
+	}
+	response.Success(c, toScoringProfileResponse(*profile))
+}
+
+// GetProfile 获取指定版本的计分方案
+func (h *AdminScoringHandler) GetProfile(c *gin.Context) {
+	profileID, ok := parseIDParam(c, "id", "无效的计分方案ID")
+	if !ok {
+		return
+	}
+
+	profile, err := h.scoringService.Get(inits.DB, profileID)
+	if err != nil {
+		handleScoringError(c, err, "查询失败")
+		return
+	}
+	response.Success(c, toScoringProfileResponse(*profile))
+}
+
+// PublishProfile 发布新的计分方案
+func (h *AdminScoringHandler) PublishProfile(c *gin.Context) {
+	var req models.ScoringProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	profile, err := h.scoringService.Publish(auditContext(c), req)
+	if err != nil {
+		handleScoringError(c, err, "发布计分方案失败")
+		return
+	}
+	response.SuccessWithMessage(c, "计分方案已发布", toScoringProfileResponse(*profile))
+}
+
+// handleScoringError 将计分方案服务的错误映射为统一响应
+func handleScoringError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrScoringProfileNotFound):
+		response.NotFound(c, err.Error())
+	case errors.Is(err, services.ErrInvalidScoringProfile):
+		response.BadRequest(c, err.Error())
+	default:
+		response.InternalServerError(c, fallback)
+	}
+}
+
+// toScoringProfileResponse 转换为计分方案响应格式
+func toScoringProfileResponse(profile models.ScoringProfile) models.ScoringProfileResponse {
+	return models.ScoringProfileResponse{
+		ID:        profile.ID,
+		Version:   profile.Version,
+		Bands:     services.ParseScoreBands(&profile),
+		Note:      profile.Note,
+		CreatedBy: profile.CreatedBy,
+		CreatedAt: profile.CreatedAt,
+
+		NormSD:         profile.NormSD,
+		Reliability:    profile.Reliability,
+		ClinicalCutoff: profile.ClinicalCutoff,
+	}
+}
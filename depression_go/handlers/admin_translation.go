@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"errors"
+
+	"depression_go/inits"
+	"depression_go/internal/models"
+	"depression_go/pkg/i18n"
+	"depression_go/pkg/response"
+	"depression_go/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminTranslationHandler 翻译管理处理器（仅管理员）
+type AdminTranslationHandler struct {
+	localizationService *services.LocalizationService
+}
+
+// NewAdminTranslationHandler 创建翻译管理处理器
+func NewAdminTranslationHandler() *AdminTranslationHandler {
+	return &AdminTranslationHandler{
+		localizationService: services.NewLocalizationService(inits.DB),
+	}
+}
+
+// ListQuestionTranslations 获取问题每种语言的最新翻译
+func (h *AdminTranslationHandler) ListQuestionTranslations(c *gin.Context) {
+	questionID, ok := parseIDParam(c, "id", "无效的问题ID")
+	if !ok {
+		return
+	}
+
+	translations, err := h.localizationService.ListQuestionTranslations(questionID)
+	if err != nil {
+		handleTranslationError(c, err, "查询失败")
+		return
+	}
+
+	response.Success(c, translations)
+}
+
+// SaveQuestionTranslation 新增或更新问题在某种语言下的翻译
+func (h *AdminTranslationHandler) SaveQuestionTranslation(c *gin.Context) {
+	questionID, ok := parseIDParam(c, "id", "无效的问题ID")
+	if !ok {
+		return
+	}
+
+	var req models.QuestionTranslationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	translation, err := h.localizationService.SaveQuestionTranslation(auditContext(c), questionID, c.Param("locale"), req)
+	if err != nil {
+		handleTranslationError(c, err, "保存翻译失败")
+		return
+	}
+
+	response.SuccessWithMessage(c, "翻译已保存", translation)
+}
+
+// DeleteQuestionTranslation 删除问题翻译
+func (h *AdminTranslationHandler) DeleteQuestionTranslation(c *gin.Context) {
+	questionID, ok := parseIDParam(c, "id", "无效的问题ID")
+	if !ok {
+		return
+	}
+
+	if err := h.localizationService.DeleteQuestionTranslation(auditContext(c), questionID, c.Param("locale")); err != nil {
+		handleTranslationError(c, err, "删除翻译失败")
+		return
+	}
+
+	response.SuccessWithMessage(c, "翻译已删除", nil)
+}
+
+// ListResultTexts 列出结果文本，locale默认为默认语言
+func (h *AdminTranslationHandler) ListResultTexts(c *gin.Context) {
+	items, err := h.localizationService.ListResultTexts(c.DefaultQuery("locale", i18n.Default))
+	if err != nil {
+		handleTranslationError(c, err, "查询失败")
+		return
+	}
+
+	response.Success(c, items)
+}
+
+// SaveResultText 覆盖某种语言下的结果文本
+func (h *AdminTranslationHandler) SaveResultText(c *gin.Context) {
+	var req models.ResultTextRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	text, err := h.localizationService.SaveResultText(auditContext(c), c.Param("locale"), c.Param("key"), req.Text)
+	if err != nil {
+		handleTranslationError(c, err, "保存失败")
+		return
+	}
+
+	response.SuccessWithMessage(c, "文本已保存", text)
+}
+
+// DeleteResultText 删除覆盖，恢复为内置文本
+func (h *AdminTranslationHandler) DeleteResultText(c *gin.Context) {
+	if err := h.localizationService.DeleteResultText(auditContext(c), c.Param("locale"), c.Param("key")); err != nil {
+		handleTranslationError(c, err, "删除失败")
+		return
+	}
+
+	response.SuccessWithMessage(c, "已恢复为内置文本", nil)
+}
+
+// handleTranslationError 将本地化服务的错误映射为统一响应
+func handleTranslationError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrQuestionNotFound), errors.Is(err, services.ErrTranslationNotFound):
+		response.NotFound(c, err.Error())
+	case errors.Is(err, services.ErrUnsupportedLocale), errors.Is(err, services.ErrUnknownTextKey),
+		errors.Is(err, services.ErrInvalidTranslation):
+		response.BadRequest(c, err.Error())
+	default:
+		response.InternalServerError(c, fallback)
+	}
+}
@@ -0,0 +1,298 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"depression_go/inits"
+	"depression_go/internal/models"
+	"depression_go/pkg/response"
+	"depression_go/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminUserHandler 用户与角色管理处理器
+type AdminUserHandler struct {
+	userService     *services.UserService
+	rbacService     *services.RBACService
+	lockoutService  *services.LockoutService
+	mfaService      *services.MFAService
+	deletionService *services.AccountDeletionService
+}
+
+// NewAdminUserHandler 创建用户与角色管理处理器
+func NewAdminUserHandler() *AdminUserHandler {
+	return &AdminUserHandler{
+		userService:     services.NewUserService(inits.DB),
+		rbacService:     services.NewRBACService(inits.DB),
+		lockoutService:  services.NewLockoutService(inits.DB),
+		mfaService:      services.NewMFAService(inits.DB),
+		deletionService: services.NewAccountDeletionService(inits.DB),
+	}
+}
+
+// ListUsers 分页列出用户，可按角色、状态和用户名/邮箱筛选
+func (h *AdminUserHandler) ListUsers(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	filter := services.UserFilter{Role: c.Query("role"), Keyword: c.Query("keyword")}
+	if status := c.Query("status"); status != "" {
+		if statusInt, err := strconv.Atoi(status); err == nil {
+			filter.Status = &statusInt
+		}
+	}
+
+	users, total, err := h.userService.List(filter, page, pageSize)
+	if err != nil {
+		response.InternalServerError(c, "查询失败")
+		return
+	}
+
+	responses := make([]models.UserResponse, 0, len(users))
+	for _, user := range users {
+		responses = append(responses, toUserResponse(user))
+	}
+	response.SuccessWithPage(c, responses, total, page, pageSize)
+}
+
+// GetUser 获取用户信息
+func (h *AdminUserHandler) GetUser(c *gin.Context) {
+	userID, ok := parseIDParam(c, "id", "无效的用户ID")
+	if !ok {
+		return
+	}
+
+	user, err := h.userService.Get(userID)
+	if err != nil {
+		handleUserError(c, err, "查询失败")
+		return
+	}
+
+	response.Success(c, toUserResponse(*user))
+}
+
+// SetUserRole 修改用户角色
+func (h *AdminUserHandler) SetUserRole(c *gin.Context) {
+	userID, ok := parseIDParam(c, "id", "无效的用户ID")
+	if !ok {
+		return
+	}
+
+	var req models.UserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	user, err := h.userService.SetRole(auditContext(c), userID, req.Role)
+	if err != nil {
+		handleAdminUserError(c, err, "修改角色失败")
+		return
+	}
+
+	response.SuccessWithMessage(c, "角色已修改", toUserResponse(*user))
+}
+
+// SetUserStatus 启用或禁用用户，禁用后该用户的所有登录立即失效
+func (h *AdminUserHandler) SetUserStatus(c *gin.Context) {
+	userID, ok := parseIDParam(c, "id", "无效的用户ID")
+	if !ok {
+		return
+	}
+
+	var req models.UserStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	user, err := h.userService.SetStatus(auditContext(c), userID, *req.Status)
+	if err != nil {
+		handleAdminUserError(c, err, "修改状态失败")
+		return
+	}
+
+	response.SuccessWithMessage(c, "状态已修改", toUserResponse(*user))
+}
+
+// UnlockUser 解除登录失败导致的账户锁定
+func (h *AdminUserHandler) UnlockUser(c *gin.Context) {
+	userID, ok := parseIDParam(c, "id", "无效的用户ID")
+	if !ok {
+		return
+	}
+
+	if err := h.lockoutService.Unlock(auditContext(c), userID); err != nil {
+		handleAdminUserError(c, err, "解锁失败")
+		return
+	}
+
+	response.SuccessWithMessage(c, "账户已解锁", nil)
+}
+
+// ResetUserMFA 为丢失身份验证器和恢复码的用户关闭两步验证
+func (h *AdminUserHandler) ResetUserMFA(c *gin.Context) {
+	userID, ok := parseIDParam(c, "id", "无效的用户ID")
+	if !ok {
+		return
+	}
+
+	if err := h.mfaService.Reset(auditContext(c), userID); err != nil {
+		handleAdminUserError(c, err, "重置两步验证失败")
+		return
+	}
+
+	response.SuccessWithMessage(c, "两步验证已重置", nil)
+}
+
+// ListLoginAttempts 分页查询登录记录，可按用户、用户名、IP和是否成功筛选
+func (h *AdminUserHandler) ListLoginAttempts(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	filter := services.LoginAttemptFilter{Identifier: c.Query("identifier"), IP: c.Query("ip")}
+	if userID, err := strconv.ParseUint(c.Query("user_id"), 10, 64); err == nil {
+		filter.UserID = uint(userID)
+	}
+	if success, err := strconv.ParseBool(c.Query("success")); err == nil {
+		filter.Success = &success
+	}
+
+	attempts, total, err := h.lockoutService.Attempts(filter, page, pageSize)
+	if err != nil {
+		response.InternalServerError(c, "查询失败")
+		return
+	}
+
+	response.SuccessWithPage(c, attempts, total, page, pageSize)
+}
+
+// DeleteUser 代为办理线下收到的删除请求：按等待期注销，或立即删除账户和全部数据
+func (h *AdminUserHandler) DeleteUser(c *gin.Context) {
+	userID, ok := parseIDParam(c, "id", "无效的用户ID")
+	if !ok {
+		return
+	}
+
+	var req models.AdminDeletionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	deletion, err := h.deletionService.AdminRequest(auditContext(c), userID, req.Reason, req.Immediate)
+	if err != nil {
+		handleDeletionError(c, err, "删除失败")
+		return
+	}
+
+	message := "注销申请已受理，到期后删除"
+	if deletion.Status == models.DeletionStatusCompleted {
+		message = "账户和全部数据已删除"
+	}
+	response.SuccessWithMessage(c, message, deletion)
+}
+
+// CancelUserDeletion 撤销用户等待中的注销申请
+func (h *AdminUserHandler) CancelUserDeletion(c *gin.Context) {
+	userID, ok := parseIDParam(c, "id", "无效的用户ID")
+	if !ok {
+		return
+	}
+
+	if err := h.deletionService.Cancel(auditContext(c), userID); err != nil {
+		handleDeletionError(c, err, "撤销失败")
+		return
+	}
+
+	response.SuccessWithMessage(c, "注销申请已撤销", nil)
+}
+
+// ListDeletions 分页列出注销申请，可按状态和用户筛选
+func (h *AdminUserHandler) ListDeletions(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	filter := services.DeletionFilter{Status: c.Query("status")}
+	if userID, err := strconv.ParseUint(c.Query("user_id"), 10, 64); err == nil {
+		filter.UserID = uint(userID)
+	}
+
+	deletions, total, err := h.deletionService.List(filter, page, pageSize)
+	if err != nil {
+		response.InternalServerError(c, "查询失败")
+		return
+	}
+
+	response.SuccessWithPage(c, deletions, total, page, pageSize)
+}
+
+// ErasedDataStats 已删除账户数据的匿名汇总，可按类别筛选
+func (h *AdminUserHandler) ErasedDataStats(c *gin.Context) {
+	stats, err := h.deletionService.Stats(c.Query("metric"))
+	if err != nil {
+		response.InternalServerError(c, "查询失败")
+		return
+	}
+
+	response.Success(c, stats)
+}
+
+// ListRoles 列出角色及其权限
+func (h *AdminUserHandler) ListRoles(c *gin.Context) {
+	roles, err := h.rbacService.Roles()
+	if err != nil {
+		response.InternalServerError(c, "查询失败")
+		return
+	}
+
+	response.Success(c, roles)
+}
+
+// SetRoleMFA 设置角色是否要求两步验证
+func (h *AdminUserHandler) SetRoleMFA(c *gin.Context) {
+	var req models.RoleMFARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	role, err := h.mfaService.SetRoleRequirement(auditContext(c), c.Param("name"), *req.RequireMFA)
+	if err != nil {
+		handleAdminUserError(c, err, "设置失败")
+		return
+	}
+
+	response.SuccessWithMessage(c, "设置已保存", gin.H{"name": role.Name, "require_mfa": role.RequireMFA})
+}
+
+// handleAdminUserError 将用户管理的错误映射为统一响应
+func handleAdminUserError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrRoleNotFound), errors.Is(err, services.ErrSelfManagement),
+		errors.Is(err, services.ErrLockoutNotLocked), errors.Is(err, services.ErrMFANotEnabled):
+		response.BadRequest(c, err.Error())
+	default:
+		handleUserError(c, err, fallback)
+	}
+}
@@ -0,0 +1,323 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	"depression_go/inits"
+	"depression_go/internal/models"
+	"depression_go/middleware"
+	"depression_go/pkg/response"
+	"depression_go/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AssessmentSessionHandler 评估会话处理器（草稿逐题保存、恢复与完成）
+type AssessmentSessionHandler struct {
+	assessmentService   *services.AssessmentService
+	localizationService *services.LocalizationService
+}
+
+// NewAssessmentSessionHandler 创建评估会话处理器
+func NewAssessmentSessionHandler() *AssessmentSessionHandler {
+	return &AssessmentSessionHandler{
+		assessmentService:   services.NewAssessmentService(inits.DB),
+		localizationService: services.NewLocalizationService(inits.DB),
+	}
+}
+
+// ListDrafts 获取当前用户未完成的评估草稿
+func (h *AssessmentSessionHandler) ListDrafts(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	drafts, err := h.assessmentService.ListDrafts(userID)
+	if err != nil {
+		response.InternalServerError(c, "查询失败")
+		return
+	}
+
+	responses := make([]models.AssessmentResponse, 0, len(drafts))
+	for _, draft := range drafts {
+		responses = append(responses, toAssessmentResponse(draft))
+	}
+
+	response.Success(c, responses)
+}
+
+// GetSession 获取评估会话详情及已保存的答案，用于恢复作答
+func (h *AssessmentSessionHandler) GetSession(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	assessmentID, ok := parseIDParam(c, "id", "无效的评估ID")
+	if !ok {
+		return
+	}
+
+	assessment, err := h.assessmentService.GetOwned(userID, assessmentID)
+	if err != nil {
+		handleAssessmentError(c, err, "查询失败")
+		return
+	}
+
+	answers, err := h.assessmentService.GetAnswers(assessment.ID)
+	if err != nil {
+		response.InternalServerError(c, "查询失败")
+		return
+	}
+	if err := h.localizationService.LocalizeAnswers(middleware.GetLocale(c), answers); err != nil {
+		response.InternalServerError(c, "查询失败")
+		return
+	}
+
+	response.Success(c, models.AssessmentSessionResponse{
+		AssessmentResponse: toAssessmentResponse(*assessment),
+		Answers:            answers,
+	})
+}
+
+// GetQuestions 获取评估所用问卷版本的题目
+func (h *AssessmentSessionHandler) GetQuestions(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	assessmentID, ok := parseIDParam(c, "id", "无效的评估ID")
+	if !ok {
+		return
+	}
+
+	questions, err := h.assessmentService.GetQuestions(userID, assessmentID)
+	if err != nil {
+		handleAssessmentError(c, err, "查询失败")
+		return
+	}
+
+	// 按请求语言翻译题目
+	localized := make([]models.Question, 0, len(questions))
+	for _, item := range questions {
+		localized = append(localized, item.Question)
+	}
+	if err := h.localizationService.LocalizeQuestions(middleware.GetLocale(c), localized); err != nil {
+		response.InternalServerError(c, "查询失败")
+		return
+	}
+
+	responses := make([]models.PublicQuestionResponse, 0, len(questions))
+	for i, item := range questions {
+		question := toPublicQuestionResponse(localized[i])
+		question.OrderNum = item.OrderNum
+		responses = append(responses, question)
+	}
+
+	response.Success(c, responses)
+}
+
+// NextQuestion 自适应测验获取下一题；done为true时可调用完成接口
+func (h *AssessmentSessionHandler) NextQuestion(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	assessmentID, ok := parseIDParam(c, "id", "无效的评估ID")
+	if !ok {
+		return
+	}
+
+	step, err := h.assessmentService.NextQuestion(userID, assessmentID)
+	if err != nil {
+		handleAssessmentError(c, err, "获取下一题失败")
+		return
+	}
+
+	data := gin.H{
+		"done":     step.Done,
+		"theta":    step.Theta,
+		"theta_se": step.ThetaSE,
+		"answered": step.Answered,
+	}
+	if step.StopReason != "" {
+		data["stop_reason"] = step.StopReason
+	}
+	if step.Question != nil {
+		questions := []models.Question{step.Question.Question}
+		if err := h.localizationService.LocalizeQuestions(middleware.GetLocale(c), questions); err != nil {
+			response.InternalServerError(c, "获取下一题失败")
+			return
+		}
+		question := toPublicQuestionResponse(questions[0])
+		question.OrderNum = step.Question.OrderNum
+		data["question"] = question
+	}
+
+	response.Success(c, data)
+}
+
+// SaveAnswer 保存单题答案（可重复提交覆盖）
+func (h *AssessmentSessionHandler) SaveAnswer(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	assessmentID, ok := parseIDParam(c, "id", "无效的评估ID")
+	if !ok {
+		return
+	}
+	questionID, ok := parseIDParam(c, "question_id", "无效的问题ID")
+	if !ok {
+		return
+	}
+
+	var req models.AnswerValueRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	outcome, err := h.assessmentService.SaveAnswer(userID, assessmentID, models.AnswerRequest{
+		QuestionID:     questionID,
+		AnswerValue:    req.AnswerValue,
+		ResponseTimeMs: req.ResponseTimeMs,
+	})
+	if err != nil {
+		handleAssessmentError(c, err, "保存答案失败")
+		return
+	}
+
+	data := gin.H{"answer": toAnswerResponse(*outcome.Answer)}
+	if outcome.Crisis != nil {
+		data["crisis"] = outcome.Crisis
+	}
+	response.SuccessWithMessage(c, "答案已保存", data)
+}
+
+// Finalize 完成评估并计算结果
+func (h *AssessmentSessionHandler) Finalize(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	assessmentID, ok := parseIDParam(c, "id", "无效的评估ID")
+	if !ok {
+		return
+	}
+
+	outcome, err := h.assessmentService.Finalize(userID, assessmentID)
+	if err != nil {
+		handleAssessmentError(c, err, "提交失败")
+		return
+	}
+
+	h.localizationService.LocalizeResult(middleware.GetLocale(c), &outcome.Result)
+	response.SuccessWithMessage(c, "评估已完成", finalizeResponse(outcome))
+}
+
+// finalizeResponse 评估完成后的响应数据，触发风险条目时附带危机信息
+func finalizeResponse(outcome *services.FinalizeOutcome) gin.H {
+	data := gin.H{
+		"assessment_id": outcome.Assessment.ID,
+		"score":         outcome.Result.Score,
+		"max_score":     outcome.Result.MaxScore,
+		"percentage":    outcome.Result.Percentage,
+		"level":         outcome.Result.Level,
+		"description":   outcome.Result.Description,
+		"suggestions":   outcome.Result.Suggestions,
+	}
+	if outcome.Result.Theta != nil {
+		data["theta"] = *outcome.Result.Theta
+		data["theta_se"] = *outcome.Result.ThetaSE
+	}
+	if outcome.Quality != nil {
+		data["quality"] = outcome.Quality
+	}
+	if outcome.Crisis != nil {
+		data["crisis"] = outcome.Crisis
+	}
+	return data
+}
+
+// handleAssessmentError 将评估服务的错误映射为统一响应
+func handleAssessmentError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrAssessmentNotFound), errors.Is(err, services.ErrQuestionNotFound),
+		errors.Is(err, services.ErrVersionNotFound):
+		response.NotFound(c, err.Error())
+	case errors.Is(err, services.ErrAssessmentClosed), errors.Is(err, services.ErrNoAnswers),
+		errors.Is(err, services.ErrQuestionNotInVersion), errors.Is(err, services.ErrAssessmentNotCompleted),
+		errors.Is(err, services.ErrInvalidMode), errors.Is(err, services.ErrNotAdaptive),
+		errors.Is(err, services.ErrNoAdaptiveItems), errors.Is(err, services.ErrInvalidOrderMode),
+		errors.Is(err, services.ErrAdaptiveOrderMode), errors.Is(err, services.ErrOrderSeedRequired),
+		errors.Is(err, services.ErrNotQuestionnaire), errors.Is(err, services.ErrInvalidAnswerValue):
+		response.BadRequest(c, err.Error())
+	case errors.Is(err, services.ErrAdaptiveNotDone):
+		response.Error(c, 409, err.Error())
+	default:
+		response.InternalServerError(c, fallback)
+	}
+}
+
+// parseIDParam 解析路径中的ID参数，失败时直接返回400
+func parseIDParam(c *gin.Context, name, message string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 32)
+	if err != nil || id == 0 {
+		response.BadRequest(c, message)
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// toAssessmentResponse 转换为评估响应格式
+func toAssessmentResponse(assessment models.Assessment) models.AssessmentResponse {
+	var qualityReasons []string
+	if assessment.QualityReasons != "" {
+		_ = json.Unmarshal([]byte(assessment.QualityReasons), &qualityReasons)
+	}
+	var questionOrder []uint
+	if assessment.QuestionOrder != "" {
+		_ = json.Unmarshal([]byte(assessment.QuestionOrder), &questionOrder)
+	}
+	var fusionInputs []models.FusionInput
+	if assessment.FusionInputs != "" {
+		_ = json.Unmarshal([]byte(assessment.FusionInputs), &fusionInputs)
+	}
+	return models.AssessmentResponse{
+		ID:                     assessment.ID,
+		UserID:                 assessment.UserID,
+		Title:                  assessment.Title,
+		Type:                   assessment.Type,
+		TotalScore:             assessment.TotalScore,
+		MaxScore:               assessment.MaxScore,
+		Level:                  assessment.Level,
+		Result:                 assessment.Result,
+		Status:                 assessment.Status,
+		RiskFlagged:            assessment.RiskFlagged,
+		QuestionnaireVersionID: assessment.QuestionnaireVersionID,
+		ScoringProfileID:       assessment.ScoringProfileID,
+		Mode:                   assessment.Mode,
+		ThetaEstimate:          assessment.ThetaEstimate,
+		ThetaSE:                assessment.ThetaSE,
+		OrderMode:              assessment.OrderMode,
+		OrderSeed:              assessment.OrderSeed,
+		QuestionOrder:          questionOrder,
+		QualityFlag:            assessment.QualityFlag,
+		QualityConfidence:      assessment.QualityConfidence,
+		QualityReasons:         qualityReasons,
+		Confidence:             assessment.Confidence,
+		FusionInputs:           fusionInputs,
+		ExpiresAt:              assessment.ExpiresAt,
+		CompletedAt:            assessment.CompletedAt,
+		CreatedAt:              assessment.CreatedAt,
+		UpdatedAt:              assessment.UpdatedAt,
+	}
+}
+
+// toAnswerResponse 转换为答案响应格式
+func toAnswerResponse(answer models.Answer) models.AnswerResponse {
+	return models.AnswerResponse{
+		ID:           answer.ID,
+		UserID:       answer.UserID,
+		QuestionID:   answer.QuestionID,
+		AssessmentID: answer.AssessmentID,
+		Content:      answer.Content,
+		Score:        answer.Score,
+		AnswerValue:  answer.AnswerValue,
+		CreatedAt:    answer.CreatedAt,
+		UpdatedAt:    answer.UpdatedAt,
+
+		ResponseTimeMs: answer.ResponseTimeMs,
+		Position:       answer.Position,
+	}
+}
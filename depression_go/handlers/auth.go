@@ -1,26 +1,48 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
 	"depression_go/inits"
 	"depression_go/internal/models"
 	"depression_go/middleware"
+	"depression_go/pkg/i18n"
 	"depression_go/pkg/response"
-	"depression_go/pkg/token"
 	"depression_go/pkg/utils"
+	"depression_go/services"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 // AuthHandler 认证处理器
 type AuthHandler struct {
-	db *gorm.DB
+	db             *gorm.DB
+	userService    *services.UserService
+	tokenService   *services.TokenService
+	lockoutService *services.LockoutService
+	accountService *services.AccountService
+	mfaService     *services.MFAService
+	baiduService   *services.BaiduAIService
 }
 
 // NewAuthHandler 创建认证处理器
 func NewAuthHandler() *AuthHandler {
 	return &AuthHandler{
-		db: inits.DB,
+		db:             inits.DB,
+		userService:    services.NewUserService(inits.DB),
+		tokenService:   services.NewTokenService(inits.DB),
+		lockoutService: services.NewLockoutService(inits.DB),
+		accountService: services.NewAccountService(inits.DB),
+		mfaService:     services.NewMFAService(inits.DB),
+		baiduService:   services.NewBaiduAIService(),
 	}
 }
 
@@ -35,7 +57,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	}
 
 	// 验证密码强度
-	if valid, msg := utils.ValidatePassword(req.Password); !valid {
+	if valid, msg := utils.ValidatePassword(req.Password, req.Username, req.Email); !valid {
 		response.BadRequest(c, msg)
 		return
 	}
@@ -69,6 +91,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		Gender:   req.Gender,
 		Phone:    req.Phone,
 		Status:   1,
+		Role:     models.RoleUser,
 	}
 
 	if err := h.db.Create(&user).Error; err != nil {
@@ -76,30 +99,22 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	// 生成JWT令牌
-	tokenString, err := token.GenerateToken(user.ID, user.Username)
+	// 发送邮箱验证邮件，发送失败不影响注册，用户可以稍后重新发送
+	if err := h.accountService.SendVerification(&user, middleware.GetLocale(c)); err != nil {
+		log.Printf("发送邮箱验证邮件失败(用户%d): %v", user.ID, err)
+	}
+
+	// 签发访问令牌和刷新令牌
+	pair, err := h.tokenService.Issue(&user, clientInfo(c))
 	if err != nil {
 		response.InternalServerError(c, "令牌生成失败")
 		return
 	}
 
 	// 返回用户信息（不包含密码）
-	userResponse := models.UserResponse{
-		ID:        user.ID,
-		Username:  user.Username,
-		Email:     user.Email,
-		Age:       user.Age,
-		Gender:    user.Gender,
-		Phone:     user.Phone,
-		Avatar:    user.Avatar,
-		Status:    user.Status,
-		CreatedAt: user.CreatedAt,
-	}
-
-	response.SuccessWithMessage(c, "注册成功", gin.H{
-		"user":  userResponse,
-		"token": tokenString,
-	})
+	userResponse := toUserResponse(user)
+
+	response.SuccessWithMessage(c, "注册成功", models.AuthResponse{User: &userResponse, TokenPair: *pair})
 }
 
 // Login 用户登录
@@ -112,49 +127,100 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	// 查找用户
+	// 查找用户，不存在时仍按填写的用户名计数，避免据此判断账户是否存在
+	client := clientInfo(c)
+	attempt := models.LoginAttempt{Identifier: req.Username, IP: client.IP, UserAgent: client.UserAgent}
 	var user models.User
-	if err := h.db.Where("username = ? OR email = ?", req.Username, req.Username).First(&user).Error; err != nil {
-		response.BadRequest(c, "用户名或密码错误")
+	var found *models.User
+	err := h.db.Where("username = ? OR email = ?", req.Username, req.Username).First(&user).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		response.InternalServerError(c, "登录失败")
 		return
 	}
+	if err == nil {
+		found = &user
+		attempt.UserID = user.ID
+	}
+	accountKey := services.AccountKey(found, req.Username)
 
-	// 检查用户状态
-	if user.Status == 0 {
-		response.Forbidden(c, "账户已被禁用")
+	// 锁定期间不校验密码
+	if err := h.lockoutService.Check(accountKey, client.IP); err != nil {
+		attempt.Reason = models.LoginResultLocked
+		if errors.Is(err, services.ErrTooManyAttempts) {
+			attempt.Reason = models.LoginResultIPBlocked
+		}
+		h.lockoutService.LogAttempt(attempt)
+		handleLockoutError(c, err)
+		return
+	}
+
+	// 用户不存在时也计算一次哈希，避免据响应耗时判断账户是否存在
+	if found == nil {
+		utils.CheckPassword(req.Password, utils.DummyPasswordHash())
+		attempt.Reason = models.LoginResultUnknownUser
+		h.loginFailed(c, attempt, accountKey)
 		return
 	}
 
 	// 验证密码
 	if !utils.CheckPassword(req.Password, user.Password) {
-		response.BadRequest(c, "用户名或密码错误")
+		attempt.Reason = models.LoginResultWrongPassword
+		h.loginFailed(c, attempt, accountKey)
+		return
+	}
+
+	// 密码正确后才提示账户已禁用，禁用账户的猜测同样计入失败次数
+	if user.Status == 0 {
+		attempt.Reason = models.LoginResultDisabled
+		h.lockoutService.LogAttempt(attempt)
+		response.Forbidden(c, "账户已被禁用")
 		return
 	}
+	h.userService.UpgradePasswordHash(&user, req.Password)
 
-	// 生成JWT令牌
-	tokenString, err := token.GenerateToken(user.ID, user.Username)
+	// 已启用两步验证或所属角色要求两步验证时，先签发两步验证令牌，验证通过后才清零失败次数
+	challenge, err := h.mfaService.Challenge(&user)
+	if err != nil {
+		response.InternalServerError(c, "登录失败")
+		return
+	}
+	if challenge != nil {
+		attempt.Reason = models.LoginResultMFAPending
+		h.lockoutService.LogAttempt(attempt)
+		message := "请输入两步验证码"
+		if challenge.EnrollmentRequired {
+			message = "所属角色要求两步验证，请先完成绑定"
+		}
+		response.SuccessWithMessage(c, message, challenge)
+		return
+	}
+
+	h.lockoutService.RecordSuccess(accountKey)
+	attempt.Success = true
+	attempt.Reason = models.LoginResultSuccess
+	h.lockoutService.LogAttempt(attempt)
+
+	// 签发访问令牌和刷新令牌
+	pair, err := h.tokenService.Issue(&user, client)
 	if err != nil {
 		response.InternalServerError(c, "令牌生成失败")
 		return
 	}
 
 	// 返回用户信息（不包含密码）
-	userResponse := models.UserResponse{
-		ID:        user.ID,
-		Username:  user.Username,
-		Email:     user.Email,
-		Age:       user.Age,
-		Gender:    user.Gender,
-		Phone:     user.Phone,
-		Avatar:    user.Avatar,
-		Status:    user.Status,
-		CreatedAt: user.CreatedAt,
-	}
-
-	response.SuccessWithMessage(c, "登录成功", gin.H{
-		"user":  userResponse,
-		"token": tokenString,
-	})
+	userResponse := toUserResponse(user)
+
+	response.SuccessWithMessage(c, "登录成功", models.AuthResponse{User: &userResponse, TokenPair: *pair})
+}
+
+// loginFailed 记录失败并返回错误，本次失败触发锁定时直接返回锁定信息
+func (h *AuthHandler) loginFailed(c *gin.Context, attempt models.LoginAttempt, accountKey string) {
+	h.lockoutService.LogAttempt(attempt)
+	if err := h.lockoutService.RecordFailure(accountKey, attempt.IP); err != nil {
+		handleLockoutError(c, err)
+		return
+	}
+	response.BadRequest(c, "用户名或密码错误")
 }
 
 // GetProfile 获取用户信息
@@ -168,17 +234,221 @@ func (h *AuthHandler) GetProfile(c *gin.Context) {
 	}
 
 	// 返回用户信息（不包含密码）
-	userResponse := models.UserResponse{
-		ID:        user.ID,
-		Username:  user.Username,
-		Email:     user.Email,
-		Age:       user.Age,
-		Gender:    user.Gender,
-		Phone:     user.Phone,
-		Avatar:    user.Avatar,
-		Status:    user.Status,
-		CreatedAt: user.CreatedAt,
-	}
+	userResponse := toUserResponse(user)
 
 	response.Success(c, userResponse)
 }
+
+// UpdateLocale 设置语言偏好，为空表示跟随浏览器的Accept-Language
+func (h *AuthHandler) UpdateLocale(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	var req models.UserLocaleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	locale := ""
+	if req.Locale != "" {
+		locale = i18n.Normalize(req.Locale)
+		if locale == "" {
+			response.BadRequest(c, "不支持的语言，可选: "+strings.Join(i18n.Supported(), ", "))
+			return
+		}
+	}
+
+	if err := h.db.Model(&models.User{}).Where("id = ?", userID).Update("locale", locale).Error; err != nil {
+		response.InternalServerError(c, "设置失败")
+		return
+	}
+
+	response.SuccessWithMessage(c, "语言偏好已更新", gin.H{"locale": locale})
+}
+
+// UpdateProfile 更新用户信息，只修改请求中给出的字段
+func (h *AuthHandler) UpdateProfile(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	var req models.UserUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	user, err := h.userService.UpdateProfile(userID, req)
+	if err != nil {
+		handleUserError(c, err, "更新失败")
+		return
+	}
+
+	response.SuccessWithMessage(c, "用户信息已更新", toUserResponse(*user))
+}
+
+// UploadAvatar 上传头像，图片校验和保存与人脸检测上传相同
+func (h *AuthHandler) UploadAvatar(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	file, err := c.FormFile("avatar")
+	if err != nil {
+		response.BadRequest(c, "请选择要上传的头像")
+		return
+	}
+	if err := h.baiduService.ValidateImage(file.Filename, file.Size); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	ext := strings.ToLower(filepath.Ext(file.Filename))
+	if ext == "" {
+		ext = ".jpg"
+	}
+	filename := fmt.Sprintf("avatar_%d_%s_%s%s",
+		userID,
+		time.Now().Format("20060102_150405"),
+		uuid.New().String()[:8],
+		ext,
+	)
+
+	src, err := file.Open()
+	if err != nil {
+		response.InternalServerError(c, "打开文件失败")
+		return
+	}
+	defer src.Close()
+
+	if _, err := h.baiduService.SaveImage(src, filename); err != nil {
+		response.InternalServerError(c, "保存文件失败: "+err.Error())
+		return
+	}
+
+	user, err := h.userService.SetAvatar(userID, "/uploads/"+filename)
+	if err != nil {
+		handleUserError(c, err, "更新头像失败")
+		return
+	}
+
+	response.SuccessWithMessage(c, "头像已更新", toUserResponse(*user))
+}
+
+// ChangePassword 修改密码，之前签发的令牌全部失效，返回新的令牌
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	var req models.UserPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	user, err := h.userService.ChangePassword(userID, req)
+	if err != nil {
+		handleUserError(c, err, "修改密码失败")
+		return
+	}
+
+	pair, err := h.tokenService.Issue(user, clientInfo(c))
+	if err != nil {
+		response.InternalServerError(c, "令牌生成失败")
+		return
+	}
+
+	response.SuccessWithMessage(c, "密码已修改，其他设备需要重新登录", models.AuthResponse{TokenPair: *pair})
+}
+
+// Refresh 用刷新令牌换取新的访问令牌和刷新令牌，旧的刷新令牌随即失效
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req models.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	pair, err := h.tokenService.Refresh(req.RefreshToken, clientInfo(c))
+	if err != nil {
+		handleTokenError(c, err, "刷新令牌失败")
+		return
+	}
+
+	response.Success(c, models.AuthResponse{TokenPair: *pair})
+}
+
+// Logout 注销：吊销当前会话，该会话的访问令牌和刷新令牌随即失效
+func (h *AuthHandler) Logout(c *gin.Context) {
+	if err := h.tokenService.Logout(middleware.GetClaims(c)); err != nil {
+		response.InternalServerError(c, "注销失败")
+		return
+	}
+
+	response.SuccessWithMessage(c, "已注销", nil)
+}
+
+// handleUserError 将用户服务的错误转换为响应
+func handleUserError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrUserNotFound):
+		response.NotFound(c, err.Error())
+	case errors.Is(err, services.ErrInvalidProfile),
+		errors.Is(err, services.ErrWrongPassword),
+		errors.Is(err, services.ErrWeakPassword):
+		response.BadRequest(c, err.Error())
+	default:
+		response.InternalServerError(c, fallback)
+	}
+}
+
+// handleTokenError 将令牌服务的错误转换为响应
+func handleTokenError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrInvalidRefreshToken),
+		errors.Is(err, services.ErrRefreshTokenReused):
+		response.Unauthorized(c, err.Error())
+	case errors.Is(err, services.ErrUserDisabled):
+		response.Forbidden(c, err.Error())
+	default:
+		response.InternalServerError(c, fallback)
+	}
+}
+
+// handleLockoutError 登录被锁定：账户锁定返回423，IP锁定返回429，并给出解除时间
+func handleLockoutError(c *gin.Context, err error) {
+	var lockErr *services.LockoutError
+	if !errors.As(err, &lockErr) {
+		response.InternalServerError(c, "登录失败")
+		return
+	}
+	code := 423
+	if errors.Is(err, services.ErrTooManyAttempts) {
+		code = 429
+	}
+	retryAfter := lockErr.RetryAfter()
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	response.ErrorWithData(c, code, lockErr.Error(), gin.H{
+		"locked_until": lockErr.Until,
+		"retry_after":  retryAfter,
+	})
+}
+
+// clientInfo 请求的客户端信息，用于记录登录会话
+func clientInfo(c *gin.Context) services.ClientInfo {
+	return services.ClientInfo{UserAgent: c.GetHeader("User-Agent"), IP: c.ClientIP()}
+}
+
+// toUserResponse 转换为用户信息响应格式（不包含密码）
+func toUserResponse(user models.User) models.UserResponse {
+	return models.UserResponse{
+		ID:            user.ID,
+		Username:      user.Username,
+		Email:         user.Email,
+		Age:           user.Age,
+		Gender:        user.Gender,
+		Phone:         user.Phone,
+		Avatar:        user.Avatar,
+		Status:        user.Status,
+		Role:          user.Role,
+		Locale:        user.Locale,
+		EmailVerified: user.EmailVerifiedAt != nil,
+		PasswordSet:   !user.RandomPassword,
+		CreatedAt:     user.CreatedAt,
+	}
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"depression_go/inits"
+	"depression_go/internal/models"
+	"depression_go/middleware"
+	"depression_go/pkg/response"
+	"depression_go/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CheckinHandler 每日打卡处理器
+type CheckinHandler struct {
+	checkinService *services.CheckinService
+}
+
+// NewCheckinHandler 创建每日打卡处理器
+func NewCheckinHandler() *CheckinHandler {
+	return &CheckinHandler{
+		checkinService: services.NewCheckinService(inits.DB),
+	}
+}
+
+// GetItems 获取打卡题目
+func (h *CheckinHandler) GetItems(c *gin.Context) {
+	questions, err := h.checkinService.Items(middleware.GetLocale(c))
+	if err != nil {
+		response.InternalServerError(c, "查询失败")
+		return
+	}
+
+	responses := make([]models.PublicQuestionResponse, 0, len(questions))
+	for _, question := range questions {
+		responses = append(responses, toPublicQuestionResponse(question))
+	}
+
+	response.Success(c, responses)
+}
+
+// Submit 提交一次打卡
+func (h *CheckinHandler) Submit(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	var req models.CheckinRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	assessment, answers, err := h.checkinService.Submit(userID, req)
+	if err != nil {
+		handleCheckinError(c, err, "打卡失败")
+		return
+	}
+
+	ids := make([]uint, 0, len(answers))
+	for _, answer := range answers {
+		ids = append(ids, answer.QuestionID)
+	}
+	titles, err := h.checkinService.Titles(ids, middleware.GetLocale(c))
+	if err != nil {
+		response.InternalServerError(c, "打卡失败")
+		return
+	}
+
+	result := models.CheckinResponse{
+		ID:        assessment.ID,
+		Date:      assessment.CreatedAt.In(time.Local).Format("2006-01-02"),
+		Answers:   make([]models.CheckinAnswerResponse, 0, len(answers)),
+		CreatedAt: assessment.CreatedAt,
+	}
+	for _, answer := range answers {
+		result.Answers = append(result.Answers, models.CheckinAnswerResponse{
+			QuestionID:  answer.QuestionID,
+			Title:       titles[answer.QuestionID],
+			AnswerValue: answer.AnswerValue,
+			Content:     answer.Content,
+		})
+	}
+
+	response.SuccessWithMessage(c, "打卡成功", result)
+}
+
+// GetDaily 按天汇总最近的打卡
+func (h *CheckinHandler) GetDaily(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	days, ok := parseCheckinDays(c, 7)
+	if !ok {
+		return
+	}
+
+	result, err := h.checkinService.Daily(userID, days, middleware.GetLocale(c))
+	if err != nil {
+		response.InternalServerError(c, "查询失败")
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// GetStreak 获取连续打卡天数
+func (h *CheckinHandler) GetStreak(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	streak, err := h.checkinService.Streak(userID)
+	if err != nil {
+		response.InternalServerError(c, "查询失败")
+		return
+	}
+
+	response.Success(c, streak)
+}
+
+// GetTrend 获取各打卡题目的趋势
+func (h *CheckinHandler) GetTrend(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	days, ok := parseCheckinDays(c, 30)
+	if !ok {
+		return
+	}
+
+	trends, err := h.checkinService.Trend(userID, days, middleware.GetLocale(c))
+	if err != nil {
+		response.InternalServerError(c, "查询失败")
+		return
+	}
+
+	response.Success(c, gin.H{
+		"days":  days,
+		"items": trends,
+	})
+}
+
+// parseCheckinDays 解析统计天数参数，范围1-365
+func parseCheckinDays(c *gin.Context, fallback int) (int, bool) {
+	value := c.Query("days")
+	if value == "" {
+		return fallback, true
+	}
+	days, err := strconv.Atoi(value)
+	if err != nil || days < 1 || days > 365 {
+		response.BadRequest(c, "days必须是1-365之间的整数")
+		return 0, false
+	}
+	return days, true
+}
+
+// handleCheckinError 将打卡服务的错误映射为响应
+func handleCheckinError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrInvalidCheckin), errors.Is(err, services.ErrCheckinLimit),
+		errors.Is(err, services.ErrNoCheckinItems):
+		response.BadRequest(c, err.Error())
+	default:
+		response.InternalServerError(c, fallback)
+	}
+}
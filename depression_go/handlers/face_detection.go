@@ -19,15 +19,17 @@ import (
 
 // FaceDetectionHandler 人脸检测处理器
 type FaceDetectionHandler struct {
-	db           *gorm.DB
-	baiduService *services.BaiduAIService
+	db                  *gorm.DB
+	baiduService        *services.BaiduAIService
+	localizationService *services.LocalizationService
 }
 
 // NewFaceDetectionHandler 创建人脸检测处理器
 func NewFaceDetectionHandler() *FaceDetectionHandler {
 	return &FaceDetectionHandler{
-		db:           inits.DB,
-		baiduService: services.NewBaiduAIService(),
+		db:                  inits.DB,
+		baiduService:        services.NewBaiduAIService(),
+		localizationService: services.NewLocalizationService(inits.DB),
 	}
 }
 
@@ -120,7 +122,7 @@ func (h *FaceDetectionHandler) UploadImage(c *gin.Context) {
 		Confidence: faceDetection.Confidence,
 		Score:      faceDetection.Score,
 		Level:      faceDetection.Level,
-		Result:     faceDetection.Result,
+		Result:     h.localizationService.EmotionText(middleware.GetLocale(c), faceDetection.Emotion, faceDetection.Level),
 		Status:     faceDetection.Status,
 		CreatedAt:  faceDetection.CreatedAt,
 		UpdatedAt:  faceDetection.UpdatedAt,
@@ -164,7 +166,8 @@ func (h *FaceDetectionHandler) GetDetectionHistory(c *gin.Context) {
 		return
 	}
 
-	// 转换为响应格式
+	// 转换为响应格式，结果描述按请求语言给出
+	locale := middleware.GetLocale(c)
 	var responses []models.FaceDetectionResponse
 	for _, detection := range detections {
 		responses = append(responses, models.FaceDetectionResponse{
@@ -176,7 +179,7 @@ func (h *FaceDetectionHandler) GetDetectionHistory(c *gin.Context) {
 			Confidence: detection.Confidence,
 			Score:      detection.Score,
 			Level:      detection.Level,
-			Result:     detection.Result,
+			Result:     h.localizationService.EmotionText(locale, detection.Emotion, detection.Level),
 			Status:     detection.Status,
 			CreatedAt:  detection.CreatedAt,
 			UpdatedAt:  detection.UpdatedAt,
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"depression_go/inits"
+	"depression_go/internal/models"
+	"depression_go/middleware"
+	"depression_go/pkg/response"
+	"depression_go/pkg/sentiment"
+	"depression_go/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JournalHandler 心情日记处理器
+type JournalHandler struct {
+	journalService *services.JournalService
+}
+
+// NewJournalHandler 创建心情日记处理器
+func NewJournalHandler() *JournalHandler {
+	return &JournalHandler{
+		journalService: services.NewJournalService(inits.DB),
+	}
+}
+
+// CreateEntry 写一篇日记
+func (h *JournalHandler) CreateEntry(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	var req models.JournalEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	outcome, err := h.journalService.Create(userID, req)
+	if err != nil {
+		handleJournalError(c, err, "保存日记失败")
+		return
+	}
+
+	response.SuccessWithMessage(c, "日记保存成功", toJournalOutcomeResponse(outcome))
+}
+
+// ListEntries 分页获取日记列表
+func (h *JournalHandler) ListEntries(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	// 获取分页参数
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	entries, total, err := h.journalService.List(userID, page, pageSize)
+	if err != nil {
+		response.InternalServerError(c, "查询失败")
+		return
+	}
+
+	responses := make([]models.JournalEntryResponse, 0, len(entries))
+	for _, entry := range entries {
+		responses = append(responses, toJournalEntryResponse(entry, nil))
+	}
+
+	response.SuccessWithPage(c, responses, total, page, pageSize)
+}
+
+// GetEntry 获取单篇日记
+func (h *JournalHandler) GetEntry(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	entryID, ok := parseIDParam(c, "id", "无效的日记ID")
+	if !ok {
+		return
+	}
+
+	entry, err := h.journalService.Get(userID, entryID)
+	if err != nil {
+		handleJournalError(c, err, "获取日记失败")
+		return
+	}
+
+	response.Success(c, toJournalEntryResponse(*entry, nil))
+}
+
+// UpdateEntry 修改日记并重新分析
+func (h *JournalHandler) UpdateEntry(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	entryID, ok := parseIDParam(c, "id", "无效的日记ID")
+	if !ok {
+		return
+	}
+
+	var req models.JournalEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	outcome, err := h.journalService.Update(userID, entryID, req)
+	if err != nil {
+		handleJournalError(c, err, "修改日记失败")
+		return
+	}
+
+	response.SuccessWithMessage(c, "日记修改成功", toJournalOutcomeResponse(outcome))
+}
+
+// DeleteEntry 删除日记
+func (h *JournalHandler) DeleteEntry(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	entryID, ok := parseIDParam(c, "id", "无效的日记ID")
+	if !ok {
+		return
+	}
+
+	if err := h.journalService.Delete(userID, entryID); err != nil {
+		handleJournalError(c, err, "删除日记失败")
+		return
+	}
+
+	response.SuccessWithMessage(c, "日记已删除", nil)
+}
+
+// handleJournalError 将日记服务的错误映射为响应
+func handleJournalError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrJournalNotFound):
+		response.NotFound(c, err.Error())
+	case errors.Is(err, services.ErrInvalidJournal):
+		response.BadRequest(c, err.Error())
+	default:
+		response.InternalServerError(c, fallback)
+	}
+}
+
+// toJournalEntryResponse 转换为日记响应格式，result不为空时带上命中的情感词
+func toJournalEntryResponse(entry models.JournalEntry, result *sentiment.Result) models.JournalEntryResponse {
+	resp := models.JournalEntryResponse{
+		ID:             entry.ID,
+		UserID:         entry.UserID,
+		Content:        entry.Content,
+		Mood:           entry.Mood,
+		SentimentScore: entry.SentimentScore,
+		SentimentLabel: entry.SentimentLabel,
+		Language:       entry.Language,
+		Analyzer:       entry.Analyzer,
+		Score:          entry.Score,
+		Level:          entry.Level,
+		CreatedAt:      entry.CreatedAt,
+		UpdatedAt:      entry.UpdatedAt,
+	}
+	if result != nil {
+		resp.Matched = result.Matched
+	}
+	return resp
+}
+
+// toJournalOutcomeResponse 新建或修改日记的响应，带上命中的情感词和危机信息
+func toJournalOutcomeResponse(outcome *services.JournalOutcome) models.JournalEntryResponse {
+	resp := toJournalEntryResponse(*outcome.Entry, outcome.Sentiment)
+	resp.Crisis = outcome.Crisis
+	return resp
+}
@@ -0,0 +1,298 @@
+package handlers
+
+import (
+	"errors"
+
+	"depression_go/inits"
+	"depression_go/internal/models"
+	"depression_go/middleware"
+	"depression_go/pkg/response"
+	"depression_go/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MFAHandler 两步验证处理器
+type MFAHandler struct {
+	userService    *services.UserService
+	mfaService     *services.MFAService
+	tokenService   *services.TokenService
+	lockoutService *services.LockoutService
+}
+
+// NewMFAHandler 创建两步验证处理器
+func NewMFAHandler() *MFAHandler {
+	return &MFAHandler{
+		userService:    services.NewUserService(inits.DB),
+		mfaService:     services.NewMFAService(inits.DB),
+		tokenService:   services.NewTokenService(inits.DB),
+		lockoutService: services.NewLockoutService(inits.DB),
+	}
+}
+
+// GetStatus 两步验证状态
+func (h *MFAHandler) GetStatus(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	status, err := h.mfaService.Status(user)
+	if err != nil {
+		response.InternalServerError(c, "查询失败")
+		return
+	}
+
+	response.Success(c, status)
+}
+
+// Setup 生成TOTP密钥和二维码地址，确认前不生效；登录时完成绑定也使用该接口
+func (h *MFAHandler) Setup(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	setup, err := h.mfaService.Setup(user)
+	if err != nil {
+		handleMFAError(c, err, "生成密钥失败")
+		return
+	}
+
+	response.Success(c, setup)
+}
+
+// Confirm 提交身份验证器上的验证码确认绑定，返回恢复码
+func (h *MFAHandler) Confirm(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req models.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	codes, err := h.mfaService.Confirm(user, req.Code)
+	if err != nil {
+		handleMFAError(c, err, "启用失败")
+		return
+	}
+
+	response.SuccessWithMessage(c, "两步验证已启用，请妥善保存恢复码", models.MFAEnrollResponse{RecoveryCodes: codes})
+}
+
+// Disable 关闭两步验证，需要当前密码和验证码；错误计入登录失败次数
+func (h *MFAHandler) Disable(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req models.MFADisableRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	if !h.guarded(c, user, "关闭失败", func() error {
+		return h.mfaService.Disable(user, req.Password, req.Code)
+	}) {
+		return
+	}
+
+	response.SuccessWithMessage(c, "两步验证已关闭", nil)
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码，之前的恢复码全部失效；验证码错误计入登录失败次数
+func (h *MFAHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req models.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	var codes []string
+	if !h.guarded(c, user, "生成恢复码失败", func() error {
+		var err error
+		codes, err = h.mfaService.RegenerateRecoveryCodes(user.ID, req.Code)
+		return err
+	}) {
+		return
+	}
+
+	response.SuccessWithMessage(c, "恢复码已重新生成，请妥善保存", models.MFAEnrollResponse{RecoveryCodes: codes})
+}
+
+// VerifyLogin 登录第二步：校验验证码或恢复码，通过后签发访问令牌和刷新令牌；错误计入登录失败次数
+func (h *MFAHandler) VerifyLogin(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req models.MFAVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+	if (req.Code == "") == (req.RecoveryCode == "") {
+		response.BadRequest(c, "请提供验证码或恢复码其中之一")
+		return
+	}
+
+	client := clientInfo(c)
+	attempt := models.LoginAttempt{Identifier: user.Username, UserID: user.ID, IP: client.IP, UserAgent: client.UserAgent}
+	accountKey := services.AccountKey(user, "")
+	if h.lockedOut(c, attempt, accountKey) {
+		return
+	}
+
+	if err := h.mfaService.Verify(user.ID, req.Code, req.RecoveryCode); err != nil {
+		h.codeFailed(c, attempt, accountKey, err, "验证失败")
+		return
+	}
+
+	h.lockoutService.RecordSuccess(accountKey)
+	attempt.Success = true
+	attempt.Reason = models.LoginResultSuccess
+	h.lockoutService.LogAttempt(attempt)
+
+	pair, err := h.tokenService.Issue(user, client)
+	if err != nil {
+		response.InternalServerError(c, "令牌生成失败")
+		return
+	}
+
+	userResponse := toUserResponse(*user)
+	response.SuccessWithMessage(c, "登录成功", models.AuthResponse{User: &userResponse, TokenPair: *pair})
+}
+
+// ConfirmLogin 登录时完成绑定：角色要求两步验证的用户确认绑定后直接登录，返回恢复码和令牌；错误计入登录失败次数
+func (h *MFAHandler) ConfirmLogin(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req models.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	client := clientInfo(c)
+	attempt := models.LoginAttempt{Identifier: user.Username, UserID: user.ID, IP: client.IP, UserAgent: client.UserAgent}
+	accountKey := services.AccountKey(user, "")
+	if h.lockedOut(c, attempt, accountKey) {
+		return
+	}
+
+	codes, err := h.mfaService.Confirm(user, req.Code)
+	if err != nil {
+		h.codeFailed(c, attempt, accountKey, err, "启用失败")
+		return
+	}
+
+	h.lockoutService.RecordSuccess(accountKey)
+	attempt.Success = true
+	attempt.Reason = models.LoginResultSuccess
+	h.lockoutService.LogAttempt(attempt)
+
+	pair, err := h.tokenService.Issue(user, client)
+	if err != nil {
+		response.InternalServerError(c, "令牌生成失败")
+		return
+	}
+
+	userResponse := toUserResponse(*user)
+	response.SuccessWithMessage(c, "两步验证已启用，请妥善保存恢复码", models.MFAEnrollResponse{
+		RecoveryCodes: codes,
+		Auth:          &models.AuthResponse{User: &userResponse, TokenPair: *pair},
+	})
+}
+
+// lockedOut 账户或来源IP处于锁定期时记录本次尝试并返回锁定信息
+func (h *MFAHandler) lockedOut(c *gin.Context, attempt models.LoginAttempt, accountKey string) bool {
+	err := h.lockoutService.Check(accountKey, attempt.IP)
+	if err == nil {
+		return false
+	}
+	attempt.Reason = models.LoginResultLocked
+	if errors.Is(err, services.ErrTooManyAttempts) {
+		attempt.Reason = models.LoginResultIPBlocked
+	}
+	h.lockoutService.LogAttempt(attempt)
+	handleLockoutError(c, err)
+	return true
+}
+
+// codeFailed 登录第二步失败：验证码错误时计入失败次数，本次失败触发锁定时直接返回锁定信息
+func (h *MFAHandler) codeFailed(c *gin.Context, attempt models.LoginAttempt, accountKey string, err error, fallback string) {
+	if !errors.Is(err, services.ErrInvalidMFACode) {
+		handleMFAError(c, err, fallback)
+		return
+	}
+	attempt.Reason = models.LoginResultWrongMFACode
+	h.lockoutService.LogAttempt(attempt)
+	if lockErr := h.lockoutService.RecordFailure(accountKey, attempt.IP); lockErr != nil {
+		handleLockoutError(c, lockErr)
+		return
+	}
+	response.BadRequest(c, err.Error())
+}
+
+// guarded 已登录后校验密码或验证码的操作与登录共用失败计数：锁定期间拒绝，密码或验证码错误计入失败次数；返回false时已写入响应
+func (h *MFAHandler) guarded(c *gin.Context, user *models.User, fallback string, action func() error) bool {
+	accountKey := services.AccountKey(user, "")
+	ip := c.ClientIP()
+	if err := h.lockoutService.Check(accountKey, ip); err != nil {
+		handleLockoutError(c, err)
+		return false
+	}
+	if err := action(); err != nil {
+		if errors.Is(err, services.ErrInvalidMFACode) || errors.Is(err, services.ErrWrongPassword) {
+			if lockErr := h.lockoutService.RecordFailure(accountKey, ip); lockErr != nil {
+				handleLockoutError(c, lockErr)
+				return false
+			}
+		}
+		handleMFAError(c, err, fallback)
+		return false
+	}
+	h.lockoutService.RecordSuccess(accountKey)
+	return true
+}
+
+// currentUser 查询当前用户，失败时已写入响应
+func (h *MFAHandler) currentUser(c *gin.Context) (*models.User, bool) {
+	user, err := h.userService.Get(middleware.GetUserID(c))
+	if err != nil {
+		handleUserError(c, err, "查询失败")
+		return nil, false
+	}
+	return user, true
+}
+
+// handleMFAError 将两步验证的错误转换为响应
+func handleMFAError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrMFANotEnabled),
+		errors.Is(err, services.ErrMFAAlreadyEnabled),
+		errors.Is(err, services.ErrMFANotSetup),
+		errors.Is(err, services.ErrInvalidMFACode),
+		errors.Is(err, services.ErrMFARequiredByRole):
+		response.BadRequest(c, err.Error())
+	case errors.Is(err, services.ErrMFAKeyMissing):
+		response.Error(c, 503, err.Error())
+	default:
+		handleUserError(c, err, fallback)
+	}
+}
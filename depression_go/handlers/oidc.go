@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"errors"
+
+	"depression_go/inits"
+	"depression_go/internal/models"
+	"depression_go/pkg/response"
+	"depression_go/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OIDCHandler 单点登录处理器
+type OIDCHandler struct {
+	oidcService    *services.OIDCService
+	mfaService     *services.MFAService
+	tokenService   *services.TokenService
+	lockoutService *services.LockoutService
+}
+
+// NewOIDCHandler 创建单点登录处理器
+func NewOIDCHandler() *OIDCHandler {
+	return &OIDCHandler{
+		oidcService:    services.NewOIDCService(inits.DB),
+		mfaService:     services.NewMFAService(inits.DB),
+		tokenService:   services.NewTokenService(inits.DB),
+		lockoutService: services.NewLockoutService(inits.DB),
+	}
+}
+
+// ListProviders 可用的单点登录提供方，登录页据此显示按钮
+func (h *OIDCHandler) ListProviders(c *gin.Context) {
+	response.Success(c, h.oidcService.Providers())
+}
+
+// Authorize 发起单点登录，返回提供方的授权地址，前端跳转过去；reauth=true时要求提供方重新认证（如申请注销前）
+func (h *OIDCHandler) Authorize(c *gin.Context) {
+	result, err := h.oidcService.Begin(c.Request.Context(), c.Param("provider"), c.Query("reauth") == "true")
+	if err != nil {
+		handleOIDCError(c, err, "发起单点登录失败")
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// Callback 提供方回调前端后，前端提交code和state；校验通过后与密码登录相同，需要两步验证时先返回两步验证令牌
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	var req models.OIDCCallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	provider := c.Param("provider")
+	client := clientInfo(c)
+	login, err := h.oidcService.Complete(c.Request.Context(), provider, req.Code, req.State)
+	if err != nil {
+		handleOIDCError(c, err, "单点登录失败")
+		return
+	}
+
+	user := login.User
+	attempt := models.LoginAttempt{
+		Identifier: "oidc:" + provider + ":" + login.Email,
+		UserID:     user.ID,
+		IP:         client.IP,
+		UserAgent:  client.UserAgent,
+	}
+	if user.Status == 0 {
+		attempt.Reason = models.LoginResultDisabled
+		h.lockoutService.LogAttempt(attempt)
+		response.Forbidden(c, "账户已被禁用")
+		return
+	}
+
+	// 单点登录不校验本地密码，两步验证的要求不变
+	challenge, err := h.mfaService.Challenge(user)
+	if err != nil {
+		response.InternalServerError(c, "登录失败")
+		return
+	}
+	if challenge != nil {
+		attempt.Reason = models.LoginResultMFAPending
+		h.lockoutService.LogAttempt(attempt)
+		message := "请输入两步验证码"
+		if challenge.EnrollmentRequired {
+			message = "所属角色要求两步验证，请先完成绑定"
+		}
+		response.SuccessWithMessage(c, message, challenge)
+		return
+	}
+
+	attempt.Success = true
+	attempt.Reason = models.LoginResultSuccess
+	h.lockoutService.LogAttempt(attempt)
+
+	pair, err := h.tokenService.Issue(user, client)
+	if err != nil {
+		response.InternalServerError(c, "令牌生成失败")
+		return
+	}
+
+	message := "登录成功"
+	if login.Created {
+		message = "账户已创建，登录成功"
+	} else if login.Linked {
+		message = "已关联现有账户，登录成功"
+	}
+	userResponse := toUserResponse(*user)
+	response.SuccessWithMessage(c, message, models.AuthResponse{User: &userResponse, TokenPair: *pair})
+}
+
+// handleOIDCError 将单点登录的错误转换为响应
+func handleOIDCError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrOIDCProviderNotFound):
+		response.NotFound(c, err.Error())
+	case errors.Is(err, services.ErrOIDCInvalidState),
+		errors.Is(err, services.ErrOIDCEmailRequired):
+		response.BadRequest(c, err.Error())
+	case errors.Is(err, services.ErrOIDCLoginFailed):
+		response.Unauthorized(c, err.Error())
+	case errors.Is(err, services.ErrOIDCEmailNotVerified),
+		errors.Is(err, services.ErrOIDCLocalUnverified),
+		errors.Is(err, services.ErrOIDCEmailNotAllowed),
+		errors.Is(err, services.ErrOIDCAccountNotFound):
+		response.Forbidden(c, err.Error())
+	case errors.Is(err, services.ErrOIDCUnavailable):
+		response.Error(c, 503, err.Error())
+	default:
+		response.InternalServerError(c, fallback)
+	}
+}
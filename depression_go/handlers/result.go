@@ -1,24 +1,33 @@
 package handlers
 
 import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
 	"depression_go/inits"
 	"depression_go/internal/models"
 	"depression_go/middleware"
 	"depression_go/pkg/response"
+	"depression_go/services"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
 // ResultHandler 评估结果处理器
 type ResultHandler struct {
-	db *gorm.DB
+	assessmentService   *services.AssessmentService
+	fusionService       *services.FusionService
+	localizationService *services.LocalizationService
 }
 
 // NewResultHandler 创建评估结果处理器
 func NewResultHandler() *ResultHandler {
 	return &ResultHandler{
-		db: inits.DB,
+		assessmentService:   services.NewAssessmentService(inits.DB),
+		fusionService:       services.NewFusionService(inits.DB),
+		localizationService: services.NewLocalizationService(inits.DB),
 	}
 }
 
@@ -34,102 +43,212 @@ func (h *ResultHandler) CreateAssessment(c *gin.Context) {
 		return
 	}
 
-	// 创建评估
-	assessment := models.Assessment{
-		UserID: userID,
-		Title:  req.Title,
-		Type:   req.Type,
-		Status: 0, // 进行中
+	// 创建评估草稿，后续可逐题保存并在任意设备上继续
+	assessment, err := h.assessmentService.CreateDraft(userID, req)
+	if err != nil {
+		handleAssessmentError(c, err, "创建评估失败")
+		return
+	}
+
+	response.SuccessWithMessage(c, "评估创建成功", toAssessmentResponse(*assessment))
+}
+
+// GetHistory 分页获取评估历史，可按类型、等级和日期筛选
+func (h *ResultHandler) GetHistory(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	// 获取分页参数
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	// 日期按服务器时区解析，to当天也包含在内
+	filter := services.HistoryFilter{
+		Type:  c.Query("type"),
+		Level: c.Query("level"),
+	}
+	if value := c.Query("from"); value != "" {
+		from, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			response.BadRequest(c, "from格式错误，应为YYYY-MM-DD")
+			return
+		}
+		filter.From = &from
+	}
+	if value := c.Query("to"); value != "" {
+		to, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			response.BadRequest(c, "to格式错误，应为YYYY-MM-DD")
+			return
+		}
+		to = to.AddDate(0, 0, 1)
+		filter.To = &to
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		response.BadRequest(c, "from不能晚于to")
+		return
 	}
 
-	if err := h.db.Create(&assessment).Error; err != nil {
-		response.InternalServerError(c, "创建评估失败")
+	assessments, total, err := h.assessmentService.History(userID, filter, page, pageSize)
+	if err != nil {
+		response.InternalServerError(c, "查询失败")
 		return
 	}
 
-	response.SuccessWithMessage(c, "评估创建成功", models.AssessmentResponse{
-		ID:         assessment.ID,
-		UserID:     assessment.UserID,
-		Title:      assessment.Title,
-		Type:       assessment.Type,
-		TotalScore: assessment.TotalScore,
-		MaxScore:   assessment.MaxScore,
-		Level:      assessment.Level,
-		Result:     assessment.Result,
-		Status:     assessment.Status,
-		CreatedAt:  assessment.CreatedAt,
-		UpdatedAt:  assessment.UpdatedAt,
-	})
+	locale := middleware.GetLocale(c)
+	responses := make([]models.AssessmentResponse, 0, len(assessments))
+	for _, assessment := range assessments {
+		item := toAssessmentResponse(assessment)
+		item.Result = h.localizedResult(locale, assessment)
+		responses = append(responses, item)
+	}
+
+	response.SuccessWithPage(c, responses, total, page, pageSize)
 }
 
-// GetCombinedResult 获取综合评估结果（结合问卷和人脸检测）
-func (h *ResultHandler) GetCombinedResult(c *gin.Context) {
+// GetHistoryDetail 获取评估详情，答案按作答时的问卷版本渲染题目
+func (h *ResultHandler) GetHistoryDetail(c *gin.Context) {
 	userID := middleware.GetUserID(c)
 
-	// 获取最近的问卷评估
-	var questionnaireAssessment models.Assessment
-	if err := h.db.Where("user_id = ?", userID).
-		Order("created_at DESC").
-		First(&questionnaireAssessment).Error; err != nil {
-		response.NotFound(c, "未找到评估记录记录")
+	assessmentID, ok := parseIDParam(c, "id", "无效的评估ID")
+	if !ok {
 		return
 	}
 
-	// 获取最近的人脸检测
-	var faceDetection models.FaceDetection
-	if err := h.db.Where("user_id = ?", userID).
-		Order("created_at DESC").
-		First(&faceDetection).Error; err != nil {
-		response.NotFound(c, "未找到人脸检测记录")
+	// 只能查看自己的评估，其他用户的评估按不存在处理
+	assessment, err := h.assessmentService.GetOwned(userID, assessmentID)
+	if err != nil {
+		handleAssessmentError(c, err, "查询失败")
 		return
 	}
 
-	// 计算综合得分
-	questionnaireScore := questionnaireAssessment.TotalScore
-	faceScore := faceDetection.Score
-
-	// 权重分配：问卷70%，人脸检测30%
-	combinedScore := int(float64(questionnaireScore)*0.7 + float64(faceScore)*0.3)
-
-	// 计算综合等级
-	var combinedLevel string
-	var description string
-	var suggestions string
-
-	if combinedScore >= 80 {
-		combinedLevel = "severe"
-		description = "综合评估显示您的抑郁倾向较为严重，建议立即寻求专业帮助。"
-		suggestions = "1. 立即联系专业心理咨询师或精神科医生\n2. 保持规律的作息时间\n3. 多与家人朋友交流\n4. 避免独处时间过长\n5. 考虑药物治疗"
-	} else if combinedScore >= 60 {
-		combinedLevel = "moderate"
-		description = "综合评估显示您存在中等程度的抑郁倾向，建议适当调节并考虑寻求专业帮助。"
-		suggestions = "1. 考虑寻求心理咨询师的帮助\n2. 增加户外活动和运动\n3. 培养兴趣爱好\n4. 保持社交活动\n5. 学习放松技巧"
-	} else if combinedScore >= 40 {
-		combinedLevel = "mild"
-		description = "综合评估显示您存在轻微的抑郁倾向，属于正常范围，建议适当调节。"
-		suggestions = "1. 多进行户外活动\n2. 保持规律作息\n3. 与朋友多交流\n4. 培养积极心态\n5. 学习压力管理"
-	} else {
-		combinedLevel = "normal"
-		description = "综合评估显示您的心理状态良好，继续保持积极的生活态度。"
-		suggestions = "1. 继续保持良好的生活习惯\n2. 定期进行心理健康检查\n3. 帮助身边的人保持心理健康\n4. 培养兴趣爱好"
+	locale := middleware.GetLocale(c)
+	answers, err := h.assessmentService.GetAnswers(assessment.ID)
+	if err != nil {
+		response.InternalServerError(c, "查询失败")
+		return
+	}
+	if err := h.localizationService.LocalizeAnswers(locale, answers); err != nil {
+		response.InternalServerError(c, "查询失败")
+		return
 	}
 
+	var qualityReasons []string
+	if assessment.QualityReasons != "" {
+		_ = json.Unmarshal([]byte(assessment.QualityReasons), &qualityReasons)
+	}
+
+	response.Success(c, models.AssessmentWithAnswers{
+		ID:                assessment.ID,
+		UserID:            assessment.UserID,
+		Title:             assessment.Title,
+		Type:              assessment.Type,
+		TotalScore:        assessment.TotalScore,
+		MaxScore:          assessment.MaxScore,
+		Level:             assessment.Level,
+		Result:            h.localizedResult(locale, *assessment),
+		Status:            assessment.Status,
+		Mode:              assessment.Mode,
+		RiskFlagged:       assessment.RiskFlagged,
+		QualityFlag:       assessment.QualityFlag,
+		QualityConfidence: assessment.QualityConfidence,
+		QualityReasons:    qualityReasons,
+		CompletedAt:       assessment.CompletedAt,
+		CreatedAt:         assessment.CreatedAt,
+		UpdatedAt:         assessment.UpdatedAt,
+		Answers:           answers,
+	})
+}
+
+// localizedResult 按请求语言给出评估结果描述，未计分的评估（草稿、每日打卡）保留原文
+func (h *ResultHandler) localizedResult(locale string, assessment models.Assessment) string {
+	if assessment.Level == "" {
+		return assessment.Result
+	}
+	if assessment.Type == models.AssessmentTypeCombined {
+		description, _ := h.localizationService.CombinedText(locale, assessment.Level)
+		return description
+	}
+	result := models.AssessmentResult{
+		Level:            assessment.Level,
+		Description:      assessment.Result,
+		ScoringProfileID: assessment.ScoringProfileID,
+	}
+	h.localizationService.LocalizeResult(locale, &result)
+	return result.Description
+}
+
+// GetCombinedResult 预览综合评估结果（融合问卷、人脸检测和近期心情日记），不保存
+func (h *ResultHandler) GetCombinedResult(c *gin.Context) {
+	h.combinedResult(c, h.fusionService.Preview)
+}
+
+// SaveCombinedResult 计算综合评估结果并保存为综合评估记录
+func (h *ResultHandler) SaveCombinedResult(c *gin.Context) {
+	h.combinedResult(c, h.fusionService.Combine)
+}
+
+// combinedResult 融合并返回综合评估结果，fuse决定是否保存
+func (h *ResultHandler) combinedResult(c *gin.Context, fuse func(userID uint, includeLowQuality bool) (*services.FusionOutcome, error)) {
+	userID := middleware.GetUserID(c)
+
+	// 默认跳过疑似随意作答的低质量问卷评估
+	includeLowQuality := c.Query("include_low_quality") == "1" || c.Query("include_low_quality") == "true"
+	outcome, err := fuse(userID, includeLowQuality)
+	if err != nil {
+		if errors.Is(err, services.ErrNoFusionInputs) {
+			response.NotFound(c, err.Error())
+			return
+		}
+		response.InternalServerError(c, "计算综合评估失败")
+		return
+	}
+
+	combined := outcome.Assessment
+	description, suggestions := h.localizationService.CombinedText(middleware.GetLocale(c), combined.Level)
 	result := gin.H{
-		"combined_score": combinedScore,
-		"combined_level": combinedLevel,
+		"saved":          outcome.Saved,
+		"combined_score": combined.TotalScore,
+		"combined_level": combined.Level,
+		"confidence":     combined.Confidence,
 		"description":    description,
 		"suggestions":    suggestions,
-		"questionnaire": gin.H{
-			"score": questionnaireAssessment.TotalScore,
-			"level": questionnaireAssessment.Level,
-		},
-		"face_detection": gin.H{
-			"score":   faceDetection.Score,
-			"level":   faceDetection.Level,
-			"emotion": faceDetection.Emotion,
-		},
-		"assessment_date": questionnaireAssessment.CreatedAt,
-		"detection_date":  faceDetection.CreatedAt,
+		"inputs":         outcome.Inputs,
+	}
+	if outcome.Saved {
+		result["assessment_id"] = combined.ID
+	}
+	if assessment := outcome.Questionnaire; assessment != nil {
+		result["questionnaire"] = gin.H{
+			"assessment_id": assessment.ID,
+			"score":         assessment.TotalScore,
+			"max_score":     assessment.MaxScore,
+			"level":         assessment.Level,
+			"quality_flag":  assessment.QualityFlag,
+		}
+		result["assessment_date"] = assessment.CreatedAt
+	}
+	if detection := outcome.Face; detection != nil {
+		result["face_detection"] = gin.H{
+			"score":   detection.Score,
+			"level":   detection.Level,
+			"emotion": detection.Emotion,
+		}
+		result["detection_date"] = detection.CreatedAt
+	}
+	if signal := outcome.Journal; signal != nil {
+		result["journal"] = gin.H{
+			"score":   signal.Score,
+			"level":   signal.Level,
+			"entries": signal.Entries,
+			"since":   signal.Since,
+		}
 	}
 
 	response.Success(c, result)
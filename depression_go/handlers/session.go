@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"errors"
+
+	"depression_go/inits"
+	"depression_go/middleware"
+	"depression_go/pkg/response"
+	"depression_go/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SessionHandler 登录会话管理处理器
+type SessionHandler struct {
+	sessionService *services.SessionService
+}
+
+// NewSessionHandler 创建登录会话管理处理器
+func NewSessionHandler() *SessionHandler {
+	return &SessionHandler{
+		sessionService: services.NewSessionService(inits.DB),
+	}
+}
+
+// ListSessions 列出当前用户已登录的设备
+func (h *SessionHandler) ListSessions(c *gin.Context) {
+	sessions, err := h.sessionService.List(middleware.GetUserID(c), middleware.GetSessionID(c))
+	if err != nil {
+		response.InternalServerError(c, "查询失败")
+		return
+	}
+
+	response.Success(c, sessions)
+}
+
+// RevokeSession 移除一个会话，该设备需要重新登录；移除当前会话等同于注销
+func (h *SessionHandler) RevokeSession(c *gin.Context) {
+	if err := h.sessionService.Revoke(middleware.GetUserID(c), c.Param("id")); err != nil {
+		if errors.Is(err, services.ErrSessionNotFound) {
+			response.NotFound(c, err.Error())
+			return
+		}
+		response.InternalServerError(c, "移除会话失败")
+		return
+	}
+
+	response.SuccessWithMessage(c, "会话已移除", nil)
+}
+
+// RevokeOtherSessions 移除除当前会话以外的所有会话
+func (h *SessionHandler) RevokeOtherSessions(c *gin.Context) {
+	count, err := h.sessionService.RevokeOthers(middleware.GetUserID(c), middleware.GetSessionID(c))
+	if err != nil {
+		response.InternalServerError(c, "移除会话失败")
+		return
+	}
+
+	response.SuccessWithMessage(c, "其他设备已退出登录", gin.H{"revoked": count})
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"depression_go/inits"
+	"depression_go/middleware"
+	"depression_go/pkg/response"
+	"depression_go/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TrendHandler 纵向趋势处理器
+type TrendHandler struct {
+	trendService *services.TrendService
+}
+
+// NewTrendHandler 创建纵向趋势处理器
+func NewTrendHandler() *TrendHandler {
+	return &TrendHandler{
+		trendService: services.NewTrendService(inits.DB),
+	}
+}
+
+// GetTrends 获取当前用户的纵向趋势
+func (h *TrendHandler) GetTrends(c *gin.Context) {
+	h.respondTrends(c, middleware.GetUserID(c))
+}
+
+// GetUserTrends 管理员（咨询师）查看指定用户的纵向趋势
+func (h *TrendHandler) GetUserTrends(c *gin.Context) {
+	userID, ok := parseIDParam(c, "id", "无效的用户ID")
+	if !ok {
+		return
+	}
+	h.respondTrends(c, userID)
+}
+
+// CompareAssessments 计算两次评估之间的可靠变化
+func (h *TrendHandler) CompareAssessments(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	fromID, err := strconv.ParseUint(c.Query("from"), 10, 32)
+	if err != nil || fromID == 0 {
+		response.BadRequest(c, "无效的起始评估ID")
+		return
+	}
+	toID, err := strconv.ParseUint(c.Query("to"), 10, 32)
+	if err != nil || toID == 0 {
+		response.BadRequest(c, "无效的结束评估ID")
+		return
+	}
+
+	change, params, err := h.trendService.Compare(userID, uint(fromID), uint(toID))
+	if err != nil {
+		if errors.Is(err, services.ErrInstrumentMismatch) {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		handleAssessmentError(c, err, "查询失败")
+		return
+	}
+
+	response.Success(c, gin.H{
+		"params": params,
+		"change": change,
+	})
+}
+
+// respondTrends 返回用户的纵向趋势，include_low_quality为1时包含低质量评估
+func (h *TrendHandler) respondTrends(c *gin.Context, userID uint) {
+	includeLowQuality := c.Query("include_low_quality") == "1" || c.Query("include_low_quality") == "true"
+
+	trends, err := h.trendService.Trends(userID, includeLowQuality)
+	if err != nil {
+		response.InternalServerError(c, "查询失败")
+		return
+	}
+
+	response.Success(c, trends)
+}
@@ -67,11 +67,80 @@ func AutoMigrate() {
 		&models.Assessment{},
 		&models.Answer{},
 		&models.FaceDetection{},
+		&models.RiskEvent{},
+		&models.AuditLog{},
+		&models.QuestionRevision{},
+		&models.QuestionnaireVersion{},
+		&models.QuestionnaireVersionItem{},
+		&models.QuestionTranslation{},
+		&models.ResultTextTranslation{},
+		&models.ScoringProfile{},
+		&models.JournalEntry{},
+		&models.RefreshToken{},
+		&models.UserSession{},
+		&models.Role{},
+		&models.Permission{},
+		&models.LoginAttempt{},
+		&models.LoginLockout{},
+		&models.UserToken{},
+		&models.UserMFA{},
+		&models.MFARecoveryCode{},
+		&models.UserIdentity{},
+		&models.OIDCState{},
+		&models.AccountDeletion{},
+		&models.ErasedDataStat{},
+		&models.PublishLock{},
 	)
 
 	if err != nil {
 		log.Fatalf("数据库迁移失败: %v", err)
 	}
+
+	// AutoMigrate不会把NOT NULL的列改为可空：日记触发的风险事件没有评估和问题
+	if err := relaxNotNull(&models.RiskEvent{}, "AssessmentID", "QuestionID"); err != nil {
+		log.Fatalf("数据库迁移失败: %v", err)
+	}
+
+	// 问题翻译改为按修订保存：删除旧的按问题唯一的索引，旧翻译归到问题当前修订
+	if DB.Migrator().HasIndex(&models.QuestionTranslation{}, "idx_question_locale") {
+		if err := DB.Migrator().DropIndex(&models.QuestionTranslation{}, "idx_question_locale"); err != nil {
+			log.Fatalf("数据库迁移失败: %v", err)
+		}
+	}
+	if err := DB.Exec("UPDATE question_translations t JOIN questions q ON q.id = t.question_id " +
+		"SET t.question_revision_id = q.current_revision_id " +
+		"WHERE t.question_revision_id = 0 AND q.current_revision_id <> 0").Error; err != nil {
+		log.Fatalf("数据库迁移失败: %v", err)
+	}
+}
+
+// relaxNotNull 将已有表中仍为NOT NULL的列改为可空
+func relaxNotNull(model interface{}, fields ...string) error {
+	columnTypes, err := DB.Migrator().ColumnTypes(model)
+	if err != nil {
+		return err
+	}
+	stmt := &gorm.Statement{DB: DB}
+	if err := stmt.Parse(model); err != nil {
+		return err
+	}
+	for _, name := range fields {
+		field := stmt.Schema.LookUpField(name)
+		if field == nil {
+			continue
+		}
+		for _, columnType := range columnTypes {
+			if columnType.Name() != field.DBName {
+				continue
+			}
+			if nullable, ok := columnType.Nullable(); ok && !nullable {
+				if err := DB.Migrator().AlterColumn(model, name); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
 }
 
 // CloseDatabase 关闭数据库连接
@@ -5,6 +5,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -16,8 +17,9 @@ func InitConfig() {
 	}
 
 	dbPort, _ := strconv.Atoi(os.Getenv("DB_PORT"))
-	jwtExpire, _ := strconv.Atoi(os.Getenv("JWT_EXPIRE_HOURS"))
 	maxFileSize, _ := strconv.ParseInt(os.Getenv("MAX_FILE_SIZE"), 10, 64)
+	draftTTL := getEnvInt("ASSESSMENT_DRAFT_TTL_HOURS", 72)
+	expiryInterval := getEnvInt("ASSESSMENT_EXPIRY_CHECK_MINUTES", 30)
 
 	configs.GlobalConfig = &configs.Config{
 		Database: configs.DatabaseConfig{
@@ -28,8 +30,9 @@ func InitConfig() {
 			DBName:   os.Getenv("DB_NAME"),
 		},
 		JWT: configs.JWTConfig{
-			Secret:      os.Getenv("JWT_SECRET"),
-			ExpireHours: jwtExpire,
+			Secret:           os.Getenv("JWT_SECRET"),
+			AccessTTLMinutes: getEnvInt("JWT_ACCESS_TTL_MINUTES", 15),
+			RefreshTTLHours:  getEnvInt("JWT_REFRESH_TTL_HOURS", 720),
 		},
 		BaiduAI: configs.BaiduAIConfig{
 			AppID:     os.Getenv("BAIDU_APP_ID"),
@@ -37,12 +40,231 @@ func InitConfig() {
 			SecretKey: os.Getenv("BAIDU_SECRET_KEY"),
 		},
 		Server: configs.ServerConfig{
-			Port: os.Getenv("SERVER_PORT"),
-			Mode: os.Getenv("SERVER_MODE"),
+			Port:           os.Getenv("SERVER_PORT"),
+			Mode:           os.Getenv("SERVER_MODE"),
+			TrustedProxies: splitList(os.Getenv("TRUSTED_PROXIES"), ","),
 		},
 		Upload: configs.UploadConfig{
 			Path:        os.Getenv("UPLOAD_PATH"),
 			MaxFileSize: maxFileSize,
 		},
+		Assessment: configs.AssessmentConfig{
+			DraftTTLHours:       draftTTL,
+			ExpiryCheckInterval: expiryInterval,
+			CATTargetSE:         getEnvFloat("CAT_TARGET_SE", 0.3),
+			CATMinItems:         getEnvInt("CAT_MIN_ITEMS", 3),
+			CATMaxItems:         getEnvInt("CAT_MAX_ITEMS", 12),
+		},
+		Crisis: configs.CrisisConfig{
+			Notifier:   os.Getenv("CRISIS_NOTIFIER"),
+			WebhookURL: os.Getenv("CRISIS_WEBHOOK_URL"),
+			Responders: parseCrisisResponders(os.Getenv("CRISIS_RESPONDERS")),
+			Resources:  parseCrisisResources(os.Getenv("CRISIS_RESOURCES")),
+		},
+		Quality: configs.QualityConfig{
+			MinMsPerItem:         getEnvInt("QUALITY_MIN_MS_PER_ITEM", 1000),
+			StraightLineMinItems: getEnvInt("QUALITY_STRAIGHTLINE_MIN_ITEMS", 5),
+			ConsistencyTolerance: getEnvInt("QUALITY_CONSISTENCY_TOLERANCE", 1),
+		},
+		Journal: configs.JournalConfig{
+			Analyzer:   os.Getenv("SENTIMENT_ANALYZER"),
+			MaxLength:  getEnvInt("JOURNAL_MAX_LENGTH", 2000),
+			WindowDays: getEnvInt("JOURNAL_WINDOW_DAYS", 7),
+		},
+		Checkin: configs.CheckinConfig{
+			MaxPerDay: getEnvInt("CHECKIN_MAX_PER_DAY", 5),
+		},
+		Trend: configs.TrendConfig{
+			DeteriorationWindow: getEnvInt("TREND_DETERIORATION_WINDOW", 3),
+		},
+		Fusion: configs.FusionConfig{
+			Modalities:  splitList(os.Getenv("FUSION_MODALITIES"), ","),
+			Weights:     parseFusionWeights(os.Getenv("FUSION_WEIGHTS")),
+			MaxGapHours: getEnvInt("FUSION_MAX_GAP_HOURS", 72),
+		},
+		Lockout: configs.LockoutConfig{
+			Store:              os.Getenv("LOCKOUT_STORE"),
+			RedisAddr:          os.Getenv("LOCKOUT_REDIS_ADDR"),
+			RedisPassword:      os.Getenv("LOCKOUT_REDIS_PASSWORD"),
+			RedisDB:            getEnvInt("LOCKOUT_REDIS_DB", 0),
+			MaxAccountFailures: getEnvInt("LOCKOUT_MAX_ACCOUNT_FAILURES", 5),
+			MaxIPFailures:      getEnvInt("LOCKOUT_MAX_IP_FAILURES", 20),
+			BaseLockSeconds:    getEnvInt("LOCKOUT_BASE_SECONDS", 60),
+			MaxLockMinutes:     getEnvInt("LOCKOUT_MAX_MINUTES", 60),
+			WindowMinutes:      getEnvInt("LOCKOUT_WINDOW_MINUTES", 15),
+		},
+		Mail: configs.MailConfig{
+			Driver:          os.Getenv("MAIL_DRIVER"),
+			From:            getEnvString("MAIL_FROM", "noreply@localhost"),
+			Dir:             getEnvString("MAIL_DIR", "./mails"),
+			SMTPHost:        os.Getenv("SMTP_HOST"),
+			SMTPPort:        getEnvInt("SMTP_PORT", 587),
+			SMTPUsername:    os.Getenv("SMTP_USERNAME"),
+			SMTPPassword:    os.Getenv("SMTP_PASSWORD"),
+			SMTPSecurity:    os.Getenv("SMTP_SECURITY"),
+			AppName:         getEnvString("APP_NAME", "抑郁倾向检测系统"),
+			LinkBaseURL:     getEnvString("MAIL_LINK_BASE_URL", "http://localhost:8088"),
+			VerifyTTLHours:  getEnvInt("MAIL_VERIFY_TTL_HOURS", 24),
+			ResetTTLMinutes: getEnvInt("MAIL_RESET_TTL_MINUTES", 30),
+		},
+		MFA: configs.MFAConfig{
+			Issuer:            getEnvString("MFA_ISSUER", getEnvString("APP_NAME", "抑郁倾向检测系统")),
+			EncryptionKey:     os.Getenv("MFA_ENCRYPTION_KEY"),
+			PendingTTLMinutes: getEnvInt("MFA_PENDING_TTL_MINUTES", 5),
+		},
+		OIDC: configs.OIDCConfig{
+			Providers: parseOIDCProviders(os.Getenv("OIDC_PROVIDERS")),
+		},
+		Password: configs.PasswordConfig{
+			MinLength:           getEnvInt("PASSWORD_MIN_LENGTH", 8),
+			MaxLength:           getEnvInt("PASSWORD_MAX_LENGTH", 128),
+			MinClasses:          getEnvInt("PASSWORD_MIN_CLASSES", 2),
+			RejectUserInfo:      getEnvBool("PASSWORD_REJECT_USER_INFO", true),
+			Blocklist:           getEnvBool("PASSWORD_BLOCKLIST", true),
+			BlocklistFile:       os.Getenv("PASSWORD_BLOCKLIST_FILE"),
+			Argon2MemoryKB:      getEnvInt("ARGON2_MEMORY_KB", 64*1024),
+			Argon2Iterations:    getEnvInt("ARGON2_ITERATIONS", 3),
+			Argon2Parallelism:   getEnvInt("ARGON2_PARALLELISM", 2),
+			Argon2MaxConcurrent: getEnvInt("ARGON2_MAX_CONCURRENT", 4),
+		},
+		Deletion: configs.DeletionConfig{
+			GraceDays: getEnvInt("ACCOUNT_DELETION_GRACE_DAYS", 14),
+		},
+	}
+
+	// 未配置TOTP密钥加密口令时照常启动，但绑定和校验两步验证都会失败
+	if configs.GlobalConfig.MFA.EncryptionKey == "" {
+		log.Printf("警告: 未配置MFA_ENCRYPTION_KEY，两步验证不可用，已启用两步验证或所属角色要求两步验证的用户将无法登录（此前未配置时使用的是JWT_SECRET，沿用该值可保留已绑定的两步验证）")
+	}
+}
+
+// parseFusionWeights 解析综合评估权重配置，格式：模态:权重,模态:权重
+func parseFusionWeights(value string) map[string]float64 {
+	weights := map[string]float64{}
+	for _, item := range splitList(value, ",") {
+		fields := strings.Split(item, ":")
+		if len(fields) != 2 {
+			log.Printf("忽略格式错误的综合评估权重配置: %s", item)
+			continue
+		}
+		weight, err := strconv.ParseFloat(strings.TrimSpace(fields[1]), 64)
+		if err != nil || weight < 0 {
+			log.Printf("忽略格式错误的综合评估权重配置: %s", item)
+			continue
+		}
+		weights[strings.TrimSpace(fields[0])] = weight
+	}
+	return weights
+}
+
+// parseOIDCProviders 按OIDC_PROVIDERS中的名称读取各提供方的配置，如名称campus对应OIDC_CAMPUS_ISSUER等
+func parseOIDCProviders(value string) []configs.OIDCProviderConfig {
+	var providers []configs.OIDCProviderConfig
+	for _, name := range splitList(value, ",") {
+		name = strings.ToLower(name)
+		prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		provider := configs.OIDCProviderConfig{
+			Name:           name,
+			DisplayName:    getEnvString(prefix+"DISPLAY_NAME", name),
+			Issuer:         strings.TrimSuffix(os.Getenv(prefix+"ISSUER"), "/"),
+			ClientID:       os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret:   os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectURL:    os.Getenv(prefix + "REDIRECT_URL"),
+			Scopes:         splitList(os.Getenv(prefix+"SCOPES"), " "),
+			AutoCreate:     getEnvBool(prefix+"AUTO_CREATE", true),
+			LinkByEmail:    getEnvBool(prefix+"LINK_BY_EMAIL", true),
+			AllowedDomains: splitList(strings.ToLower(os.Getenv(prefix+"ALLOWED_DOMAINS")), ","),
+		}
+		if provider.Issuer == "" || provider.ClientID == "" || provider.RedirectURL == "" {
+			log.Printf("忽略配置不完整的OIDC提供方: %s（需要%sISSUER、%sCLIENT_ID和%sREDIRECT_URL）", name, prefix, prefix, prefix)
+			continue
+		}
+		providers = append(providers, provider)
+	}
+	return providers
+}
+
+// parseCrisisResponders 解析响应人员配置，格式：角色|姓名|联系方式;角色|姓名|联系方式
+func parseCrisisResponders(value string) []configs.CrisisResponder {
+	var responders []configs.CrisisResponder
+	for _, item := range splitList(value, ";") {
+		fields := strings.Split(item, "|")
+		if len(fields) != 3 {
+			log.Printf("忽略格式错误的危机响应人员配置: %s", item)
+			continue
+		}
+		responders = append(responders, configs.CrisisResponder{
+			Role:    strings.TrimSpace(fields[0]),
+			Name:    strings.TrimSpace(fields[1]),
+			Contact: strings.TrimSpace(fields[2]),
+		})
+	}
+	return responders
+}
+
+// parseCrisisResources 解析求助资源配置，格式：名称|电话|说明;名称|电话|说明
+func parseCrisisResources(value string) []configs.CrisisResource {
+	var resources []configs.CrisisResource
+	for _, item := range splitList(value, ";") {
+		fields := strings.Split(item, "|")
+		if len(fields) < 2 {
+			log.Printf("忽略格式错误的求助资源配置: %s", item)
+			continue
+		}
+		resource := configs.CrisisResource{
+			Name:  strings.TrimSpace(fields[0]),
+			Phone: strings.TrimSpace(fields[1]),
+		}
+		if len(fields) > 2 {
+			resource.Description = strings.TrimSpace(fields[2])
+		}
+		resources = append(resources, resource)
+	}
+	return resources
+}
+
+// splitList 按分隔符拆分配置并去掉空项
+func splitList(value, sep string) []string {
+	var items []string
+	for _, item := range strings.Split(value, sep) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// getEnvString 读取字符串环境变量，未设置时使用默认值
+func getEnvString(key, defaultValue string) string {
+	if value := strings.TrimSpace(os.Getenv(key)); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvBool 读取布尔型环境变量，未设置或格式错误时使用默认值
+func getEnvBool(key string, defaultValue bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvInt 读取整型环境变量，未设置或格式错误时使用默认值
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvFloat 读取浮点型环境变量，未设置或格式错误时使用默认值
+func getEnvFloat(key string, defaultValue float64) float64 {
+	value, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil || value <= 0 {
+		return defaultValue
 	}
+	return value
 }
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 注销申请状态
+const (
+	DeletionStatusPending   = "pending"   // 等待期内，可以撤销
+	DeletionStatusCancelled = "cancelled" // 已撤销
+	DeletionStatusCompleted = "completed" // 数据已删除
+)
+
+// 注销申请来源
+const (
+	DeletionSourceSelf  = "self"  // 用户自助申请
+	DeletionSourceAdmin = "admin" // 管理员代为办理线下收到的删除请求
+)
+
+// AccountDeletion 账户注销申请，到期后删除账户及全部数据；完成后只保留本记录作为删除回执
+type AccountDeletion struct {
+	gorm.Model
+	UserID      uint       `json:"user_id" gorm:"not null;index"`
+	Source      string     `json:"source" gorm:"size:20;not null"`
+	RequestedBy uint       `json:"requested_by"`                         // 发起人，自助申请时为用户本人
+	Reason      string     `json:"reason" gorm:"size:500"`               // 管理员办理时填写的依据（如线下申请的编号）
+	Status      string     `json:"status" gorm:"size:20;not null;index"` // pending, cancelled, completed
+	ScheduledAt time.Time  `json:"scheduled_at" gorm:"index"`            // 计划删除时间，之前可以撤销
+	CancelledAt *time.Time `json:"cancelled_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+	ReceiptID   string     `json:"receipt_id" gorm:"size:36;not null;uniqueIndex"` // 删除回执编号，凭此查询回执
+	Summary     string     `json:"-" gorm:"type:text"`                             // 删除的数据条数（JSON，见DeletionSummary）
+	LastError   string     `json:"-" gorm:"type:text"`                             // 最近一次执行失败的原因，下次定时任务重试
+}
+
+// TableName 指定表名
+func (AccountDeletion) TableName() string {
+	return "account_deletions"
+}
+
+// ErasedDataStat 已删除账户数据的匿名汇总，只按月份和类别计数，不含任何用户标识
+type ErasedDataStat struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	Period    string    `json:"period" gorm:"size:7;not null;uniqueIndex:idx_erased_stat"`     // 数据产生的月份，如2024-01
+	Metric    string    `json:"metric" gorm:"size:30;not null;uniqueIndex:idx_erased_stat"`    // users, assessments, face_detections, journal_entries, risk_events
+	Dimension string    `json:"dimension" gorm:"size:50;not null;uniqueIndex:idx_erased_stat"` // 细分：用户为角色，评估为类型:等级，人脸检测为情绪，日记为情感倾向
+	Count     int64     `json:"count"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (ErasedDataStat) TableName() string {
+	return "erased_data_stats"
+}
+
+// DeletionSummary 删除回执中的数据条数
+type DeletionSummary struct {
+	Assessments    int64 `json:"assessments"` // 包括问卷评估、综合评估和每日打卡
+	Answers        int64 `json:"answers"`
+	FaceDetections int64 `json:"face_detections"`
+	JournalEntries int64 `json:"journal_entries"`
+	RiskEvents     int64 `json:"risk_events"`
+	Sessions       int64 `json:"sessions"`       // 登录会话和刷新令牌
+	LoginAttempts  int64 `json:"login_attempts"` // 登录记录
+	Files          int64 `json:"files"`          // 删除的图片文件（人脸检测图片、头像）
+	FilesMissing   int64 `json:"files_missing"`  // 已不存在或删除失败的文件
+}
+
+// AccountDeletionRequest 申请注销账户，需要当前密码或两步验证码；单点登录创建且未设置过密码的账户可以都不提供
+type AccountDeletionRequest struct {
+	Password string `json:"password"`
+	Code     string `json:"code"` // 身份验证器上的6位验证码
+}
+
+// AdminDeletionRequest 管理员代为办理删除请求
+type AdminDeletionRequest struct {
+	Reason    string `json:"reason" binding:"required,max=500"` // 删除依据，如线下申请的编号
+	Immediate bool   `json:"immediate"`                         // 立即删除，不设等待期
+}
+
+// DeletionReceiptResponse 注销申请及删除回执
+type DeletionReceiptResponse struct {
+	ReceiptID   string           `json:"receipt_id"`
+	Status      string           `json:"status"`
+	Source      string           `json:"source"`
+	RequestedAt time.Time        `json:"requested_at"`
+	ScheduledAt time.Time        `json:"scheduled_at"`
+	CancelledAt *time.Time       `json:"cancelled_at,omitempty"`
+	CompletedAt *time.Time       `json:"completed_at,omitempty"`
+	Deleted     *DeletionSummary `json:"deleted,omitempty"` // 完成后才有
+}
+
+// AdminDeletionResponse 管理员查看的注销申请
+type AdminDeletionResponse struct {
+	ID          uint   `json:"id"`
+	UserID      uint   `json:"user_id"`
+	RequestedBy uint   `json:"requested_by"`
+	Reason      string `json:"reason"`
+	LastError   string `json:"last_error,omitempty"`
+	DeletionReceiptResponse
+}
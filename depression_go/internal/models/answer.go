@@ -10,10 +10,15 @@ import (
 type Answer struct {
 	gorm.Model
 	UserID       uint   `json:"user_id" gorm:"not null"`
-	QuestionID   uint   `json:"question_id" gorm:"not null"`
-	AssessmentID uint   `json:"assessment_id" gorm:"not null"`
+	QuestionID   uint   `json:"question_id" gorm:"not null;index:idx_answer_assessment_question,priority:2"`
+	AssessmentID uint   `json:"assessment_id" gorm:"not null;index:idx_answer_assessment_question,priority:1"`
 	Content      string `json:"content" gorm:"type:text;not null"` // 答案内容
 	Score        int    `json:"score" gorm:"default:0"`            // 答案得分
+	AnswerValue  int    `json:"answer_value" gorm:"default:0"`     // 选项值，用于按原版本重新计分
+
+	QuestionRevisionID uint `json:"question_revision_id" gorm:"default:0"` // 作答时展示的问题修订
+	ResponseTimeMs     int  `json:"response_time_ms" gorm:"default:0"`     // 客户端记录的作答用时（毫秒），0表示未知
+	Position           int  `json:"position" gorm:"default:0"`             // 该题在本次评估中展示的位置（从1开始），0表示未记录
 
 	// 关联关系
 	User       User       `json:"user,omitempty" gorm:"foreignKey:UserID"`
@@ -39,32 +44,46 @@ type AnswerUpdateRequest struct {
 	Score   int    `json:"score"`
 }
 
+// AnswerRequest 按选项值提交答案的请求
 type AnswerRequest struct {
-	QuestionID  uint `json:"question_id"`
-	AnswerValue int  `json:"answer_value"`
+	QuestionID     uint `json:"question_id"`
+	AnswerValue    int  `json:"answer_value"`
+	ResponseTimeMs int  `json:"response_time_ms"` // 作答用时（毫秒），可选
+}
+
+// AnswerValueRequest 单题保存请求（问题ID取自路径）
+type AnswerValueRequest struct {
+	AnswerValue    int `json:"answer_value" binding:"required"`
+	ResponseTimeMs int `json:"response_time_ms"` // 作答用时（毫秒），可选
 }
 
 // AnswerResponse 答案响应
 type AnswerResponse struct {
-	ID           uint      `json:"id"`
-	UserID       uint      `json:"user_id"`
-	QuestionID   uint      `json:"question_id"`
-	AssessmentID uint      `json:"assessment_id"`
-	Content      string    `json:"content"`
-	Score        int       `json:"score"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID             uint      `json:"id"`
+	UserID         uint      `json:"user_id"`
+	QuestionID     uint      `json:"question_id"`
+	AssessmentID   uint      `json:"assessment_id"`
+	Content        string    `json:"content"`
+	Score          int       `json:"score"`
+	AnswerValue    int       `json:"answer_value"`
+	ResponseTimeMs int       `json:"response_time_ms"`
+	Position       int       `json:"position"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
 }
 
 // AnswerWithQuestion 包含问题信息的答案
 type AnswerWithQuestion struct {
-	ID           uint             `json:"id"`
-	UserID       uint             `json:"user_id"`
-	QuestionID   uint             `json:"question_id"`
-	AssessmentID uint             `json:"assessment_id"`
-	Content      string           `json:"content"`
-	Score        int              `json:"score"`
-	CreatedAt    time.Time        `json:"created_at"`
-	UpdatedAt    time.Time        `json:"updated_at"`
-	Question     QuestionResponse `json:"question"`
+	ID             uint                   `json:"id"`
+	UserID         uint                   `json:"user_id"`
+	QuestionID     uint                   `json:"question_id"`
+	AssessmentID   uint                   `json:"assessment_id"`
+	Content        string                 `json:"content"`
+	Score          int                    `json:"score"`
+	AnswerValue    int                    `json:"answer_value"`
+	ResponseTimeMs int                    `json:"response_time_ms"`
+	Position       int                    `json:"position"`
+	CreatedAt      time.Time              `json:"created_at"`
+	UpdatedAt      time.Time              `json:"updated_at"`
+	Question       PublicQuestionResponse `json:"question"`
 }
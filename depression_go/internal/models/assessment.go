@@ -11,12 +11,34 @@ type Assessment struct {
 	gorm.Model
 	UserID     uint   `json:"user_id" gorm:"not null"`
 	Title      string `json:"title" gorm:"size:200;not null"` // 评估标题
-	Type       string `json:"type" gorm:"size:50;not null"` // 评估类型：questionnaire(问卷), face(人脸), combined(综合)
-	TotalScore int    `json:"total_score" gorm:"default:0"` // 总分数
-	MaxScore   int    `json:"max_score" gorm:"default:0"` // 最高可能分数
-	Level      string `json:"level" gorm:"size:20"` // 评估等级：normal(正常), mild(轻度), moderate(中度), severe(重度)
-	Result     string `json:"result" gorm:"type:text"` // 评估结果描述
-	Status     int    `json:"status" gorm:"default:1"` // 1:完成 0:进行中
+	Type       string `json:"type" gorm:"size:50;not null"`   // 评估类型：questionnaire(问卷), face(人脸), combined(综合)
+	TotalScore int    `json:"total_score" gorm:"default:0"`   // 总分数
+	MaxScore   int    `json:"max_score" gorm:"default:0"`     // 最高可能分数
+	Level      string `json:"level" gorm:"size:20"`           // 评估等级：normal(正常), mild(轻度), moderate(中度), severe(重度)
+	Result     string `json:"result" gorm:"type:text"`        // 评估结果描述
+	Status     int    `json:"status" gorm:"default:0"`        // 0:进行中 1:完成 2:已过期
+
+	ExpiresAt   *time.Time `json:"expires_at"`                        // 草稿过期时间，每次保存答案后顺延
+	CompletedAt *time.Time `json:"completed_at"`                      // 完成时间
+	RiskFlagged bool       `json:"risk_flagged" gorm:"default:false"` // 是否有风险条目被触发
+
+	QuestionnaireVersionID uint `json:"questionnaire_version_id" gorm:"default:0"` // 作答时的问卷版本，0表示版本化之前的历史评估
+	ScoringProfileID       uint `json:"scoring_profile_id" gorm:"default:0"`       // 计分所用的计分方案，0表示计分方案之前的历史评估
+
+	Mode          string   `json:"mode" gorm:"size:20;default:'fixed'"` // 作答模式：fixed(完整问卷), adaptive(自适应测验)
+	ThetaEstimate *float64 `json:"theta_estimate"`                      // 自适应测验的严重程度估计（IRT θ）
+	ThetaSE       *float64 `json:"theta_se"`                            // θ估计的标准误
+
+	OrderMode     string `json:"order_mode" gorm:"size:20;default:'fixed'"` // 题目顺序：fixed(按题库顺序), random, block, counterbalanced
+	OrderSeed     int64  `json:"order_seed" gorm:"default:0"`               // 生成题目顺序的随机种子，相同种子可复现顺序
+	QuestionOrder string `json:"question_order" gorm:"type:text"`           // 本次评估展示的题目顺序（问题ID的JSON数组）
+
+	QualityFlag       string  `json:"quality_flag" gorm:"size:20"`         // 作答质量：ok(正常), low(疑似随意作答)，空表示未检查
+	QualityConfidence float64 `json:"quality_confidence" gorm:"default:0"` // 质量判定的置信度（0-1）
+	QualityReasons    string  `json:"quality_reasons" gorm:"size:255"`     // 触发的检查项（JSON数组）
+
+	Confidence   float64 `json:"confidence" gorm:"default:0"`    // 综合评估的置信度（0-1），缺少部分模态时降低
+	FusionInputs string  `json:"fusion_inputs" gorm:"type:text"` // 综合评估使用的各模态输入（JSON数组，见FusionInput）
 
 	// 关联关系
 	User    User     `json:"user,omitempty" gorm:"foreignKey:UserID"`
@@ -28,18 +50,47 @@ func (Assessment) TableName() string {
 	return "assessments"
 }
 
-// BeforeCreate 创建前的钩子函数
-func (a *Assessment) BeforeCreate(tx *gorm.DB) error {
-	if a.Status == 0 {
-		a.Status = 1
-	}
-	return nil
-}
+// 评估状态
+const (
+	AssessmentStatusInProgress = 0 // 进行中（草稿）
+	AssessmentStatusCompleted  = 1 // 已完成
+	AssessmentStatusExpired    = 2 // 已过期
+)
+
+// 评估类型
+const (
+	AssessmentTypeQuestionnaire = "questionnaire" // 问卷评估
+	AssessmentTypeCheckin       = "checkin"       // 每日打卡，不参与问卷计分
+	AssessmentTypeCombined      = "combined"      // 综合评估，由问卷、人脸检测等结果融合而成
+)
+
+// 作答质量标记
+const (
+	QualityFlagOK  = "ok"
+	QualityFlagLow = "low"
+)
+
+// 评估作答模式
+const (
+	AssessmentModeFixed    = "fixed"    // 按顺序作答全部题目
+	AssessmentModeAdaptive = "adaptive" // 服务端根据已作答情况选择下一题
+)
+
+// 题目顺序
+const (
+	QuestionOrderFixed           = "fixed"           // 按题库排序
+	QuestionOrderRandom          = "random"          // 全部题目随机排列
+	QuestionOrderBlock           = "block"           // 按分类分块，块的顺序不变，块内随机排列
+	QuestionOrderCounterbalanced = "counterbalanced" // 按分类分块，块的顺序按拉丁方轮换，块内随机排列
+)
 
 // AssessmentCreateRequest 创建评估请求
 type AssessmentCreateRequest struct {
-	Title string `json:"title" binding:"required"`
-	Type  string `json:"type" binding:"required"`
+	Title     string `json:"title" binding:"required"`
+	Type      string `json:"type" binding:"required"`
+	Mode      string `json:"mode"`       // fixed(默认) 或 adaptive
+	OrderMode string `json:"order_mode"` // 题目顺序，默认fixed
+	OrderSeed *int64 `json:"order_seed"` // 随机种子，不传时由服务端生成
 }
 
 // AssessmentUpdateRequest 更新评估请求
@@ -54,33 +105,61 @@ type AssessmentUpdateRequest struct {
 
 // AssessmentResponse 评估响应
 type AssessmentResponse struct {
-	ID         uint      `json:"id"`
-	UserID     uint      `json:"user_id"`
-	Title      string    `json:"title"`
-	Type       string    `json:"type"`
-	TotalScore int       `json:"total_score"`
-	MaxScore   int       `json:"max_score"`
-	Level      string    `json:"level"`
-	Result     string    `json:"result"`
-	Status     int       `json:"status"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	ID                     uint          `json:"id"`
+	UserID                 uint          `json:"user_id"`
+	Title                  string        `json:"title"`
+	Type                   string        `json:"type"`
+	TotalScore             int           `json:"total_score"`
+	MaxScore               int           `json:"max_score"`
+	Level                  string        `json:"level"`
+	Result                 string        `json:"result"`
+	Status                 int           `json:"status"`
+	RiskFlagged            bool          `json:"risk_flagged"`
+	QuestionnaireVersionID uint          `json:"questionnaire_version_id"`
+	ScoringProfileID       uint          `json:"scoring_profile_id,omitempty"`
+	Mode                   string        `json:"mode"`
+	ThetaEstimate          *float64      `json:"theta_estimate,omitempty"`
+	ThetaSE                *float64      `json:"theta_se,omitempty"`
+	OrderMode              string        `json:"order_mode"`
+	OrderSeed              int64         `json:"order_seed,omitempty"`
+	QuestionOrder          []uint        `json:"question_order,omitempty"`
+	QualityFlag            string        `json:"quality_flag,omitempty"`
+	QualityConfidence      float64       `json:"quality_confidence,omitempty"`
+	QualityReasons         []string      `json:"quality_reasons,omitempty"`
+	Confidence             float64       `json:"confidence,omitempty"`
+	FusionInputs           []FusionInput `json:"fusion_inputs,omitempty"`
+	ExpiresAt              *time.Time    `json:"expires_at,omitempty"`
+	CompletedAt            *time.Time    `json:"completed_at,omitempty"`
+	CreatedAt              time.Time     `json:"created_at"`
+	UpdatedAt              time.Time     `json:"updated_at"`
+}
+
+// AssessmentSessionResponse 评估会话（草稿）详情，答案按作答时的问卷版本渲染
+type AssessmentSessionResponse struct {
+	AssessmentResponse
+	Answers []AnswerWithQuestion `json:"answers"`
 }
 
 // AssessmentWithAnswers 包含答案的评估
 type AssessmentWithAnswers struct {
-	ID         uint      `json:"id"`
-	UserID     uint      `json:"user_id"`
-	Title      string    `json:"title"`
-	Type       string    `json:"type"`
-	TotalScore int       `json:"total_score"`
-	MaxScore   int       `json:"max_score"`
-	Level      string    `json:"level"`
-	Result     string    `json:"result"`
-	Status     int       `json:"status"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
-	Answers    []AnswerWithQuestion `json:"answers"`
+	ID                uint                 `json:"id"`
+	UserID            uint                 `json:"user_id"`
+	Title             string               `json:"title"`
+	Type              string               `json:"type"`
+	TotalScore        int                  `json:"total_score"`
+	MaxScore          int                  `json:"max_score"`
+	Level             string               `json:"level"`
+	Result            string               `json:"result"`
+	Status            int                  `json:"status"`
+	Mode              string               `json:"mode"`
+	RiskFlagged       bool                 `json:"risk_flagged"`
+	QualityFlag       string               `json:"quality_flag,omitempty"`
+	QualityConfidence float64              `json:"quality_confidence,omitempty"`
+	QualityReasons    []string             `json:"quality_reasons,omitempty"`
+	CompletedAt       *time.Time           `json:"completed_at,omitempty"`
+	CreatedAt         time.Time            `json:"created_at"`
+	UpdatedAt         time.Time            `json:"updated_at"`
+	Answers           []AnswerWithQuestion `json:"answers"`
 }
 
 // AssessmentResult 评估结果
@@ -91,4 +170,9 @@ type AssessmentResult struct {
 	Percentage  float64 `json:"percentage"`
 	Description string  `json:"description"`
 	Suggestions string  `json:"suggestions"`
-} 
\ No newline at end of file
+
+	Theta   *float64 `json:"theta,omitempty"`    // 自适应测验的θ估计
+	ThetaSE *float64 `json:"theta_se,omitempty"` // θ估计的标准误
+
+	ScoringProfileID uint `json:"-"` // 计分所用的计分方案，用于按语言获取方案中的等级文本
+}
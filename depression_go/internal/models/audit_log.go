@@ -0,0 +1,22 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// AuditLog 审计日志模型，记录管理操作前后的数据
+type AuditLog struct {
+	gorm.Model
+	ActorID      uint   `json:"actor_id" gorm:"index"`                                          // 操作人ID
+	Action       string `json:"action" gorm:"size:50;not null"`                                 // 操作：create, update, delete, restore, status, reorder, role
+	ResourceType string `json:"resource_type" gorm:"size:50;not null;index:idx_audit_resource"` // 资源类型：question等
+	ResourceID   uint   `json:"resource_id" gorm:"index:idx_audit_resource"`                    // 资源ID，批量操作时为0
+	Before       string `json:"before" gorm:"type:text"`                                        // 操作前的数据（JSON）
+	After        string `json:"after" gorm:"type:text"`                                         // 操作后的数据（JSON）
+	IP           string `json:"ip" gorm:"size:64"`                                              // 操作来源IP
+}
+
+// TableName 指定表名
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
@@ -0,0 +1,72 @@
+package models
+
+import "time"
+
+// 每日打卡复用Question和Answer：打卡题目是分类为checkin的问题，
+// 每次打卡保存为一条类型为checkin的已完成评估，和问卷评估一起出现在评估历史中
+
+// CheckinRequest 提交打卡请求，每次回答1-3道打卡题目
+type CheckinRequest struct {
+	Answers []AnswerRequest `json:"answers" binding:"required,min=1,max=3"`
+}
+
+// CheckinAnswerResponse 打卡答案
+type CheckinAnswerResponse struct {
+	QuestionID  uint   `json:"question_id"`
+	Title       string `json:"title"`
+	AnswerValue int    `json:"answer_value"`
+	Content     string `json:"content"`
+}
+
+// CheckinResponse 打卡记录
+type CheckinResponse struct {
+	ID        uint                    `json:"id"` // 对应的评估ID
+	Date      string                  `json:"date"`
+	Answers   []CheckinAnswerResponse `json:"answers"`
+	CreatedAt time.Time               `json:"created_at"`
+}
+
+// CheckinItemAverage 某道打卡题目的平均值
+type CheckinItemAverage struct {
+	QuestionID uint    `json:"question_id"`
+	Title      string  `json:"title"`
+	Average    float64 `json:"average"` // 选项值的平均值
+	Count      int     `json:"count"`   // 作答次数
+}
+
+// CheckinDay 按天汇总的打卡
+type CheckinDay struct {
+	Date     string               `json:"date"`     // 日期（服务器时区），格式2006-01-02
+	Checkins int                  `json:"checkins"` // 当天打卡次数
+	Items    []CheckinItemAverage `json:"items"`
+}
+
+// CheckinStreak 连续打卡天数
+type CheckinStreak struct {
+	Current  int    `json:"current"`   // 截至今天（今天尚未打卡时截至昨天）的连续天数
+	Longest  int    `json:"longest"`   // 历史最长连续天数
+	LastDate string `json:"last_date"` // 最近一次打卡的日期
+	Total    int    `json:"total"`     // 打卡总天数
+}
+
+// CheckinTrendPoint 趋势中的一天
+type CheckinTrendPoint struct {
+	Date    string  `json:"date"`
+	Average float64 `json:"average"`
+}
+
+// CheckinTrend 单道打卡题目的趋势
+type CheckinTrend struct {
+	QuestionID uint                `json:"question_id"`
+	Title      string              `json:"title"`
+	Points     []CheckinTrendPoint `json:"points"` // 只包含有打卡的日期
+	Slope      float64             `json:"slope"`  // 按天线性拟合的斜率（每天变化的选项值），少于2天时为0
+	Direction  string              `json:"direction"`
+}
+
+// 趋势方向
+const (
+	TrendUp     = "up"
+	TrendDown   = "down"
+	TrendStable = "stable"
+)
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// 综合评估的模态
+const (
+	ModalityQuestionnaire = "questionnaire" // 问卷评估
+	ModalityFace          = "face"          // 人脸检测
+	ModalityJournal       = "journal"       // 心情日记
+)
+
+// 模态输入的状态
+const (
+	FusionInputUsed    = "used"    // 参与融合
+	FusionInputMissing = "missing" // 没有可用的记录
+	FusionInputStale   = "stale"   // 与最新的输入间隔过久，不参与融合
+)
+
+// FusionInput 综合评估中一个模态的输入
+type FusionInput struct {
+	Modality string     `json:"modality"`
+	Status   string     `json:"status"`
+	SourceID uint       `json:"source_id,omitempty"` // 问卷评估ID或人脸检测ID，日记为最近窗口内的汇总，没有ID
+	Score    int        `json:"score"`               // 换算到0-100的得分
+	Level    string     `json:"level,omitempty"`
+	Weight   float64    `json:"weight"`         // 配置的权重
+	Applied  float64    `json:"applied_weight"` // 归一化后实际使用的权重，未参与融合时为0
+	Date     *time.Time `json:"date,omitempty"` // 输入的时间
+}
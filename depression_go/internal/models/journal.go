@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// JournalEntry 心情日记
+type JournalEntry struct {
+	gorm.Model
+	UserID         uint    `json:"user_id" gorm:"not null;index"`
+	Content        string  `json:"content" gorm:"type:text;not null"` // 日记内容
+	Mood           *int    `json:"mood"`                              // 用户自评心情（1-10），可选
+	SentimentScore float64 `json:"sentiment_score" gorm:"default:0"`  // 情感得分，-1（非常消极）到1（非常积极）
+	SentimentLabel string  `json:"sentiment_label" gorm:"size:20"`    // 情感倾向：positive, neutral, negative
+	Language       string  `json:"language" gorm:"size:10"`           // 识别出的文本语言：zh, en, mixed
+	Analyzer       string  `json:"analyzer" gorm:"size:50"`           // 使用的情感分析器
+	Score          int     `json:"score" gorm:"default:0"`            // 抑郁倾向信号（0-100），由消极程度换算，用于综合评估
+	Level          string  `json:"level" gorm:"size:20"`              // 等级：normal, mild, moderate, severe
+
+	// 关联关系
+	User User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
+// TableName 指定表名
+func (JournalEntry) TableName() string {
+	return "journal_entries"
+}
+
+// JournalEntryRequest 新建或修改日记请求
+type JournalEntryRequest struct {
+	Content string `json:"content" binding:"required"`
+	Mood    *int   `json:"mood"`
+}
+
+// JournalEntryResponse 日记响应
+type JournalEntryResponse struct {
+	ID             uint        `json:"id"`
+	UserID         uint        `json:"user_id"`
+	Content        string      `json:"content"`
+	Mood           *int        `json:"mood,omitempty"`
+	SentimentScore float64     `json:"sentiment_score"`
+	SentimentLabel string      `json:"sentiment_label"`
+	Language       string      `json:"language"`
+	Analyzer       string      `json:"analyzer"`
+	Score          int         `json:"score"`
+	Level          string      `json:"level"`
+	Matched        []string    `json:"matched,omitempty"` // 命中的情感词，仅在新建或修改时返回
+	Crisis         *CrisisInfo `json:"crisis,omitempty"`  // 日记中出现轻生表述时的危机信息，仅在新建或修改时返回
+	CreatedAt      time.Time   `json:"created_at"`
+	UpdatedAt      time.Time   `json:"updated_at"`
+}
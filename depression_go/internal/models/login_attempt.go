@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+)
+
+// LoginAttempt 登录尝试记录，用于审计和排查暴力破解
+type LoginAttempt struct {
+	ID         uint      `json:"id" gorm:"primarykey"`
+	Identifier string    `json:"identifier" gorm:"size:100;index"` // 登录时填写的用户名或邮箱
+	UserID     uint      `json:"user_id" gorm:"index"`             // 对应的用户，用户不存在时为0
+	IP         string    `json:"ip" gorm:"size:64;index"`
+	UserAgent  string    `json:"user_agent" gorm:"size:255"`
+	Success    bool      `json:"success"`
+	Reason     string    `json:"reason" gorm:"size:20"` // 结果，见LoginResult常量
+	CreatedAt  time.Time `json:"created_at" gorm:"index"`
+}
+
+// TableName 指定表名
+func (LoginAttempt) TableName() string {
+	return "login_attempts"
+}
+
+// 登录尝试的结果
+const (
+	LoginResultSuccess       = "success"        // 登录成功
+	LoginResultUnknownUser   = "unknown_user"   // 用户不存在
+	LoginResultWrongPassword = "wrong_password" // 密码错误
+	LoginResultDisabled      = "disabled"       // 账户已被禁用
+	LoginResultLocked        = "locked"         // 账户已锁定，未校验密码
+	LoginResultIPBlocked     = "ip_blocked"     // 来源IP失败次数过多，未校验密码
+	LoginResultMFAPending    = "mfa_pending"    // 密码正确，等待两步验证
+	LoginResultWrongMFACode  = "wrong_mfa_code" // 两步验证码或恢复码错误
+)
+
+// LoginLockout 登录失败计数（LOCKOUT_STORE=db时使用），键为user:<id>、login:<用户名>或ip:<地址>
+type LoginLockout struct {
+	ID          uint   `gorm:"primarykey"`
+	LockKey     string `gorm:"size:191;not null;uniqueIndex"`
+	Failures    int    `gorm:"not null;default:0"`
+	LockedUntil *time.Time
+	ExpiresAt   time.Time `gorm:"index"` // 最后一次失败（或锁定结束）后计数的保留期限
+}
+
+// TableName 指定表名
+func (LoginLockout) TableName() string {
+	return "login_lockouts"
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserMFA 用户的TOTP两步验证，密钥加密保存；ConfirmedAt为空表示已生成密钥、尚未确认
+type UserMFA struct {
+	gorm.Model
+	UserID       uint       `json:"user_id" gorm:"not null;uniqueIndex"`
+	Secret       string     `json:"-" gorm:"size:255;not null"` // AES-GCM加密后的密钥（base64）
+	ConfirmedAt  *time.Time `json:"confirmed_at"`
+	LastUsedStep int64      `json:"-"` // 最近一次通过验证的时间步，同一验证码不能重复使用
+}
+
+// TableName 指定表名
+func (UserMFA) TableName() string {
+	return "user_mfa"
+}
+
+// MFARecoveryCode 恢复码，只保存哈希值，每个只能使用一次
+type MFARecoveryCode struct {
+	gorm.Model
+	UserID   uint       `json:"user_id" gorm:"not null;index"`
+	CodeHash string     `json:"-" gorm:"size:64;not null;uniqueIndex"`
+	UsedAt   *time.Time `json:"used_at"`
+}
+
+// TableName 指定表名
+func (MFARecoveryCode) TableName() string {
+	return "mfa_recovery_codes"
+}
+
+// MFAChallengeResponse 密码校验通过、需要两步验证时的登录响应
+type MFAChallengeResponse struct {
+	MFARequired        bool   `json:"mfa_required"`
+	EnrollmentRequired bool   `json:"enrollment_required"` // 角色要求两步验证但尚未启用，需要先完成绑定
+	MFAToken           string `json:"mfa_token"`
+	ExpiresIn          int    `json:"expires_in"` // 两步验证令牌有效期（秒）
+}
+
+// MFASetupResponse 生成的TOTP密钥，确认前不生效
+type MFASetupResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"` // otpauth://地址，用于生成二维码
+}
+
+// MFAStatusResponse 两步验证状态
+type MFAStatusResponse struct {
+	Enabled                bool       `json:"enabled"`
+	Required               bool       `json:"required"` // 所属角色要求两步验证
+	ConfirmedAt            *time.Time `json:"confirmed_at"`
+	RecoveryCodesRemaining int        `json:"recovery_codes_remaining"`
+}
+
+// MFACodeRequest 提交验证码
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// MFAVerifyRequest 登录时的两步验证，验证码和恢复码二选一
+type MFAVerifyRequest struct {
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+// MFADisableRequest 关闭两步验证，需要当前密码和验证码
+type MFADisableRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// MFAEnrollResponse 确认启用两步验证后返回的恢复码，只显示这一次；登录时完成绑定还会返回令牌
+type MFAEnrollResponse struct {
+	RecoveryCodes []string      `json:"recovery_codes"`
+	Auth          *AuthResponse `json:"auth,omitempty"`
+}
+
+// RoleMFARequest 设置角色是否要求两步验证
+type RoleMFARequest struct {
+	RequireMFA *bool `json:"require_mfa" binding:"required"`
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserIdentity 用户关联的外部身份，同一提供方的同一subject只能关联一个账户
+type UserIdentity struct {
+	gorm.Model
+	UserID          uint       `json:"user_id" gorm:"not null;index"`
+	Provider        string     `json:"provider" gorm:"size:50;not null;uniqueIndex:idx_identity_provider_subject"`
+	Subject         string     `json:"subject" gorm:"size:191;not null;uniqueIndex:idx_identity_provider_subject"`
+	Email           string     `json:"email" gorm:"size:100"` // 最近一次登录时提供方返回的邮箱
+	LastLoginAt     *time.Time `json:"last_login_at"`
+	AuthenticatedAt *time.Time `json:"authenticated_at"` // 最近一次登录时提供方认证本人的时间（auth_time），用于敏感操作前的重新认证
+}
+
+// TableName 指定表名
+func (UserIdentity) TableName() string {
+	return "user_identities"
+}
+
+// OIDCState 发起单点登录时保存的state、nonce和PKCE校验码，回调时使用一次后删除
+type OIDCState struct {
+	ID           uint      `json:"id" gorm:"primarykey"`
+	StateHash    string    `json:"-" gorm:"size:64;not null;uniqueIndex"`
+	Provider     string    `json:"provider" gorm:"size:50;not null"`
+	Nonce        string    `json:"-" gorm:"size:64;not null"`
+	CodeVerifier string    `json:"-" gorm:"size:128;not null"`
+	ExpiresAt    time.Time `json:"expires_at" gorm:"index"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// TableName 指定表名
+func (OIDCState) TableName() string {
+	return "oidc_states"
+}
+
+// OIDCProviderResponse 可用的单点登录提供方
+type OIDCProviderResponse struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+}
+
+// OIDCAuthorizeResponse 发起单点登录，前端跳转到authorization_url
+type OIDCAuthorizeResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+	State            string `json:"state"`
+	ExpiresIn        int    `json:"expires_in"` // state有效期（秒）
+}
+
+// OIDCCallbackRequest 提供方回调到前端后，前端提交code和state完成登录
+type OIDCCallbackRequest struct {
+	Code  string `json:"code" binding:"required"`
+	State string `json:"state" binding:"required"`
+}
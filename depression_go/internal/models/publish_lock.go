@@ -0,0 +1,17 @@
+package models
+
+// 发布锁名称
+const (
+	PublishLockQuestionnaire  = "questionnaire_version"
+	PublishLockScoringProfile = "scoring_profile"
+)
+
+// PublishLock 发布锁：发布问卷版本、计分方案时在事务内锁定对应的行，使版本号依次递增
+type PublishLock struct {
+	Name string `json:"name" gorm:"primaryKey;size:50"`
+}
+
+// TableName 指定表名
+func (PublishLock) TableName() string {
+	return "publish_locks"
+}
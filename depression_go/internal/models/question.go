@@ -18,10 +18,31 @@ type Question struct {
 	OrderNum    int    `json:"order_num" gorm:"default:0"`       // 排序号
 	Status      int    `json:"status" gorm:"default:1"`          // 1:启用 0:禁用
 
+	RiskItem     bool `json:"risk_item" gorm:"default:false"` // 是否为风险条目（如PHQ-9第9题），作答后不只计入总分
+	RiskBaseline int  `json:"risk_baseline"`                  // 选项值大于该值即触发危机流程，0表示任何作答均触发；创建时未指定为1，即除第一个选项外均触发
+
+	IRTDiscrimination float64 `json:"irt_discrimination" gorm:"default:0"` // IRT区分度a，为0表示不参与自适应测验
+	IRTThresholds     string  `json:"irt_thresholds" gorm:"type:text"`     // IRT等级反应模型的阈值b（JSON数组，长度为选项数-1，递增）
+
+	ConsistencyPairID   uint `json:"consistency_pair_id" gorm:"default:0"`      // 用于一致性检查的配对题目ID，0表示不配对
+	ConsistencyReversed bool `json:"consistency_reversed" gorm:"default:false"` // 配对题目是否反向计分（选项顺序相反）
+
+	CurrentRevisionID uint `json:"current_revision_id" gorm:"default:0"` // 当前内容对应的修订
+
 	// 关联关系
 	Answers []Answer `json:"answers,omitempty" gorm:"foreignKey:QuestionID"`
 }
 
+// 问题类型
+const (
+	QuestionTypeSingle   = "single"
+	QuestionTypeMultiple = "multiple"
+	QuestionTypeText     = "text"
+)
+
+// QuestionCategoryCheckin 每日打卡题目的分类，这类题目不进入问卷版本
+const QuestionCategoryCheckin = "checkin"
+
 // TableName 指定表名
 func (Question) TableName() string {
 	return "questions"
@@ -44,31 +65,97 @@ type QuestionCreateRequest struct {
 	Options     string `json:"options"`
 	Score       int    `json:"score"`
 	OrderNum    int    `json:"order_num"`
+
+	RiskItem     bool `json:"risk_item"`
+	RiskBaseline *int `json:"risk_baseline"` // 不传时为1
+
+	IRTDiscrimination float64 `json:"irt_discrimination"`
+	IRTThresholds     string  `json:"irt_thresholds"`
+
+	ConsistencyPairID   uint `json:"consistency_pair_id"`
+	ConsistencyReversed bool `json:"consistency_reversed"`
 }
 
-// QuestionUpdateRequest 更新问题请求
+// QuestionUpdateRequest 更新问题请求（字段为空表示不修改）
 type QuestionUpdateRequest struct {
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	Type        string `json:"type"`
-	Category    string `json:"category"`
-	Options     string `json:"options"`
-	Score       int    `json:"score"`
-	OrderNum    int    `json:"order_num"`
-	Status      int    `json:"status"`
+	Title       *string `json:"title"`
+	Description *string `json:"description"`
+	Type        *string `json:"type"`
+	Category    *string `json:"category"`
+	Options     *string `json:"options"`
+	Score       *int    `json:"score"`
+	OrderNum    *int    `json:"order_num"`
+	Status      *int    `json:"status"`
+
+	RiskItem     *bool `json:"risk_item"`
+	RiskBaseline *int  `json:"risk_baseline"`
+
+	IRTDiscrimination *float64 `json:"irt_discrimination"`
+	IRTThresholds     *string  `json:"irt_thresholds"`
+
+	ConsistencyPairID   *uint `json:"consistency_pair_id"`
+	ConsistencyReversed *bool `json:"consistency_reversed"`
+}
+
+// QuestionStatusRequest 启用/禁用问题请求
+type QuestionStatusRequest struct {
+	Status *int `json:"status" binding:"required"`
+}
+
+// QuestionOrderItem 问题排序项
+type QuestionOrderItem struct {
+	ID       uint `json:"id" binding:"required"`
+	OrderNum int  `json:"order_num"`
 }
 
-// QuestionResponse 问题响应
+// QuestionReorderRequest 批量调整问题排序请求
+type QuestionReorderRequest struct {
+	Items []QuestionOrderItem `json:"items" binding:"required,dive"`
+}
+
+// QuestionResponse 问题响应（管理员接口）
 type QuestionResponse struct {
-	ID          uint      `json:"id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	Type        string    `json:"type"`
-	Category    string    `json:"category"`
-	Options     string    `json:"options"`
-	Score       int       `json:"score"`
-	OrderNum    int       `json:"order_num"`
-	Status      int       `json:"status"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID                  uint       `json:"id"`
+	Title               string     `json:"title"`
+	Description         string     `json:"description"`
+	Type                string     `json:"type"`
+	Category            string     `json:"category"`
+	Options             string     `json:"options"`
+	Score               int        `json:"score"`
+	OrderNum            int        `json:"order_num"`
+	Status              int        `json:"status"`
+	RiskItem            bool       `json:"risk_item"`
+	RiskBaseline        int        `json:"risk_baseline"`
+	IRTDiscrimination   float64    `json:"irt_discrimination"`
+	IRTThresholds       string     `json:"irt_thresholds"`
+	ConsistencyPairID   uint       `json:"consistency_pair_id"`
+	ConsistencyReversed bool       `json:"consistency_reversed"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+	DeletedAt           *time.Time `json:"deleted_at,omitempty"` // 仅管理员接口返回已删除的问题
+}
+
+// PublicQuestionResponse 答题接口返回的问题，不含计分一致性配对和IRT参数
+type PublicQuestionResponse struct {
+	ID           uint      `json:"id"`
+	Title        string    `json:"title"`
+	Description  string    `json:"description"`
+	Type         string    `json:"type"`
+	Category     string    `json:"category"`
+	Options      string    `json:"options"`
+	Score        int       `json:"score"`
+	OrderNum     int       `json:"order_num"`
+	Status       int       `json:"status"`
+	RiskItem     bool      `json:"risk_item"`
+	RiskBaseline int       `json:"risk_baseline"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// DefaultRiskBaseline 未指定风险基线时的默认值：除第一个选项外均触发
+const DefaultRiskBaseline = 1
+
+// IsRiskAnswer 判断该选项值是否触发危机流程
+func (q Question) IsRiskAnswer(answerValue int) bool {
+	return q.RiskItem && answerValue > q.RiskBaseline
 }
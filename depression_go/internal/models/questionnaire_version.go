@@ -0,0 +1,138 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// QuestionRevision 问题修订版本（不可变），问题内容或计分变化时生成新修订
+type QuestionRevision struct {
+	gorm.Model
+	QuestionID   uint   `json:"question_id" gorm:"not null;uniqueIndex:idx_question_revision"`
+	Revision     int    `json:"revision" gorm:"not null;uniqueIndex:idx_question_revision"` // 修订号，从1开始
+	Title        string `json:"title" gorm:"size:500;not null"`
+	Description  string `json:"description" gorm:"size:1000"`
+	Type         string `json:"type" gorm:"size:20;not null"`
+	Category     string `json:"category" gorm:"size:50;not null"`
+	Options      string `json:"options" gorm:"type:text"`
+	Score        int    `json:"score" gorm:"default:0"`
+	RiskItem     bool   `json:"risk_item" gorm:"default:false"`
+	RiskBaseline int    `json:"risk_baseline"`
+
+	IRTDiscrimination float64 `json:"irt_discrimination" gorm:"default:0"`
+	IRTThresholds     string  `json:"irt_thresholds" gorm:"type:text"`
+
+	ConsistencyPairID   uint `json:"consistency_pair_id" gorm:"default:0"`
+	ConsistencyReversed bool `json:"consistency_reversed" gorm:"default:false"`
+}
+
+// TableName 指定表名
+func (QuestionRevision) TableName() string {
+	return "question_revisions"
+}
+
+// NewQuestionRevision 根据问题当前内容生成修订（修订号由调用方设置）
+func NewQuestionRevision(question Question) QuestionRevision {
+	return QuestionRevision{
+		QuestionID:   question.ID,
+		Title:        question.Title,
+		Description:  question.Description,
+		Type:         question.Type,
+		Category:     question.Category,
+		Options:      question.Options,
+		Score:        question.Score,
+		RiskItem:     question.RiskItem,
+		RiskBaseline: question.RiskBaseline,
+
+		IRTDiscrimination: question.IRTDiscrimination,
+		IRTThresholds:     question.IRTThresholds,
+
+		ConsistencyPairID:   question.ConsistencyPairID,
+		ConsistencyReversed: question.ConsistencyReversed,
+	}
+}
+
+// SameContent 判断修订内容是否与问题当前内容一致
+func (r QuestionRevision) SameContent(question Question) bool {
+	return r.Title == question.Title &&
+		r.Description == question.Description &&
+		r.Type == question.Type &&
+		r.Category == question.Category &&
+		r.Options == question.Options &&
+		r.Score == question.Score &&
+		r.RiskItem == question.RiskItem &&
+		r.RiskBaseline == question.RiskBaseline &&
+		r.IRTDiscrimination == question.IRTDiscrimination &&
+		r.IRTThresholds == question.IRTThresholds &&
+		r.ConsistencyPairID == question.ConsistencyPairID &&
+		r.ConsistencyReversed == question.ConsistencyReversed
+}
+
+// AsQuestion 以修订内容还原出问题，用于按当时展示的版本计分和渲染
+func (r QuestionRevision) AsQuestion() Question {
+	question := Question{
+		Title:        r.Title,
+		Description:  r.Description,
+		Type:         r.Type,
+		Category:     r.Category,
+		Options:      r.Options,
+		Score:        r.Score,
+		Status:       1,
+		RiskItem:     r.RiskItem,
+		RiskBaseline: r.RiskBaseline,
+
+		IRTDiscrimination: r.IRTDiscrimination,
+		IRTThresholds:     r.IRTThresholds,
+
+		ConsistencyPairID:   r.ConsistencyPairID,
+		ConsistencyReversed: r.ConsistencyReversed,
+	}
+	question.ID = r.QuestionID
+	question.CreatedAt = r.CreatedAt
+	question.UpdatedAt = r.CreatedAt
+	return question
+}
+
+// QuestionnaireVersion 问卷版本（不可变），题库每次变更后发布新版本
+type QuestionnaireVersion struct {
+	gorm.Model
+	Version   uint   `json:"version" gorm:"not null;uniqueIndex"` // 版本号，从1开始递增
+	Note      string `json:"note" gorm:"size:255"`                // 版本说明（触发变更的操作）
+	CreatedBy uint   `json:"created_by"`                          // 发布人ID，系统自动生成时为0
+
+	// 关联关系
+	Items []QuestionnaireVersionItem `json:"items,omitempty" gorm:"foreignKey:VersionID"`
+}
+
+// TableName 指定表名
+func (QuestionnaireVersion) TableName() string {
+	return "questionnaire_versions"
+}
+
+// QuestionnaireVersionItem 问卷版本中的题目及其修订
+type QuestionnaireVersionItem struct {
+	ID         uint `json:"id" gorm:"primarykey"`
+	VersionID  uint `json:"version_id" gorm:"not null;uniqueIndex:idx_version_question"`
+	QuestionID uint `json:"question_id" gorm:"not null;uniqueIndex:idx_version_question"`
+	RevisionID uint `json:"revision_id" gorm:"not null"`
+	OrderNum   int  `json:"order_num"`
+
+	// 关联关系
+	Revision QuestionRevision `json:"revision" gorm:"foreignKey:RevisionID"`
+}
+
+// TableName 指定表名
+func (QuestionnaireVersionItem) TableName() string {
+	return "questionnaire_version_items"
+}
+
+// QuestionnaireVersionResponse 问卷版本响应
+type QuestionnaireVersionResponse struct {
+	ID        uint               `json:"id"`
+	Version   uint               `json:"version"`
+	Note      string             `json:"note"`
+	CreatedBy uint               `json:"created_by"`
+	CreatedAt time.Time          `json:"created_at"`
+	Questions []QuestionResponse `json:"questions,omitempty"`
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// Role 角色，User.Role保存角色名
+type Role struct {
+	gorm.Model
+	Name        string       `json:"name" gorm:"uniqueIndex;size:20;not null"`
+	Description string       `json:"description" gorm:"size:100"`
+	RequireMFA  bool         `json:"require_mfa" gorm:"default:false"` // 该角色的用户登录时必须通过两步验证
+	Permissions []Permission `json:"permissions" gorm:"many2many:role_permissions"`
+}
+
+// TableName 指定表名
+func (Role) TableName() string {
+	return "roles"
+}
+
+// Permission 权限
+type Permission struct {
+	gorm.Model
+	Code        string `json:"code" gorm:"uniqueIndex;size:50;not null"`
+	Description string `json:"description" gorm:"size:100"`
+}
+
+// TableName 指定表名
+func (Permission) TableName() string {
+	return "permissions"
+}
+
+// 权限
+const (
+	PermQuestionManage    = "question:manage"    // 题库、问卷版本、翻译和结果文本
+	PermScoringManage     = "scoring:manage"     // 计分方案
+	PermAssessmentRescore = "assessment:rescore" // 重新计分
+	PermUserRead          = "user:read"          // 查看用户信息
+	PermUserManage        = "user:manage"        // 修改用户角色和状态
+	PermTrendRead         = "trend:read"         // 查看用户的纵向趋势
+	PermAuditRead         = "audit:read"         // 查看审计日志
+	PermRiskManage        = "risk:manage"        // 查看和处理风险事件
+)
+
+// UserRoleRequest 修改用户角色请求
+type UserRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// UserStatusRequest 启用/禁用用户请求
+type UserStatusRequest struct {
+	Status *int `json:"status" binding:"required,oneof=0 1"`
+}
+
+// RoleResponse 角色及其权限
+type RoleResponse struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	RequireMFA  bool     `json:"require_mfa"`
+	Permissions []string `json:"permissions"`
+}
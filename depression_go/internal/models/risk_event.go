@@ -0,0 +1,70 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 风险事件状态
+const (
+	RiskEventStatusOpen         = "open"         // 待处理
+	RiskEventStatusAcknowledged = "acknowledged" // 已接手
+	RiskEventStatusResolved     = "resolved"     // 已解决
+)
+
+// 风险事件来源
+const (
+	RiskSourceAssessment = "assessment" // 风险条目被非零作答
+	RiskSourceJournal    = "journal"    // 心情日记中出现轻生表述
+)
+
+// RiskEvent 风险事件模型（风险条目被非零作答，或日记中出现轻生表述时创建）
+type RiskEvent struct {
+	gorm.Model
+	UserID         uint       `json:"user_id" gorm:"not null;index"`
+	Source         string     `json:"source" gorm:"size:20;default:'assessment'"` // assessment, journal
+	AssessmentID   *uint      `json:"assessment_id" gorm:"index"`                 // 来源为评估时的评估ID
+	QuestionID     *uint      `json:"question_id"`                                // 来源为评估时触发的问题ID
+	JournalEntryID *uint      `json:"journal_entry_id" gorm:"index"`              // 来源为日记时的日记ID
+	AnswerValue    int        `json:"answer_value"`                               // 触发时的选项值，日记为0
+	AnswerContent  string     `json:"answer_content" gorm:"size:500"`             // 触发时的选项文本，日记为命中的表述
+	Status         string     `json:"status" gorm:"size:20;default:'open'"`       // open, acknowledged, resolved
+	NotifiedAt     *time.Time `json:"notified_at"`                                // 通知响应人员的时间，为空表示尚未送达
+	NotifyError    string     `json:"notify_error" gorm:"type:text"`              // 通知失败原因
+	NotifyAttempts int        `json:"notify_attempts" gorm:"default:0"`           // 已尝试通知的次数
+	HandlerID      uint       `json:"handler_id"`                                 // 最近一次处理的响应人员
+	HandlerNote    string     `json:"handler_note" gorm:"size:500"`               // 处理备注
+	AcknowledgedAt *time.Time `json:"acknowledged_at"`
+	ResolvedAt     *time.Time `json:"resolved_at"`
+
+	// 关联关系
+	User     *User     `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	Question *Question `json:"question,omitempty" gorm:"foreignKey:QuestionID"`
+}
+
+// TableName 指定表名
+func (RiskEvent) TableName() string {
+	return "risk_events"
+}
+
+// RiskEventStatusRequest 修改风险事件状态请求
+type RiskEventStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=acknowledged resolved"`
+	Note   string `json:"note" binding:"max=500"`
+}
+
+// CrisisResource 危机求助资源
+type CrisisResource struct {
+	Name        string `json:"name"`
+	Phone       string `json:"phone"`
+	Description string `json:"description,omitempty"`
+}
+
+// CrisisInfo 危机流程响应信息，随提交结果一并返回
+type CrisisInfo struct {
+	Triggered    bool             `json:"triggered"`
+	Message      string           `json:"message"`
+	Resources    []CrisisResource `json:"resources"`
+	RiskEventIDs []uint           `json:"risk_event_ids"`
+}
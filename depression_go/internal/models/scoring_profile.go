@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ScoringProfile 计分方案（不可变），管理员每次修改都发布新版本，最新版本为当前生效的方案
+type ScoringProfile struct {
+	gorm.Model
+	Version   uint   `json:"version" gorm:"not null;uniqueIndex"` // 版本号，从1开始递增
+	Bands     string `json:"bands" gorm:"type:text;not null"`     // 等级划分（JSON数组，见ScoreBand）
+	Note      string `json:"note" gorm:"size:255"`                // 版本说明
+	CreatedBy uint   `json:"created_by"`                          // 发布人ID，系统自动生成时为0
+
+	// 可靠变化指数（Jacobson-Truax）所需的量表参数，均以得分百分比为单位，0表示使用默认值
+	NormSD         float64 `json:"norm_sd" gorm:"default:0"`         // 常模标准差
+	Reliability    float64 `json:"reliability" gorm:"default:0"`     // 信度（重测信度或内部一致性）
+	ClinicalCutoff float64 `json:"clinical_cutoff" gorm:"default:0"` // 临床界值，得分百分比不低于该值视为临床范围
+}
+
+// TableName 指定表名
+func (ScoringProfile) TableName() string {
+	return "scoring_profiles"
+}
+
+// ScoreBand 计分方案中的一个等级：得分占满分的百分比不低于MinPercentage时落入该等级
+type ScoreBand struct {
+	Level         string                   `json:"level" binding:"required"`
+	MinPercentage float64                  `json:"min_percentage"`  // 下限（含），0-100
+	Texts         map[string]ScoreBandText `json:"texts,omitempty"` // 按语言的描述和建议，未给出时使用结果文本
+}
+
+// ScoreBandText 等级的描述和建议
+type ScoreBandText struct {
+	Description string `json:"description"`
+	Suggestions string `json:"suggestions"`
+}
+
+// ScoringProfileRequest 发布计分方案请求
+type ScoringProfileRequest struct {
+	Bands []ScoreBand `json:"bands" binding:"required"`
+	Note  string      `json:"note"`
+
+	NormSD         float64 `json:"norm_sd"`
+	Reliability    float64 `json:"reliability"`
+	ClinicalCutoff float64 `json:"clinical_cutoff"`
+}
+
+// ScoringProfileResponse 计分方案响应
+type ScoringProfileResponse struct {
+	ID        uint        `json:"id"`
+	Version   uint        `json:"version"`
+	Bands     []ScoreBand `json:"bands"`
+	Note      string      `json:"note"`
+	CreatedBy uint        `json:"created_by"`
+	CreatedAt time.Time   `json:"created_at"`
+
+	NormSD         float64 `json:"norm_sd"`
+	Reliability    float64 `json:"reliability"`
+	ClinicalCutoff float64 `json:"clinical_cutoff"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// UserSession 登录会话：每次登录创建一个，ID即访问令牌的jti，吊销后该会话签发的访问令牌和刷新令牌全部失效
+type UserSession struct {
+	ID           string     `json:"id" gorm:"primaryKey;size:36"`
+	UserID       uint       `json:"user_id" gorm:"not null;index"`
+	UserAgent    string     `json:"user_agent" gorm:"size:255"`
+	Device       string     `json:"device" gorm:"size:100"` // 由User-Agent识别的浏览器和系统，如 Chrome / Windows
+	IP           string     `json:"ip" gorm:"size:45"`
+	LastSeenAt   time.Time  `json:"last_seen_at"`
+	ExpiresAt    time.Time  `json:"expires_at" gorm:"index"` // 最近一个刷新令牌的过期时间
+	RevokedAt    *time.Time `json:"revoked_at"`
+	RevokeReason string     `json:"revoke_reason" gorm:"size:20"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (UserSession) TableName() string {
+	return "user_sessions"
+}
+
+// SessionResponse 会话列表项
+type SessionResponse struct {
+	ID         string    `json:"id"`
+	Device     string    `json:"device"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	Current    bool      `json:"current"` // 是否为发起请求的会话
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RefreshToken 刷新令牌，只保存哈希值；每次刷新都会轮换，同一次登录轮换出的令牌属于同一个会话
+type RefreshToken struct {
+	gorm.Model
+	UserID       uint       `json:"user_id" gorm:"not null;index"`
+	TokenHash    string     `json:"-" gorm:"size:64;not null;uniqueIndex"` // 令牌的SHA-256（十六进制）
+	SessionID    string     `json:"session_id" gorm:"size:36;not null;index"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	RevokedAt    *time.Time `json:"revoked_at"`
+	RevokeReason string     `json:"revoke_reason" gorm:"size:20"`
+}
+
+// 刷新令牌和会话的吊销原因
+const (
+	RevokeReasonRotated  = "rotated"  // 刷新令牌已轮换为新令牌
+	RevokeReasonLogout   = "logout"   // 用户注销
+	RevokeReasonRevoked  = "revoked"  // 用户在会话管理中移除
+	RevokeReasonReuse    = "reuse"    // 检测到已轮换的令牌被再次使用，吊销整个会话
+	RevokeReasonPassword = "password" // 修改密码
+	RevokeReasonDisabled = "disabled" // 账户被禁用
+	RevokeReasonMFA      = "mfa"      // 角色要求两步验证而用户尚未启用
+)
+
+// TableName 指定表名
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
+
+// RefreshTokenRequest 刷新令牌请求
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// TokenPair 访问令牌和刷新令牌
+type TokenPair struct {
+	Token            string    `json:"token"`
+	ExpiresIn        int       `json:"expires_in"` // 访问令牌有效期（秒）
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
+
+// AuthResponse 登录、注册等返回的用户信息和令牌
+type AuthResponse struct {
+	User *UserResponse `json:"user,omitempty"`
+	TokenPair
+}
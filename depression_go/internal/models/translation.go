@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// QuestionTranslation 问题的翻译（标题、说明、选项），题库原文为默认语言；
+// 按翻译时的问题修订保存，只用于原文与该修订一致的展示，题目修改后需要重新翻译
+type QuestionTranslation struct {
+	gorm.Model
+	QuestionID         uint   `json:"question_id" gorm:"not null;uniqueIndex:idx_question_revision_locale"`
+	QuestionRevisionID uint   `json:"question_revision_id" gorm:"not null;uniqueIndex:idx_question_revision_locale"` // 翻译所依据的问题修订
+	Locale             string `json:"locale" gorm:"size:10;not null;uniqueIndex:idx_question_revision_locale"`       // 语言，如en-US
+	Title              string `json:"title" gorm:"size:500;not null"`
+	Description        string `json:"description" gorm:"size:1000"`
+	Options            string `json:"options" gorm:"type:text"` // JSON数组，顺序与原题选项一一对应
+	Stale              bool   `json:"stale" gorm:"-"`           // 问题在翻译后被修改过，当前展示不再使用该翻译
+
+	Revision QuestionRevision `json:"-" gorm:"foreignKey:QuestionRevisionID"`
+}
+
+// TableName 指定表名
+func (QuestionTranslation) TableName() string {
+	return "question_translations"
+}
+
+// ResultTextTranslation 结果文本的覆盖翻译，未覆盖时使用内置文本
+type ResultTextTranslation struct {
+	gorm.Model
+	TextKey string `json:"key" gorm:"size:100;not null;uniqueIndex:idx_text_locale"`   // 文本key，如assessment.mild.description
+	Locale  string `json:"locale" gorm:"size:10;not null;uniqueIndex:idx_text_locale"` // 语言
+	Text    string `json:"text" gorm:"type:text;not null"`
+}
+
+// TableName 指定表名
+func (ResultTextTranslation) TableName() string {
+	return "result_text_translations"
+}
+
+// QuestionTranslationRequest 保存问题翻译请求
+type QuestionTranslationRequest struct {
+	Title       string `json:"title" binding:"required"`
+	Description string `json:"description"`
+	Options     string `json:"options"` // JSON数组字符串，选择题必填
+}
+
+// ResultTextRequest 保存结果文本请求
+type ResultTextRequest struct {
+	Text string `json:"text" binding:"required"`
+}
+
+// ResultTextItem 结果文本列表项
+type ResultTextItem struct {
+	Key        string     `json:"key"`
+	Locale     string     `json:"locale"`
+	Text       string     `json:"text"`       // 当前生效的文本
+	Builtin    string     `json:"builtin"`    // 内置文本（按回退规则）
+	Overridden bool       `json:"overridden"` // 是否被数据库覆盖
+	UpdatedAt  *time.Time `json:"updated_at,omitempty"`
+}
+
+// UserLocaleRequest 设置语言偏好请求，为空表示跟随浏览器（Accept-Language）
+type UserLocaleRequest struct {
+	Locale string `json:"locale"`
+}
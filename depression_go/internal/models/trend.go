@@ -0,0 +1,75 @@
+package models
+
+import "time"
+
+// 变化分类（得分越高越严重）
+const (
+	ChangeRecovered    = "recovered"    // 可靠改善且从临床范围降到界值以下
+	ChangeImproved     = "improved"     // 可靠改善
+	ChangeUnchanged    = "unchanged"    // 变化在测量误差范围内
+	ChangeDeteriorated = "deteriorated" // 可靠恶化
+)
+
+// ChangeParams 计算可靠变化指数所用的量表参数（得分百分比为单位）
+type ChangeParams struct {
+	ScoringProfileID uint    `json:"scoring_profile_id"`
+	NormSD           float64 `json:"norm_sd"`
+	Reliability      float64 `json:"reliability"`
+	ClinicalCutoff   float64 `json:"clinical_cutoff"`
+	SDiff            float64 `json:"s_diff"` // 差异的标准误：√2 × SD × √(1-信度)
+}
+
+// TrendPoint 得分序列中的一次评估
+type TrendPoint struct {
+	AssessmentID uint      `json:"assessment_id"`
+	Date         time.Time `json:"date"`
+	Score        int       `json:"score"`
+	MaxScore     int       `json:"max_score"`
+	Percentage   float64   `json:"percentage"`
+	Level        string    `json:"level,omitempty"`
+	QualityFlag  string    `json:"quality_flag,omitempty"`
+}
+
+// SubscaleSeries 分量表（题目分类）的得分序列
+type SubscaleSeries struct {
+	Category string       `json:"category"`
+	Points   []TrendPoint `json:"points"`
+}
+
+// ReliableChange 两次评估之间的可靠变化
+type ReliableChange struct {
+	FromAssessmentID      uint    `json:"from_assessment_id"`
+	ToAssessmentID        uint    `json:"to_assessment_id"`
+	FromPercentage        float64 `json:"from_percentage"`
+	ToPercentage          float64 `json:"to_percentage"`
+	Difference            float64 `json:"difference"` // 后一次减前一次，正数表示加重
+	RCI                   float64 `json:"rci"`
+	Reliable              bool    `json:"reliable"`               // |RCI| ≥ 1.96
+	ClinicallySignificant bool    `json:"clinically_significant"` // 可靠变化且跨越临床界值
+	Classification        string  `json:"classification"`
+}
+
+// Deterioration 最近几次评估的持续恶化标记
+type Deterioration struct {
+	Flagged       bool            `json:"flagged"`
+	Window        int             `json:"window"`         // 检查的评估次数
+	AssessmentIDs []uint          `json:"assessment_ids"` // 参与检查的评估，按时间先后
+	Change        *ReliableChange `json:"change,omitempty"`
+}
+
+// InstrumentTrend 单个量表的纵向趋势
+type InstrumentTrend struct {
+	Instrument    string           `json:"instrument"` // 评估类型，自适应测验单独成列，如questionnaire、questionnaire:adaptive
+	Series        []TrendPoint     `json:"series"`
+	Subscales     []SubscaleSeries `json:"subscales,omitempty"`
+	Changes       []ReliableChange `json:"changes"` // 相邻两次评估之间的变化
+	Baseline      *ReliableChange  `json:"baseline,omitempty"`
+	Deterioration Deterioration    `json:"deterioration"`
+}
+
+// TrendResponse 用户的纵向趋势
+type TrendResponse struct {
+	UserID      uint              `json:"user_id"`
+	Params      ChangeParams      `json:"params"`
+	Instruments []InstrumentTrend `json:"instruments"`
+}
@@ -16,13 +16,34 @@ type User struct {
 	Gender   string `json:"gender" gorm:"size:10;default:'未知'"`
 	Phone    string `json:"phone" gorm:"size:20"`
 	Avatar   string `json:"avatar" gorm:"size:255"`
-	Status   int    `json:"status" gorm:"default:1"` // 1:正常 0:禁用
+	Status   int    `json:"status" gorm:"default:1"`            // 1:正常 0:禁用
+	Role     string `json:"role" gorm:"size:20;default:'user'"` // 角色：user(普通用户), clinician(咨询师), admin(管理员)
+	Locale   string `json:"locale" gorm:"size:10"`              // 语言偏好，为空时跟随Accept-Language
+
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`      // 邮箱验证时间，为空表示未验证
+	TokenVersion    int        `json:"-" gorm:"default:0"`     // 令牌版本，修改密码时递增，使之前签发的令牌失效
+	RandomPassword  bool       `json:"-" gorm:"default:false"` // 单点登录自动创建的账户，密码为随机生成，用户尚未自行设置
 
 	// 关联关系
 	Assessments    []Assessment    `json:"assessments,omitempty" gorm:"foreignKey:UserID"`
 	FaceDetections []FaceDetection `json:"face_detections,omitempty" gorm:"foreignKey:UserID"`
 }
 
+// 用户角色
+const (
+	RoleUser      = "user"
+	RoleClinician = "clinician"
+	RoleAdmin     = "admin"
+)
+
+// 性别
+const (
+	GenderMale    = "男"
+	GenderFemale  = "女"
+	GenderOther   = "其他"
+	GenderUnknown = "未知"
+)
+
 // TableName 指定表名
 func (User) TableName() string {
 	return "users"
@@ -40,7 +61,7 @@ func (u *User) BeforeCreate(tx *gorm.DB) error {
 type UserRegisterRequest struct {
 	Username string `json:"username" binding:"required,min=3,max=50"`
 	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=6"`
+	Password string `json:"password" binding:"required"`
 	Age      int    `json:"age"`
 	Gender   string `json:"gender"`
 	Phone    string `json:"phone"`
@@ -52,23 +73,33 @@ type UserLoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
-// UserUpdateRequest 用户信息更新请求
+// UserUpdateRequest 用户信息更新请求，未传的字段保持不变
 type UserUpdateRequest struct {
-	Age    int    `json:"age"`
-	Gender string `json:"gender"`
-	Phone  string `json:"phone"`
-	Avatar string `json:"avatar"`
+	Age    *int    `json:"age"`
+	Gender *string `json:"gender"`
+	Phone  *string `json:"phone"`
+	Avatar *string `json:"avatar"`
+}
+
+// UserPasswordRequest 修改密码请求
+type UserPasswordRequest struct {
+	OldPassword string `json:"old_password" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
 }
 
 // UserResponse 用户信息响应
 type UserResponse struct {
-	ID        uint      `json:"id"`
-	Username  string    `json:"username"`
-	Email     string    `json:"email"`
-	Age       int       `json:"age"`
-	Gender    string    `json:"gender"`
-	Phone     string    `json:"phone"`
-	Avatar    string    `json:"avatar"`
-	Status    int       `json:"status"`
-	CreatedAt time.Time `json:"created_at"`
+	ID            uint      `json:"id"`
+	Username      string    `json:"username"`
+	Email         string    `json:"email"`
+	Age           int       `json:"age"`
+	Gender        string    `json:"gender"`
+	Phone         string    `json:"phone"`
+	Avatar        string    `json:"avatar"`
+	Status        int       `json:"status"`
+	Role          string    `json:"role"`
+	EmailVerified bool      `json:"email_verified"`
+	PasswordSet   bool      `json:"password_set"` // 是否设置过密码，单点登录自动创建的账户为false
+	Locale        string    `json:"locale"`
+	CreatedAt     time.Time `json:"created_at"`
 }
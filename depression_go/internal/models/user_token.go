@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserToken 通过邮件发送的一次性令牌（邮箱验证、重置密码），只保存哈希值
+type UserToken struct {
+	gorm.Model
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	Purpose   string     `json:"purpose" gorm:"size:20;not null;index"`
+	TokenHash string     `json:"-" gorm:"size:64;not null;uniqueIndex"` // 令牌的SHA-256（十六进制）
+	Email     string     `json:"email" gorm:"size:100"`                 // 发送时的邮箱，邮箱变更后旧的验证链接失效
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+}
+
+// 一次性令牌的用途
+const (
+	TokenPurposeVerifyEmail   = "verify_email"
+	TokenPurposeResetPassword = "reset_password"
+)
+
+// TableName 指定表名
+func (UserToken) TableName() string {
+	return "user_tokens"
+}
+
+// VerifyEmailRequest 验证邮箱请求
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// ForgotPasswordRequest 申请重置密码请求
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordRequest 重置密码请求
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
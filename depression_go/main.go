@@ -9,8 +9,10 @@ import (
 	"syscall"
 	"time"
 
+	"depression_go/configs"
 	"depression_go/inits"
 	routers "depression_go/routes"
+	"depression_go/services"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -35,9 +37,19 @@ func main() {
 	// 初始化JWT
 	inits.InitConfig()
 
+	// 补齐内置角色和权限
+	if err := services.NewRBACService(inits.DB).EnsureDefaults(); err != nil {
+		log.Fatalf("初始化角色和权限失败: %v", err)
+	}
+
 	// 创建Gin引擎
 	r := gin.Default()
 
+	// 只有来自可信代理的请求才按X-Forwarded-For取客户端IP，否则登录锁定和会话记录的IP可以伪造
+	if err := r.SetTrustedProxies(configs.GlobalConfig.Server.TrustedProxies); err != nil {
+		log.Fatalf("TRUSTED_PROXIES配置错误: %v", err)
+	}
+
 	// 添加CORS中间件
 	r.Use(func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
@@ -57,6 +69,14 @@ func main() {
 	// 设置路由
 	routers.SetupRoutes(r)
 
+	// 启动后台任务
+	jobCtx, stopJobs := context.WithCancel(context.Background())
+	services.NewAssessmentService(inits.DB).StartDraftExpiry(jobCtx)
+	services.NewTokenService(inits.DB).StartPurge(jobCtx)
+	services.NewLockoutService(inits.DB).StartPurge(jobCtx)
+	services.NewAccountDeletionService(inits.DB).StartPurge(jobCtx)
+	services.NewCrisisService(inits.DB).StartNotifyRetry(jobCtx)
+
 	// 获取端口
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -82,6 +102,7 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	log.Println("正在关闭服务器...")
+	stopJobs()
 
 	// 优雅关闭
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -2,13 +2,19 @@ package middleware
 
 import (
 	"strings"
+	"time"
 
+	"depression_go/inits"
+	"depression_go/internal/models"
 	"depression_go/pkg/response"
 	"depression_go/pkg/token"
 
 	"github.com/gin-gonic/gin"
 )
 
+// sessionTouchInterval 会话最近活跃时间的最小记录间隔
+const sessionTouchInterval = 5 * time.Minute
+
 // AuthMiddleware JWT认证中间件
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -38,7 +44,72 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// 令牌所属的会话被注销或移除后令牌失效；没有会话的令牌是旧版本签发的，要求重新登录
+		var session models.UserSession
+		if err := inits.DB.Where("id = ? AND user_id = ?", claims.ID, claims.UserID).First(&session).Error; err != nil || session.RevokedAt != nil {
+			response.Unauthorized(c, "登录状态已失效，请重新登录")
+			c.Abort()
+			return
+		}
+
+		// 账户被禁用时立即失效，修改密码后之前签发的令牌失效
+		var user models.User
+		if err := inits.DB.Select("id", "status", "token_version").First(&user, claims.UserID).Error; err != nil {
+			response.Unauthorized(c, "用户不存在")
+			c.Abort()
+			return
+		}
+		if user.Status == 0 {
+			response.Forbidden(c, "账户已被禁用")
+			c.Abort()
+			return
+		}
+		if user.TokenVersion != claims.Version {
+			response.Unauthorized(c, "登录状态已失效，请重新登录")
+			c.Abort()
+			return
+		}
+
+		touchSession(c, &session)
+
 		// 将用户信息存储到上下文中
+		c.Set("user_id", claims.UserID)
+		c.Set("username", claims.Username)
+		c.Set("claims", claims)
+		c.Set("session_id", claims.ID)
+
+		c.Next()
+	}
+}
+
+// MFAPendingMiddleware 两步验证令牌认证中间件：只用于登录的第二步，令牌中没有会话
+func MFAPendingMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		valid, claims := token.ValidateMFAToken(tokenString)
+		if tokenString == "" || !valid {
+			response.Unauthorized(c, "两步验证令牌无效或已过期，请重新登录")
+			c.Abort()
+			return
+		}
+
+		var user models.User
+		if err := inits.DB.Select("id", "status", "token_version").First(&user, claims.UserID).Error; err != nil {
+			response.Unauthorized(c, "用户不存在")
+			c.Abort()
+			return
+		}
+		if user.Status == 0 {
+			response.Forbidden(c, "账户已被禁用")
+			c.Abort()
+			return
+		}
+		if user.TokenVersion != claims.Version {
+			response.Unauthorized(c, "两步验证令牌无效或已过期，请重新登录")
+			c.Abort()
+			return
+		}
+
 		c.Set("user_id", claims.UserID)
 		c.Set("username", claims.Username)
 		c.Set("claims", claims)
@@ -47,6 +118,15 @@ func AuthMiddleware() gin.HandlerFunc {
 	}
 }
 
+// touchSession 记录会话的最近活跃时间和IP，间隔不足sessionTouchInterval且IP未变时不写库
+func touchSession(c *gin.Context, session *models.UserSession) {
+	ip := c.ClientIP()
+	if time.Since(session.LastSeenAt) < sessionTouchInterval && ip == session.IP {
+		return
+	}
+	inits.DB.Model(session).Updates(map[string]interface{}{"last_seen_at": time.Now(), "ip": ip})
+}
+
 // GetUserID 从上下文中获取用户ID
 func GetUserID(c *gin.Context) uint {
 	if userID, exists := c.Get("user_id"); exists {
@@ -56,3 +136,18 @@ func GetUserID(c *gin.Context) uint {
 	}
 	return 0
 }
+
+// GetSessionID 从上下文中获取当前会话ID
+func GetSessionID(c *gin.Context) string {
+	return c.GetString("session_id")
+}
+
+// GetClaims 从上下文中获取令牌声明
+func GetClaims(c *gin.Context) *token.Claims {
+	if claims, exists := c.Get("claims"); exists {
+		if cl, ok := claims.(*token.Claims); ok {
+			return cl
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"depression_go/inits"
+	"depression_go/internal/models"
+	"depression_go/pkg/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetLocale 获取请求使用的语言，优先级：查询参数lang > 用户语言偏好 > Accept-Language > 默认语言。
+// 结果缓存在上下文中，并写入Content-Language响应头
+func GetLocale(c *gin.Context) string {
+	if locale, exists := c.Get("locale"); exists {
+		if l, ok := locale.(string); ok {
+			return l
+		}
+	}
+
+	locale := resolveLocale(c)
+	c.Set("locale", locale)
+	c.Header("Content-Language", locale)
+	return locale
+}
+
+// resolveLocale 按优先级解析语言
+func resolveLocale(c *gin.Context) string {
+	if locale := i18n.Normalize(c.Query("lang")); locale != "" {
+		return locale
+	}
+
+	if userID := GetUserID(c); userID != 0 && inits.DB != nil {
+		var user models.User
+		if err := inits.DB.Select("locale").First(&user, userID).Error; err == nil {
+			if locale := i18n.Normalize(user.Locale); locale != "" {
+				return locale
+			}
+		}
+	}
+
+	if locale := i18n.ParseAcceptLanguage(c.GetHeader("Accept-Language")); locale != "" {
+		return locale
+	}
+	return i18n.Default
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"depression_go/inits"
+	"depression_go/pkg/response"
+	"depression_go/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePermission 权限中间件，令牌中的角色需拥有全部给定权限，需在AuthMiddleware之后使用
+func RequirePermission(permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims := GetClaims(c)
+		if claims == nil {
+			response.Unauthorized(c, "缺少认证令牌")
+			c.Abort()
+			return
+		}
+
+		allowed, err := services.NewRBACService(inits.DB).HasPermissions(claims.Role, permissions...)
+		if err != nil {
+			response.InternalServerError(c, "权限校验失败")
+			c.Abort()
+			return
+		}
+		if !allowed {
+			response.Forbidden(c, "没有访问权限")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,120 @@
+package i18n
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 支持的语言
+const (
+	ZhCN = "zh-CN"
+	EnUS = "en-US"
+
+	// Default 默认语言，题库和存储的结果文本均使用该语言
+	Default = ZhCN
+)
+
+var supported = []string{ZhCN, EnUS}
+
+// Supported 返回支持的语言列表
+func Supported() []string {
+	return append([]string(nil), supported...)
+}
+
+// Normalize 将语言标签规范化为支持的语言：先精确匹配（忽略大小写和下划线），
+// 再按基础语言匹配（如en-GB、en匹配en-US）；无法匹配时返回空字符串
+func Normalize(tag string) string {
+	tag = strings.TrimSpace(strings.ReplaceAll(tag, "_", "-"))
+	if tag == "" {
+		return ""
+	}
+	for _, locale := range supported {
+		if strings.EqualFold(tag, locale) {
+			return locale
+		}
+	}
+	base := strings.ToLower(strings.SplitN(tag, "-", 2)[0])
+	for _, locale := range supported {
+		if strings.ToLower(strings.SplitN(locale, "-", 2)[0]) == base {
+			return locale
+		}
+	}
+	return ""
+}
+
+// ParseAcceptLanguage 按q值从高到低返回Accept-Language中第一个支持的语言，没有时返回空字符串
+func ParseAcceptLanguage(header string) string {
+	type candidate struct {
+		tag string
+		q   float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		if fields[0] == "" || fields[0] == "*" {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if value, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64); err == nil {
+					q = value
+				}
+			}
+		}
+		if q > 0 {
+			candidates = append(candidates, candidate{tag: fields[0], q: q})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+
+	for _, c := range candidates {
+		if locale := Normalize(c.tag); locale != "" {
+			return locale
+		}
+	}
+	return ""
+}
+
+// Fallbacks 返回查找文本时依次尝试的语言：请求的语言，最后回退到默认语言
+func Fallbacks(locale string) []string {
+	locale = Normalize(locale)
+	if locale == "" || locale == Default {
+		return []string{Default}
+	}
+	return []string{locale, Default}
+}
+
+// Lookup 在内置文本中精确查找指定语言的文本
+func Lookup(locale, key string) (string, bool) {
+	text, ok := catalog[locale][key]
+	return text, ok
+}
+
+// T 获取文本，按Fallbacks依次回退，都没有时返回key本身
+func T(locale, key string) string {
+	for _, l := range Fallbacks(locale) {
+		if text, ok := Lookup(l, key); ok {
+			return text
+		}
+	}
+	return key
+}
+
+// Keys 返回内置文本的全部key（以默认语言为准），按字母排序
+func Keys() []string {
+	keys := make([]string, 0, len(catalog[Default]))
+	for key := range catalog[Default] {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// HasKey 判断key是否为内置文本
+func HasKey(key string) bool {
+	_, ok := catalog[Default][key]
+	return ok
+}
@@ -0,0 +1,70 @@
+package i18n
+
+// catalog 内置的结果文本，可在数据库中按语言覆盖
+//
+// key约定：
+//   - assessment.<level>.description/suggestions：问卷评估结果
+//   - combined.<level>.description/suggestions：综合评估结果
+//   - emotion.<emotion>.<level>：人脸情绪分析描述
+var catalog = map[string]map[string]string{
+	ZhCN: {
+		"assessment.severe.description":   "您的抑郁倾向较为严重，建议立即寻求专业心理咨询师的帮助。",
+		"assessment.severe.suggestions":   "1. 尽快联系专业心理咨询师或精神科医生\n2. 保持规律的作息时间\n3. 多与家人朋友交流\n4. 避免独处时间过长",
+		"assessment.moderate.description": "您存在中等程度的抑郁倾向，建议适当调节心情并考虑寻求专业帮助。",
+		"assessment.moderate.suggestions": "1. 考虑寻求心理咨询师的帮助\n2. 增加户外活动和运动\n3. 培养兴趣爱好\n4. 保持社交活动",
+		"assessment.mild.description":     "您存在轻微的抑郁倾向，属于正常范围，建议适当调节。",
+		"assessment.mild.suggestions":     "1. 多进行户外活动\n2. 保持规律作息\n3. 与朋友多交流\n4. 培养积极心态",
+		"assessment.normal.description":   "您的心理状态良好，继续保持积极的生活态度。",
+		"assessment.normal.suggestions":   "1. 继续保持良好的生活习惯\n2. 定期进行心理健康检查\n3. 帮助身边的人保持心理健康",
+		"combined.severe.description":     "综合评估显示您的抑郁倾向较为严重，建议立即寻求专业帮助。",
+		"combined.severe.suggestions":     "1. 立即联系专业心理咨询师或精神科医生\n2. 保持规律的作息时间\n3. 多与家人朋友交流\n4. 避免独处时间过长\n5. 考虑药物治疗",
+		"combined.moderate.description":   "综合评估显示您存在中等程度的抑郁倾向，建议适当调节并考虑寻求专业帮助。",
+		"combined.moderate.suggestions":   "1. 考虑寻求心理咨询师的帮助\n2. 增加户外活动和运动\n3. 培养兴趣爱好\n4. 保持社交活动\n5. 学习放松技巧",
+		"combined.mild.description":       "综合评估显示您存在轻微的抑郁倾向，属于正常范围，建议适当调节。",
+		"combined.mild.suggestions":       "1. 多进行户外活动\n2. 保持规律作息\n3. 与朋友多交流\n4. 培养积极心态\n5. 学习压力管理",
+		"combined.normal.description":     "综合评估显示您的心理状态良好，继续保持积极的生活态度。",
+		"combined.normal.suggestions":     "1. 继续保持良好的生活习惯\n2. 定期进行心理健康检查\n3. 帮助身边的人保持心理健康\n4. 培养兴趣爱好",
+		"emotion.sad.severe":              "检测到明显的悲伤情绪，建议寻求专业心理咨询",
+		"emotion.sad.moderate":            "检测到中等程度的悲伤情绪，建议适当调节心情",
+		"emotion.sad.mild":                "检测到轻微的悲伤情绪，属于正常范围",
+		"emotion.sad.normal":              "情绪状态正常",
+		"emotion.angry.moderate":          "检测到愤怒情绪，建议冷静处理",
+		"emotion.angry.mild":              "检测到轻微愤怒，属于正常情绪波动",
+		"emotion.fear.moderate":           "检测到恐惧情绪，建议寻求支持",
+		"emotion.fear.mild":               "检测到轻微恐惧，属于正常反应",
+		"emotion.disgust.mild":            "检测到厌恶情绪，属于正常反应",
+		"emotion.surprise.normal":         "检测到惊讶情绪，属于正常反应",
+		"emotion.happy.normal":            "检测到快乐情绪，情绪状态良好",
+		"emotion.neutral.normal":          "情绪状态平静，属于正常范围",
+	},
+	EnUS: {
+		"assessment.severe.description":   "Your depressive tendency appears severe. Please seek help from a professional counselor as soon as possible.",
+		"assessment.severe.suggestions":   "1. Contact a professional counselor or psychiatrist as soon as possible\n2. Keep a regular sleep schedule\n3. Talk more with family and friends\n4. Avoid spending long periods alone",
+		"assessment.moderate.description": "You show a moderate depressive tendency. Try to look after your mood and consider seeking professional help.",
+		"assessment.moderate.suggestions": "1. Consider talking to a counselor\n2. Spend more time outdoors and exercise\n3. Develop hobbies\n4. Stay socially active",
+		"assessment.mild.description":     "You show a mild depressive tendency, which is within the normal range. Some self-care is recommended.",
+		"assessment.mild.suggestions":     "1. Spend more time outdoors\n2. Keep a regular routine\n3. Talk with friends more often\n4. Cultivate a positive mindset",
+		"assessment.normal.description":   "Your mental state is good. Keep up your positive attitude.",
+		"assessment.normal.suggestions":   "1. Keep up your healthy habits\n2. Check in on your mental health regularly\n3. Help the people around you stay mentally healthy",
+		"combined.severe.description":     "The combined assessment indicates a severe depressive tendency. Please seek professional help immediately.",
+		"combined.severe.suggestions":     "1. Contact a professional counselor or psychiatrist immediately\n2. Keep a regular sleep schedule\n3. Talk more with family and friends\n4. Avoid spending long periods alone\n5. Discuss medication options with a doctor",
+		"combined.moderate.description":   "The combined assessment indicates a moderate depressive tendency. Try to look after your mood and consider seeking professional help.",
+		"combined.moderate.suggestions":   "1. Consider talking to a counselor\n2. Spend more time outdoors and exercise\n3. Develop hobbies\n4. Stay socially active\n5. Learn relaxation techniques",
+		"combined.mild.description":       "The combined assessment indicates a mild depressive tendency, which is within the normal range. Some self-care is recommended.",
+		"combined.mild.suggestions":       "1. Spend more time outdoors\n2. Keep a regular routine\n3. Talk with friends more often\n4. Cultivate a positive mindset\n5. Learn to manage stress",
+		"combined.normal.description":     "The combined assessment indicates your mental state is good. Keep up your positive attitude.",
+		"combined.normal.suggestions":     "1. Keep up your healthy habits\n2. Check in on your mental health regularly\n3. Help the people around you stay mentally healthy\n4. Develop hobbies",
+		"emotion.sad.severe":              "Marked sadness detected. Consider seeking professional counseling.",
+		"emotion.sad.moderate":            "Moderate sadness detected. Try to take care of your mood.",
+		"emotion.sad.mild":                "Slight sadness detected, within the normal range.",
+		"emotion.sad.normal":              "Your emotional state is normal.",
+		"emotion.angry.moderate":          "Anger detected. Try to take a moment to calm down.",
+		"emotion.angry.mild":              "Slight anger detected, a normal emotional fluctuation.",
+		"emotion.fear.moderate":           "Fear detected. Consider reaching out for support.",
+		"emotion.fear.mild":               "Slight fear detected, a normal reaction.",
+		"emotion.disgust.mild":            "Disgust detected, a normal reaction.",
+		"emotion.surprise.normal":         "Surprise detected, a normal reaction.",
+		"emotion.happy.normal":            "Happiness detected. Your emotional state is good.",
+		"emotion.neutral.normal":          "Your emotional state is calm and within the normal range.",
+	},
+}
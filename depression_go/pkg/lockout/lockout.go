@@ -0,0 +1,125 @@
+// Package lockout 登录失败计数与锁定状态的存储，可选内存、数据库（由调用方实现）或Redis兼容服务
+package lockout
+
+import (
+	"sync"
+	"time"
+)
+
+// State 一个键（账户或IP）的失败次数和锁定截止时间
+type State struct {
+	Failures    int
+	LockedUntil time.Time // 零值表示未锁定
+}
+
+// Locked 在now时刻是否处于锁定状态
+func (s State) Locked(now time.Time) bool {
+	return now.Before(s.LockedUntil)
+}
+
+// Store 失败计数存储。计数在最后一次失败ttl之后自动清零
+type Store interface {
+	// Get 获取当前状态，不存在时返回零值
+	Get(key string) (State, error)
+	// Fail 失败次数加一并刷新ttl，返回更新后的状态
+	Fail(key string, ttl time.Duration) (State, error)
+	// Lock 锁定到until，ttl为状态的保留时间
+	Lock(key string, until time.Time, ttl time.Duration) error
+	// Reset 清除状态（登录成功或管理员解锁）
+	Reset(key string) error
+}
+
+// Purger 需要定期清理过期记录的存储（如数据库）实现该接口
+type Purger interface {
+	Purge() (int64, error)
+}
+
+// MemoryStore 进程内存储，适用于单实例部署，重启后清零
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]*memoryEntry
+}
+
+type memoryEntry struct {
+	state     State
+	expiresAt time.Time
+}
+
+// NewMemoryStore 创建进程内存储
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]*memoryEntry)}
+}
+
+// Get 获取当前状态
+func (m *MemoryStore) Get(key string) (State, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if entry := m.entry(key, time.Now()); entry != nil {
+		return entry.state, nil
+	}
+	return State{}, nil
+}
+
+// Fail 失败次数加一
+func (m *MemoryStore) Fail(key string, ttl time.Duration) (State, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	entry := m.entry(key, now)
+	if entry == nil {
+		entry = &memoryEntry{}
+		m.entries[key] = entry
+		m.sweep(now)
+	}
+	entry.state.Failures++
+	entry.expiresAt = now.Add(ttl)
+	return entry.state, nil
+}
+
+// Lock 锁定到until
+func (m *MemoryStore) Lock(key string, until time.Time, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	entry := m.entry(key, now)
+	if entry == nil {
+		entry = &memoryEntry{}
+		m.entries[key] = entry
+	}
+	entry.state.LockedUntil = until
+	entry.expiresAt = now.Add(ttl)
+	return nil
+}
+
+// Reset 清除状态
+func (m *MemoryStore) Reset(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.entries, key)
+	return nil
+}
+
+// entry 未过期的记录，已过期的顺便删除；调用方需持有锁
+func (m *MemoryStore) entry(key string, now time.Time) *memoryEntry {
+	entry, ok := m.entries[key]
+	if !ok {
+		return nil
+	}
+	if now.After(entry.expiresAt) {
+		delete(m.entries, key)
+		return nil
+	}
+	return entry
+}
+
+// sweep 记录较多时清理已过期的记录，避免大量不同的键占用内存；调用方需持有锁
+func (m *MemoryStore) sweep(now time.Time) {
+	if len(m.entries) < 10000 {
+		return
+	}
+	for key, entry := range m.entries {
+		if now.After(entry.expiresAt) {
+			delete(m.entries, key)
+		}
+	}
+}
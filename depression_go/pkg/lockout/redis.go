@@ -0,0 +1,242 @@
+package lockout
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RedisStore 基于Redis（或兼容RESP协议的服务，如KeyDB、Valkey）的存储，适用于多实例部署。
+// 每个键保存为一个hash：failures为失败次数，locked_until为锁定截止时间（毫秒时间戳）
+type RedisStore struct {
+	addr     string
+	password string
+	db       int
+	prefix   string
+	timeout  time.Duration
+
+	mu   sync.Mutex
+	conn net.Conn
+	rd   *bufio.Reader
+}
+
+// NewRedisStore 创建Redis存储，连接在首次使用时建立，出错后自动重连
+func NewRedisStore(addr, password string, db int) *RedisStore {
+	return &RedisStore{
+		addr:     addr,
+		password: password,
+		db:       db,
+		prefix:   "lockout:",
+		timeout:  3 * time.Second,
+	}
+}
+
+// Get 获取当前状态
+func (r *RedisStore) Get(key string) (State, error) {
+	replies, err := r.do([]string{"HMGET", r.prefix + key, "failures", "locked_until"})
+	if err != nil {
+		return State{}, err
+	}
+	return parseState(replies[0])
+}
+
+// Fail 失败次数加一并刷新ttl
+func (r *RedisStore) Fail(key string, ttl time.Duration) (State, error) {
+	k := r.prefix + key
+	replies, err := r.do(
+		[]string{"HINCRBY", k, "failures", "1"},
+		[]string{"PEXPIRE", k, strconv.FormatInt(ttl.Milliseconds(), 10)},
+		[]string{"HMGET", k, "failures", "locked_until"},
+	)
+	if err != nil {
+		return State{}, err
+	}
+	return parseState(replies[2])
+}
+
+// Lock 锁定到until
+func (r *RedisStore) Lock(key string, until time.Time, ttl time.Duration) error {
+	k := r.prefix + key
+	_, err := r.do(
+		[]string{"HSET", k, "locked_until", strconv.FormatInt(until.UnixMilli(), 10)},
+		[]string{"PEXPIRE", k, strconv.FormatInt(ttl.Milliseconds(), 10)},
+	)
+	return err
+}
+
+// Reset 清除状态
+func (r *RedisStore) Reset(key string) error {
+	_, err := r.do([]string{"DEL", r.prefix + key})
+	return err
+}
+
+// do 以pipeline方式发送命令并按顺序读取回复，任一命令出错时返回错误
+func (r *RedisStore) do(commands ...[]string) ([]interface{}, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.conn == nil {
+		if err := r.connect(); err != nil {
+			return nil, err
+		}
+	}
+	replies, err := r.roundTrip(commands)
+	var replyErr redisError
+	if err != nil && !errors.As(err, &replyErr) {
+		// 网络错误后连接状态未知，丢弃连接，下次调用时重连
+		r.conn.Close()
+		r.conn = nil
+	}
+	return replies, err
+}
+
+// connect 建立连接，按需认证并选择数据库；调用方需持有锁
+func (r *RedisStore) connect() error {
+	conn, err := net.DialTimeout("tcp", r.addr, r.timeout)
+	if err != nil {
+		return fmt.Errorf("连接Redis失败: %w", err)
+	}
+	r.conn = conn
+	r.rd = bufio.NewReader(conn)
+
+	var setup [][]string
+	if r.password != "" {
+		setup = append(setup, []string{"AUTH", r.password})
+	}
+	if r.db > 0 {
+		setup = append(setup, []string{"SELECT", strconv.Itoa(r.db)})
+	}
+	if len(setup) > 0 {
+		if _, err := r.roundTrip(setup); err != nil {
+			conn.Close()
+			r.conn = nil
+			return fmt.Errorf("初始化Redis连接失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// roundTrip 写出命令并读取同样数量的回复；调用方需持有锁
+func (r *RedisStore) roundTrip(commands [][]string) ([]interface{}, error) {
+	if err := r.conn.SetDeadline(time.Now().Add(r.timeout)); err != nil {
+		return nil, err
+	}
+	var buf []byte
+	for _, args := range commands {
+		buf = append(buf, '*')
+		buf = strconv.AppendInt(buf, int64(len(args)), 10)
+		buf = append(buf, '\r', '\n')
+		for _, arg := range args {
+			buf = append(buf, '$')
+			buf = strconv.AppendInt(buf, int64(len(arg)), 10)
+			buf = append(buf, '\r', '\n')
+			buf = append(buf, arg...)
+			buf = append(buf, '\r', '\n')
+		}
+	}
+	if _, err := r.conn.Write(buf); err != nil {
+		return nil, err
+	}
+
+	// 读完全部回复再返回错误，保证连接可以继续使用
+	replies := make([]interface{}, len(commands))
+	var firstErr error
+	for i := range commands {
+		reply, err := readReply(r.rd)
+		var replyErr redisError
+		if err != nil && !errors.As(err, &replyErr) {
+			return nil, err
+		}
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		replies[i] = reply
+	}
+	return replies, firstErr
+}
+
+// redisError Redis返回的错误回复
+type redisError string
+
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+// readReply 读取一个RESP回复：简单字符串、错误、整数、批量字符串（nil为nil）或数组
+func readReply(rd *bufio.Reader) (interface{}, error) {
+	line, err := rd.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, errors.New("redis: 无效的回复")
+	}
+	body := line[1 : len(line)-2]
+
+	switch line[0] {
+	case '+':
+		return body, nil
+	case '-':
+		return nil, redisError(body)
+	case ':':
+		return strconv.ParseInt(body, 10, 64)
+	case '$':
+		size, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, err
+		}
+		if size < 0 {
+			return nil, nil
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(rd, data); err != nil {
+			return nil, err
+		}
+		return string(data[:size]), nil
+	case '*':
+		count, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, err
+		}
+		if count < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, count)
+		for i := range items {
+			if items[i], err = readReply(rd); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("redis: 未知的回复类型 %q", line[0])
+}
+
+// parseState 由HMGET failures locked_until的回复解析状态
+func parseState(reply interface{}) (State, error) {
+	items, ok := reply.([]interface{})
+	if !ok || len(items) != 2 {
+		return State{}, errors.New("redis: 无效的回复")
+	}
+	var state State
+	if value, ok := items[0].(string); ok {
+		failures, err := strconv.Atoi(value)
+		if err != nil {
+			return State{}, err
+		}
+		state.Failures = failures
+	}
+	if value, ok := items[1].(string); ok {
+		ms, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return State{}, err
+		}
+		state.LockedUntil = time.UnixMilli(ms)
+	}
+	return state, nil
+}
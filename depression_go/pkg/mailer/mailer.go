@@ -0,0 +1,108 @@
+// Package mailer 发送邮件：SMTP用于生产环境，file/log用于本地开发
+package mailer
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Message 一封纯文本邮件
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer 邮件发送器，可按部署环境替换实现
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// LogMailer 只把邮件写入日志，适用于本地开发
+type LogMailer struct{}
+
+// Send 写入日志
+func (LogMailer) Send(msg Message) error {
+	log.Printf("[邮件] 收件人: %s, 主题: %s\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// FileMailer 把邮件保存为目录下的.eml文件，可以用邮件客户端打开查看
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+// NewFileMailer 创建文件发送器
+func NewFileMailer(dir, from string) *FileMailer {
+	return &FileMailer{dir: dir, from: from}
+}
+
+// unsafeFileChars 文件名中不允许出现的字符
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9@._-]`)
+
+// Send 写入文件
+func (m *FileMailer) Send(msg Message) error {
+	if err := os.MkdirAll(m.dir, 0755); err != nil {
+		return err
+	}
+	data, err := Build(m.from, msg, time.Now())
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s_%s.eml", time.Now().Format("20060102_150405.000"), unsafeFileChars.ReplaceAllString(msg.To, "_"))
+	return os.WriteFile(filepath.Join(m.dir, name), data, 0644)
+}
+
+// Build 生成RFC 5322格式的邮件内容，主题按RFC 2047编码，正文为UTF-8 quoted-printable
+func Build(from string, msg Message, date time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	header := func(key, value string) {
+		buf.WriteString(key + ": " + value + "\r\n")
+	}
+	if addr, err := mail.ParseAddress(from); err == nil {
+		from = addr.String() // 名称中的非ASCII字符按RFC 2047编码
+	}
+	header("From", from)
+	header("To", msg.To)
+	header("Subject", mime.BEncoding.Encode("UTF-8", msg.Subject))
+	header("Date", date.Format(time.RFC1123Z))
+	header("Message-ID", messageID(from))
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=UTF-8")
+	header("Content-Transfer-Encoding", "quoted-printable")
+	buf.WriteString("\r\n")
+
+	w := quotedprintable.NewWriter(&buf)
+	if _, err := w.Write(bytes.ReplaceAll([]byte(msg.Body), []byte("\n"), []byte("\r\n"))); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// messageID 生成随机的Message-ID，域名取发件地址的域名
+func messageID(from string) string {
+	domain := "localhost"
+	if addr, err := parseAddress(from); err == nil {
+		if at := strings.LastIndex(addr, "@"); at >= 0 {
+			domain = addr[at+1:]
+		}
+	}
+	buf := make([]byte, 12)
+	rand.Read(buf)
+	return fmt.Sprintf("<%s@%s>", hex.EncodeToString(buf), domain)
+}
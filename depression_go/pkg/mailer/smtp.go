@@ -0,0 +1,134 @@
+package mailer
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// SMTP连接的加密方式
+const (
+	SecurityAuto     = ""         // 服务器支持时使用STARTTLS（默认）
+	SecurityStartTLS = "starttls" // 必须使用STARTTLS
+	SecurityTLS      = "tls"      // 直接TLS连接（通常为465端口）
+	SecurityNone     = "none"     // 不加密，仅用于本地测试服务器
+)
+
+// SMTPConfig SMTP服务器配置，Username为空时不认证
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	Security string
+	From     string
+}
+
+// SMTPMailer 通过SMTP服务器发送邮件
+type SMTPMailer struct {
+	cfg     SMTPConfig
+	timeout time.Duration
+}
+
+// NewSMTPMailer 创建SMTP发送器
+func NewSMTPMailer(cfg SMTPConfig) *SMTPMailer {
+	return &SMTPMailer{cfg: cfg, timeout: 10 * time.Second}
+}
+
+// Send 发送邮件，每次发送建立一个连接
+func (m *SMTPMailer) Send(msg Message) error {
+	from, err := parseAddress(m.cfg.From)
+	if err != nil {
+		return fmt.Errorf("发件地址无效: %w", err)
+	}
+	to, err := parseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("收件地址无效: %w", err)
+	}
+	data, err := Build(m.cfg.From, msg, time.Now())
+	if err != nil {
+		return err
+	}
+
+	client, err := m.dial()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if m.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)); err != nil {
+			return fmt.Errorf("SMTP认证失败: %w", err)
+		}
+	}
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// dial 建立连接并按配置启用TLS
+func (m *SMTPMailer) dial() (*smtp.Client, error) {
+	addr := net.JoinHostPort(m.cfg.Host, strconv.Itoa(m.cfg.Port))
+	tlsConfig := &tls.Config{ServerName: m.cfg.Host}
+	dialer := &net.Dialer{Timeout: m.timeout}
+
+	var conn net.Conn
+	var err error
+	if m.cfg.Security == SecurityTLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("连接SMTP服务器失败: %w", err)
+	}
+	conn.SetDeadline(time.Now().Add(m.timeout))
+
+	client, err := smtp.NewClient(conn, m.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if m.cfg.Security == SecurityTLS || m.cfg.Security == SecurityNone {
+		return client, nil
+	}
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("STARTTLS失败: %w", err)
+		}
+	} else if m.cfg.Security == SecurityStartTLS {
+		client.Close()
+		return nil, errors.New("SMTP服务器不支持STARTTLS")
+	}
+	return client, nil
+}
+
+// parseAddress 从"名称 <地址>"或纯地址中取出邮箱地址
+func parseAddress(value string) (string, error) {
+	addr, err := mail.ParseAddress(value)
+	if err != nil {
+		return "", err
+	}
+	return addr.Address, nil
+}
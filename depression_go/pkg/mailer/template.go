@@ -0,0 +1,72 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"depression_go/pkg/i18n"
+)
+
+// 内置的邮件模板
+const (
+	TemplateVerifyEmail   = "verify_email"
+	TemplateResetPassword = "reset_password"
+	// 注销账户：申请后提醒（可撤销）、删除完成后发送回执
+	TemplateDeletionScheduled = "deletion_scheduled"
+	TemplateDeletionCompleted = "deletion_completed"
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+// templates 模板文件名为<名称>.<语言>.tmpl，每个文件定义subject和body两部分，按文件名索引
+var templates = loadTemplates()
+
+// loadTemplates 分别解析每个模板文件，避免各文件中同名的subject/body互相覆盖
+func loadTemplates() map[string]*template.Template {
+	entries, err := templateFS.ReadDir("templates")
+	if err != nil {
+		panic(err)
+	}
+	result := make(map[string]*template.Template, len(entries))
+	for _, entry := range entries {
+		result[strings.TrimSuffix(entry.Name(), ".tmpl")] = template.Must(template.ParseFS(templateFS, "templates/"+entry.Name()))
+	}
+	return result
+}
+
+// TemplateData 模板可用的数据
+type TemplateData struct {
+	AppName        string
+	Username       string
+	Link           string // 验证或重置链接，注销邮件中为撤销页面或回执地址
+	ExpiresHours   int
+	ExpiresMinutes int
+	ScheduledAt    string // 注销账户的计划删除时间
+	ReceiptID      string // 删除回执编号
+}
+
+// Render 按语言渲染邮件的主题和正文，该语言没有模板时使用默认语言
+func Render(name, locale string, data TemplateData) (subject, body string, err error) {
+	tmpl, ok := templates[name+"."+locale]
+	if !ok {
+		tmpl, ok = templates[name+"."+i18n.Default]
+	}
+	if !ok {
+		return "", "", fmt.Errorf("邮件模板不存在: %s", name)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, "subject", data); err != nil {
+		return "", "", err
+	}
+	subject = strings.TrimSpace(buf.String())
+	buf.Reset()
+	if err := tmpl.ExecuteTemplate(&buf, "body", data); err != nil {
+		return "", "", err
+	}
+	return subject, strings.TrimSpace(buf.String()) + "\n", nil
+}
@@ -0,0 +1,349 @@
+// Package oidc OpenID Connect依赖方（RP）：服务发现、授权码+PKCE流程和ID令牌校验
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrInvalidIDToken ID令牌校验失败
+var ErrInvalidIDToken = errors.New("ID令牌无效")
+
+// Config 一个OIDC提供方（发行方）的客户端配置
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string // 公共客户端可以为空，仅使用PKCE
+	RedirectURL  string
+	Scopes       []string // 为空时使用 openid email profile
+}
+
+// Metadata 服务发现文档中用到的字段
+type Metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Claims ID令牌（或userinfo）中的用户信息
+type Claims struct {
+	Subject           string `json:"sub"`
+	Email             string `json:"email"`
+	EmailVerified     bool   `json:"email_verified"`
+	Name              string `json:"name"`
+	PreferredUsername string `json:"preferred_username"`
+	Nonce             string `json:"nonce"`
+	AuthTime          int64  `json:"auth_time"` // 提供方认证本人的时间（Unix秒），提供方未返回时为0
+}
+
+// idTokenClaims ID令牌中需要校验的声明
+type idTokenClaims struct {
+	Nonce           string `json:"nonce"`
+	AuthorizedParty string `json:"azp"`
+	jwt.RegisteredClaims
+}
+
+// UnmarshalJSON 兼容email_verified为字符串"true"的提供方
+func (c *Claims) UnmarshalJSON(data []byte) error {
+	type plain Claims
+	var raw struct {
+		plain
+		EmailVerified interface{} `json:"email_verified"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*c = Claims(raw.plain)
+	switch v := raw.EmailVerified.(type) {
+	case bool:
+		c.EmailVerified = v
+	case string:
+		c.EmailVerified = strings.EqualFold(v, "true")
+	}
+	return nil
+}
+
+// TokenResponse 令牌端点的响应
+type TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IDToken     string `json:"id_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// Provider 一个已完成服务发现的OIDC提供方，可并发使用
+type Provider struct {
+	cfg      Config
+	metadata Metadata
+	client   *http.Client
+	keys     *keySet
+}
+
+// Discover 读取发行方的/.well-known/openid-configuration，发现文档中的issuer必须与配置一致
+func Discover(ctx context.Context, cfg Config, client *http.Client) (*Provider, error) {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	var metadata Metadata
+	wellKnown := strings.TrimSuffix(cfg.Issuer, "/") + "/.well-known/openid-configuration"
+	if err := getJSON(ctx, client, wellKnown, "", &metadata); err != nil {
+		return nil, fmt.Errorf("OIDC服务发现失败: %w", err)
+	}
+	if metadata.Issuer != cfg.Issuer {
+		return nil, fmt.Errorf("OIDC服务发现失败: issuer不一致（配置 %s，发现文档 %s）", cfg.Issuer, metadata.Issuer)
+	}
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return nil, errors.New("OIDC服务发现失败: 发现文档缺少必要的端点")
+	}
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "email", "profile"}
+	}
+	return &Provider{
+		cfg:      cfg,
+		metadata: metadata,
+		client:   client,
+		keys:     &keySet{uri: metadata.JWKSURI, client: client},
+	}, nil
+}
+
+// AuthCodeURL 授权地址，state用于防CSRF，nonce写入ID令牌防重放，codeChallenge为PKCE的S256挑战；
+// reauth为true时要求提供方重新认证本人（prompt=login、max_age=0），ID令牌中带auth_time
+func (p *Provider) AuthCodeURL(state, nonce, codeChallenge string, reauth bool) string {
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", p.cfg.ClientID)
+	query.Set("redirect_uri", p.cfg.RedirectURL)
+	query.Set("scope", strings.Join(p.cfg.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", codeChallenge)
+	query.Set("code_challenge_method", "S256")
+	if reauth {
+		query.Set("prompt", "login")
+		query.Set("max_age", "0")
+	}
+
+	sep := "?"
+	if strings.Contains(p.metadata.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return p.metadata.AuthorizationEndpoint + sep + query.Encode()
+}
+
+// Exchange 用授权码和PKCE验证码换取令牌
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier string) (*TokenResponse, error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.cfg.RedirectURL)
+	form.Set("code_verifier", codeVerifier)
+	form.Set("client_id", p.cfg.ClientID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.cfg.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("令牌端点返回 %d: %s", resp.StatusCode, truncate(string(body), 200))
+	}
+
+	var token TokenResponse
+	if err := json.Unmarshal(body, &token); err != nil {
+		return nil, err
+	}
+	if token.IDToken == "" {
+		return nil, errors.New("令牌端点没有返回id_token")
+	}
+	return &token, nil
+}
+
+// VerifyIDToken 校验ID令牌的签名、发行方、受众、有效期和nonce
+func (p *Provider) VerifyIDToken(ctx context.Context, raw, nonce string) (*Claims, error) {
+	claims := &idTokenClaims{}
+	_, err := jwt.ParseWithClaims(raw, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.keys.key(ctx, kid, t.Method.Alg())
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "ES256", "ES384"}),
+		jwt.WithIssuer(p.cfg.Issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+	if claims.ExpiresAt == nil {
+		return nil, fmt.Errorf("%w: 缺少exp", ErrInvalidIDToken)
+	}
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != p.cfg.ClientID {
+		return nil, fmt.Errorf("%w: azp不匹配", ErrInvalidIDToken)
+	}
+	if claims.Nonce != nonce {
+		return nil, fmt.Errorf("%w: nonce不匹配", ErrInvalidIDToken)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: 缺少sub", ErrInvalidIDToken)
+	}
+
+	// 签名已校验，再按用户信息的格式解析一遍载荷
+	payload, err := base64.RawURLEncoding.DecodeString(strings.Split(raw, ".")[1])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+	var user Claims
+	if err := json.Unmarshal(payload, &user); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+	return &user, nil
+}
+
+// UserInfo 用访问令牌读取userinfo端点，sub必须与ID令牌一致
+func (p *Provider) UserInfo(ctx context.Context, accessToken, subject string) (*Claims, error) {
+	if p.metadata.UserinfoEndpoint == "" {
+		return nil, errors.New("提供方没有userinfo端点")
+	}
+	var claims Claims
+	if err := getJSON(ctx, p.client, p.metadata.UserinfoEndpoint, accessToken, &claims); err != nil {
+		return nil, err
+	}
+	if claims.Subject != subject {
+		return nil, errors.New("userinfo的sub与ID令牌不一致")
+	}
+	return &claims, nil
+}
+
+// NewState 生成随机字符串，用作state、nonce和PKCE验证码（32字节，base64url编码，43个字符）
+func NewState() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// CodeChallenge PKCE的S256挑战：BASE64URL(SHA256(verifier))
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// getJSON GET请求并解析JSON，bearer不为空时带上访问令牌
+func getJSON(ctx context.Context, client *http.Client, uri, bearer string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+bearer)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s 返回 %d", uri, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(v)
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max]
+}
+
+// keySet 提供方的JWKS公钥，遇到未知kid时重新获取（至少间隔一分钟）
+type keySet struct {
+	uri    string
+	client *http.Client
+
+	mu        sync.Mutex
+	keys      map[string]interface{}
+	fetchedAt time.Time
+}
+
+// key 按kid查找公钥，kid为空且只有一个公钥时使用该公钥
+func (s *keySet) key(ctx context.Context, kid, alg string) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.lookup(kid)
+	if !ok && time.Since(s.fetchedAt) > time.Minute {
+		if err := s.refresh(ctx); err != nil {
+			return nil, err
+		}
+		key, ok = s.lookup(kid)
+	}
+	if !ok {
+		return nil, fmt.Errorf("未找到签名公钥 kid=%s", kid)
+	}
+	if !keyMatchesAlg(key, alg) {
+		return nil, fmt.Errorf("公钥类型与签名算法 %s 不匹配", alg)
+	}
+	return key, nil
+}
+
+// lookup 调用方需持有锁
+func (s *keySet) lookup(kid string) (interface{}, bool) {
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, true
+		}
+	}
+	key, ok := s.keys[kid]
+	return key, ok
+}
+
+// refresh 重新获取JWKS；调用方需持有锁
+func (s *keySet) refresh(ctx context.Context) error {
+	var doc struct {
+		Keys []json.RawMessage `json:"keys"`
+	}
+	if err := getJSON(ctx, s.client, s.uri, "", &doc); err != nil {
+		return fmt.Errorf("获取JWKS失败: %w", err)
+	}
+	keys := make(map[string]interface{}, len(doc.Keys))
+	for _, raw := range doc.Keys {
+		kid, key, err := parseJWK(raw)
+		if err != nil {
+			continue // 忽略不支持的密钥类型
+		}
+		keys[kid] = key
+	}
+	s.keys = keys
+	s.fetchedAt = time.Now()
+	return nil
+}
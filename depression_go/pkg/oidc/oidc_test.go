@@ -0,0 +1,137 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testIssuer   = "https://idp.example.com"
+	testClientID = "depression-app"
+	testNonce    = "nonce-123"
+)
+
+// testProvider 使用给定公钥的提供方，不访问网络
+func testProvider(t *testing.T, key *rsa.PrivateKey) *Provider {
+	t.Helper()
+	return &Provider{
+		cfg: Config{Issuer: testIssuer, ClientID: testClientID},
+		keys: &keySet{
+			keys:      map[string]interface{}{"k1": &key.PublicKey},
+			fetchedAt: time.Now(),
+		},
+	}
+}
+
+// validClaims 一组可以通过校验的声明
+func validClaims() jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":   testIssuer,
+		"aud":   testClientID,
+		"sub":   "user-1",
+		"email": "user@example.com",
+		"nonce": testNonce,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "k1"
+	raw, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider := testProvider(t, key)
+
+	with := func(changes map[string]interface{}) string {
+		claims := validClaims()
+		for name, value := range changes {
+			if value == nil {
+				delete(claims, name)
+				continue
+			}
+			claims[name] = value
+		}
+		return signRS256(t, key, claims)
+	}
+
+	// 用公钥作为HMAC密钥签名，即算法混淆攻击
+	publicDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hmacToken := jwt.NewWithClaims(jwt.SigningMethodHS256, validClaims())
+	hmacToken.Header["kid"] = "k1"
+	hs256, err := hmacToken.SignedString(publicDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+	noneToken := jwt.NewWithClaims(jwt.SigningMethodNone, validClaims())
+	noneToken.Header["kid"] = "k1"
+	none, err := noneToken.SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		raw     string
+		nonce   string
+		wantErr bool
+	}{
+		{"有效令牌", with(nil), testNonce, false},
+		{"受众包含本客户端且azp一致", with(map[string]interface{}{"aud": []string{testClientID, "other"}, "azp": testClientID}), testNonce, false},
+		{"alg为HS256", hs256, testNonce, true},
+		{"alg为none", none, testNonce, true},
+		{"其他密钥签名", signRS256(t, other, validClaims()), testNonce, true},
+		{"受众不是本客户端", with(map[string]interface{}{"aud": "other-client"}), testNonce, true},
+		{"多个受众但azp不一致", with(map[string]interface{}{"aud": []string{testClientID, "other"}, "azp": "other"}), testNonce, true},
+		{"多个受众缺少azp", with(map[string]interface{}{"aud": []string{testClientID, "other"}}), testNonce, true},
+		{"nonce不一致", with(nil), "another-nonce", true},
+		{"令牌缺少nonce", with(map[string]interface{}{"nonce": nil}), testNonce, true},
+		{"发行方不一致", with(map[string]interface{}{"iss": "https://evil.example.com"}), testNonce, true},
+		{"已过期", with(map[string]interface{}{"exp": time.Now().Add(-time.Hour).Unix()}), testNonce, true},
+		{"缺少exp", with(map[string]interface{}{"exp": nil}), testNonce, true},
+		{"缺少sub", with(map[string]interface{}{"sub": nil}), testNonce, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := provider.VerifyIDToken(context.Background(), tt.raw, tt.nonce)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidIDToken) {
+					t.Fatalf("VerifyIDToken() error = %v, want ErrInvalidIDToken", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("VerifyIDToken() error = %v", err)
+			}
+			if claims.Subject != "user-1" || claims.Email != "user@example.com" {
+				t.Errorf("claims = %+v", claims)
+			}
+		})
+	}
+}
@@ -69,6 +69,15 @@ func ValidationError(c *gin.Context, message string) {
 	Error(c, 422, message)
 }
 
+// ErrorWithData 带数据的错误响应（如逐行校验错误）
+func ErrorWithData(c *gin.Context, code int, message string, data interface{}) {
+	c.JSON(http.StatusOK, Response{
+		Code:    code,
+		Message: message,
+		Data:    data,
+	})
+}
+
 // CustomError 自定义错误
 func CustomError(c *gin.Context, code int, message string) {
 	Error(c, code, message)
@@ -0,0 +1,227 @@
+package sentiment
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// LexiconName 内置词典分析器的名称
+const LexiconName = "lexicon"
+
+const (
+	// negationWindow 否定词影响其后的词数
+	negationWindow = 3
+	// negationFactor 被否定的情感词按相反方向计分，强度减弱（"不开心"不如"难过"强烈）
+	negationFactor = -0.75
+	// normalizeAlpha 总分归一化到-1～1的平滑参数，越大越不容易趋近两端
+	normalizeAlpha = 15
+)
+
+// LexiconAnalyzer 基于中英文情感词典的离线分析器：中文按词典正向最大匹配分词，
+// 英文按单词切分；处理否定词和程度副词，按分句累计得分后归一化；未被否定的轻生表述记为高风险命中
+type LexiconAnalyzer struct {
+	maxZhLen int // 中文词典中最长词的字数
+}
+
+// token 切分后的词
+type token struct {
+	text string
+	zh   bool
+}
+
+// NewLexiconAnalyzer 创建词典分析器
+func NewLexiconAnalyzer() *LexiconAnalyzer {
+	analyzer := &LexiconAnalyzer{}
+	for _, dict := range []map[string]float64{zhWords, zhIntensifiers} {
+		for word := range dict {
+			if n := len([]rune(word)); n > analyzer.maxZhLen {
+				analyzer.maxZhLen = n
+			}
+		}
+	}
+	for word := range zhNegators {
+		if n := len([]rune(word)); n > analyzer.maxZhLen {
+			analyzer.maxZhLen = n
+		}
+	}
+	return analyzer
+}
+
+// Name 分析器名称
+func (a *LexiconAnalyzer) Name() string {
+	return LexiconName
+}
+
+// Analyze 分析文本的情感倾向
+func (a *LexiconAnalyzer) Analyze(text string) (Result, error) {
+	var total float64
+	var matched, risk []string
+	var hasZh, hasEn bool
+
+	for _, clause := range splitClauses(text) {
+		negated, window := false, 0
+		boost := 1.0
+		tokens := a.tokenize(clause)
+		for i, tok := range tokens {
+			if tok.zh {
+				hasZh = true
+			} else {
+				hasEn = true
+			}
+
+			if isNegator(tok) {
+				negated, window = !negated, negationWindow
+				continue
+			}
+			if factor, ok := intensifier(tok); ok {
+				boost *= factor
+				continue
+			}
+			if weight, ok := wordWeight(tok); ok {
+				value := weight * boost
+				if negated {
+					value *= negationFactor
+				}
+				total += value
+				matched = append(matched, tok.text)
+				if phrase, ok := riskPhrase(tokens, i); ok && !negated {
+					risk = append(risk, phrase)
+				}
+				negated, window, boost = false, 0, 1
+				continue
+			}
+
+			// 普通词：否定词的影响随距离消退，程度副词只修饰紧随其后的词
+			boost = 1
+			if window > 0 {
+				window--
+				if window == 0 {
+					negated = false
+				}
+			}
+		}
+	}
+
+	score := total / math.Sqrt(total*total+normalizeAlpha)
+	score = math.Round(score*1000) / 1000
+
+	result := Result{Score: score, Label: LabelOf(score), Matched: matched, Risk: risk}
+	switch {
+	case hasZh && hasEn:
+		result.Language = "mixed"
+	case hasZh:
+		result.Language = "zh"
+	case hasEn:
+		result.Language = "en"
+	}
+	return result, nil
+}
+
+// splitClauses 按标点和换行切分句子，否定和程度修饰不跨越分句
+func splitClauses(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		switch r {
+		case '，', '。', '！', '？', '；', '：', '、', ',', '.', '!', '?', ';', ':', '\n', '\r':
+			return true
+		}
+		return false
+	})
+}
+
+// tokenize 切分分句：汉字按词典正向最大匹配（未收录的字单独成词），拉丁字母按单词切分
+func (a *LexiconAnalyzer) tokenize(clause string) []token {
+	var tokens []token
+	runes := []rune(clause)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.Is(unicode.Han, r):
+			length := 1
+			for l := a.maxZhLen; l > 1; l-- {
+				if i+l > len(runes) {
+					continue
+				}
+				if inZhLexicon(string(runes[i : i+l])) {
+					length = l
+					break
+				}
+			}
+			tokens = append(tokens, token{text: string(runes[i : i+length]), zh: true})
+			i += length
+		case unicode.IsLetter(r):
+			j := i
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || runes[j] == '\'' || runes[j] == '’') {
+				j++
+			}
+			word := strings.ToLower(strings.ReplaceAll(string(runes[i:j]), "’", "'"))
+			tokens = append(tokens, token{text: word})
+			i = j
+		default:
+			i++
+		}
+	}
+	return tokens
+}
+
+// inZhLexicon 判断是否为中文词典中的词
+func inZhLexicon(word string) bool {
+	if _, ok := zhWords[word]; ok {
+		return true
+	}
+	if _, ok := zhIntensifiers[word]; ok {
+		return true
+	}
+	return zhNegators[word]
+}
+
+// isNegator 判断是否为否定词，英文包括以n't结尾的缩写
+func isNegator(tok token) bool {
+	if tok.zh {
+		return zhNegators[tok.text]
+	}
+	return enNegators[strings.ReplaceAll(tok.text, "'", "")] || strings.HasSuffix(tok.text, "n't")
+}
+
+// intensifier 程度副词的修饰系数
+func intensifier(tok token) (float64, bool) {
+	if tok.zh {
+		factor, ok := zhIntensifiers[tok.text]
+		return factor, ok
+	}
+	factor, ok := enIntensifiers[tok.text]
+	return factor, ok
+}
+
+// wordWeight 情感词的权重，正数为积极，负数为消极
+func wordWeight(tok token) (float64, bool) {
+	if tok.zh {
+		weight, ok := zhWords[tok.text]
+		return weight, ok
+	}
+	weight, ok := enWords[tok.text]
+	return weight, ok
+}
+
+// riskPhrase 判断第i个词是否为轻生表述，返回命中的表述；英文的部分词需要与后一个词连用
+func riskPhrase(tokens []token, i int) (string, bool) {
+	tok := tokens[i]
+	if tok.zh {
+		return tok.text, zhRiskWords[tok.text]
+	}
+	next, ok := enRiskWords[tok.text]
+	if !ok {
+		return "", false
+	}
+	if next == nil {
+		return tok.text, true
+	}
+	if i+1 < len(tokens) {
+		for _, word := range next {
+			if tokens[i+1].text == word {
+				return tok.text + " " + word, true
+			}
+		}
+	}
+	return "", false
+}
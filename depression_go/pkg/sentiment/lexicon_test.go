@@ -0,0 +1,58 @@
+package sentiment
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+// rawScore 由归一化后的得分还原词典总分，便于比较否定前后的强度
+func rawScore(score float64) float64 {
+	return score * math.Sqrt(normalizeAlpha/(1-score*score))
+}
+
+func TestLexiconAnalyzeNegation(t *testing.T) {
+	analyzer := NewLexiconAnalyzer()
+	tests := []struct {
+		name      string
+		text      string
+		wantLabel string
+		wantRaw   float64
+		wantRisk  []string
+	}{
+		{"中文积极词", "今天很开心", LabelPositive, 2 * 1.3, nil},
+		{"中文否定积极词", "今天不开心", LabelNegative, 2 * negationFactor, nil},
+		{"否定词间隔普通词仍然生效", "我不是很开心", LabelNegative, 2 * 1.3 * negationFactor, nil},
+		{"双重否定抵消", "不是不开心", LabelPositive, 2, nil},
+		{"否定不跨越分句", "不，开心", LabelPositive, 2, nil},
+		{"否定消极词", "没有难过", LabelPositive, -2 * negationFactor, nil},
+		{"否定词超出影响范围", "不 我 们 他 开心", LabelPositive, 2, nil},
+		{"英文否定", "I am not happy", LabelNegative, 2 * negationFactor, nil},
+		{"英文缩写否定", "I don't feel sad", LabelPositive, -2 * negationFactor, nil},
+		{"否定的轻生表述不计入风险", "我不想死", LabelPositive, 0, nil},
+		{"英文否定的轻生表述不计入风险", "I'm not suicidal", "", 0, nil},
+		{"未否定的轻生表述", "我真的想死", LabelNegative, 0, []string{"想死"}},
+		{"英文连用的轻生表述", "I want to kill myself", LabelNegative, 0, []string{"kill myself"}},
+		{"英文单独的kill不是轻生表述", "kill time", "", 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := analyzer.Analyze(tt.text)
+			if err != nil {
+				t.Fatalf("Analyze(%q) error: %v", tt.text, err)
+			}
+			if tt.wantLabel != "" && got.Label != tt.wantLabel {
+				t.Errorf("Label = %q, want %q (score %v)", got.Label, tt.wantLabel, got.Score)
+			}
+			if tt.wantRaw != 0 {
+				// 得分保留三位小数，还原后允许少量误差
+				if raw := rawScore(got.Score); math.Abs(raw-tt.wantRaw) > 0.02 {
+					t.Errorf("raw score = %v, want %v", raw, tt.wantRaw)
+				}
+			}
+			if !reflect.DeepEqual(got.Risk, tt.wantRisk) {
+				t.Errorf("Risk = %v, want %v", got.Risk, tt.wantRisk)
+			}
+		})
+	}
+}
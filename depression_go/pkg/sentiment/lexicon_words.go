@@ -0,0 +1,87 @@
+package sentiment
+
+// 内置情感词典。权重大致在-3.5～2.5之间，与抑郁相关的表述（绝望、无价值感、轻生念头）权重更高
+
+// zhWords 中文情感词
+var zhWords = map[string]float64{
+	// 积极
+	"开心": 2, "快乐": 2, "高兴": 2, "幸福": 2.5, "愉快": 2, "满足": 1.5, "满意": 1.5,
+	"放松": 1.5, "轻松": 1.5, "平静": 1, "安心": 1.5, "舒服": 1.5, "舒心": 1.5, "兴奋": 1.5,
+	"期待": 1.2, "希望": 1, "感激": 2, "感恩": 2, "温暖": 1.5, "喜欢": 1.5, "爱": 1.5,
+	"不错": 1.5, "美好": 2, "顺利": 1.5, "成功": 1.5, "充实": 1.5, "自信": 1.5, "活力": 1.5,
+	"哈哈": 1.5, "笑": 1, "棒": 2, "成就感": 2, "好转": 1.5, "踏实": 1.2, "乐观": 2,
+	"积极": 1.5, "欣慰": 1.5, "睡得好": 1.5, "精力充沛": 2,
+
+	// 消极
+	"难过": -2, "伤心": -2, "悲伤": -2.2, "痛苦": -2.5, "沮丧": -2.2, "失落": -1.8, "抑郁": -2.5,
+	"郁闷": -1.8, "烦": -1.2, "烦躁": -1.8, "焦虑": -2, "紧张": -1.2, "担心": -1.3, "害怕": -1.8,
+	"恐惧": -2, "孤独": -2, "寂寞": -1.8, "无助": -2.5, "绝望": -3, "无望": -2.8, "无聊": -1,
+	"累": -1.2, "疲惫": -1.5, "疲倦": -1.3, "失眠": -1.5, "哭": -1.8, "崩溃": -2.5, "生气": -1.8,
+	"愤怒": -2, "讨厌": -1.8, "后悔": -1.5, "自责": -2, "内疚": -1.8, "空虚": -2, "麻木": -2,
+	"没意思": -1.5, "没意义": -2.2, "没有意义": -2.2, "不好": -1.5, "糟糕": -2, "压力": -1.2,
+	"压抑": -2, "心累": -2, "失望": -2, "委屈": -1.8, "难受": -2, "没用": -2, "一无是处": -2.8,
+	"废物": -2.5, "想死": -3.5, "自杀": -3.5, "不想活": -3.5, "活着没意思": -3.5, "睡不着": -1.5,
+}
+
+// zhNegators 中文否定词
+var zhNegators = map[string]bool{
+	"不": true, "没": true, "没有": true, "无": true, "别": true, "未": true, "并非": true,
+	"不是": true, "毫无": true, "从不": true, "从未": true, "不再": true, "不太": true,
+}
+
+// zhIntensifiers 中文程度副词及修饰系数
+var zhIntensifiers = map[string]float64{
+	"很": 1.3, "非常": 1.6, "特别": 1.5, "十分": 1.5, "极其": 1.8, "极度": 1.8, "超级": 1.6,
+	"太": 1.5, "真": 1.2, "挺": 1.1, "越来越": 1.4, "更加": 1.3, "更": 1.2, "比较": 0.9,
+	"有点": 0.6, "有些": 0.6, "稍微": 0.5, "一点": 0.6,
+}
+
+// enWords 英文情感词
+var enWords = map[string]float64{
+	// 积极
+	"happy": 2, "glad": 1.8, "joy": 2.2, "joyful": 2.2, "great": 2, "good": 1.5, "fine": 0.8,
+	"calm": 1.2, "relaxed": 1.5, "peaceful": 1.5, "grateful": 2, "thankful": 2, "excited": 1.8,
+	"hopeful": 1.8, "hope": 1, "love": 2, "loved": 2, "enjoy": 1.8, "enjoyed": 1.8, "fun": 1.5,
+	"proud": 1.8, "confident": 1.6, "better": 1.2, "wonderful": 2.5, "amazing": 2.5, "awesome": 2.3,
+	"optimistic": 1.8, "energetic": 1.6, "motivated": 1.6, "productive": 1.4, "content": 1.2,
+	"satisfied": 1.5, "smile": 1.3, "smiled": 1.3, "laughed": 1.5, "nice": 1.4, "pleasant": 1.5,
+	"rested": 1.3,
+
+	// 消极
+	"sad": -2, "unhappy": -2, "depressed": -2.8, "depression": -2.5, "miserable": -2.5,
+	"lonely": -2, "alone": -1, "anxious": -2, "anxiety": -2, "worried": -1.6, "worry": -1.4,
+	"stressed": -1.8, "stress": -1.4, "tired": -1.2, "exhausted": -2, "hopeless": -3,
+	"helpless": -2.5, "worthless": -3, "useless": -2.2, "empty": -2, "numb": -2, "cry": -1.8,
+	"cried": -1.8, "crying": -1.8, "angry": -1.8, "upset": -1.8, "hurt": -1.8, "pain": -1.8,
+	"awful": -2.3, "terrible": -2.5, "bad": -1.5, "worse": -1.8, "worst": -2.5, "afraid": -1.8,
+	"scared": -1.8, "fear": -1.8, "guilty": -1.8, "ashamed": -2, "insomnia": -1.5,
+	"sleepless": -1.5, "overwhelmed": -2, "frustrated": -1.8, "hate": -2.3, "suicidal": -3.5,
+	"suicide": -3.5, "die": -2.5, "dead": -2.2, "kill": -3, "failure": -2.2, "failed": -1.8,
+	"lost": -1.2, "bored": -1, "boring": -1.2, "irritable": -1.6, "restless": -1.4,
+}
+
+// zhRiskWords 提示轻生念头的中文表述，未被否定时作为高风险命中
+var zhRiskWords = map[string]bool{
+	"想死": true, "自杀": true, "不想活": true, "活着没意思": true,
+}
+
+// enRiskWords 提示轻生念头的英文表述；值不为空时须紧跟其中一个词才算命中（如kill myself）
+var enRiskWords = map[string][]string{
+	"suicidal": nil, "suicide": nil, "kill": {"myself"},
+}
+
+// enNegators 英文否定词（已去掉撇号）
+var enNegators = map[string]bool{
+	"not": true, "no": true, "never": true, "none": true, "nobody": true, "nothing": true,
+	"neither": true, "nor": true, "without": true, "hardly": true, "barely": true,
+	"cannot": true, "cant": true, "dont": true, "doesnt": true, "didnt": true, "isnt": true,
+	"wasnt": true, "arent": true, "werent": true, "wont": true, "wouldnt": true,
+	"couldnt": true, "shouldnt": true, "aint": true,
+}
+
+// enIntensifiers 英文程度副词及修饰系数
+var enIntensifiers = map[string]float64{
+	"very": 1.3, "really": 1.3, "so": 1.3, "extremely": 1.8, "incredibly": 1.7, "totally": 1.4,
+	"completely": 1.5, "absolutely": 1.5, "too": 1.3, "super": 1.5, "quite": 1.1, "pretty": 1.1,
+	"deeply": 1.5, "slightly": 0.5, "somewhat": 0.7, "little": 0.6, "bit": 0.6, "kinda": 0.7,
+}
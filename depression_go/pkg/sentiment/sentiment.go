@@ -0,0 +1,70 @@
+package sentiment
+
+import (
+	"errors"
+	"fmt"
+)
+
+// 情感倾向
+const (
+	LabelPositive = "positive"
+	LabelNeutral  = "neutral"
+	LabelNegative = "negative"
+)
+
+// labelThreshold 得分绝对值低于该值时视为中性
+const labelThreshold = 0.05
+
+// ErrUnknownAnalyzer 未注册的分析器
+var ErrUnknownAnalyzer = errors.New("未知的情感分析器")
+
+// Result 情感分析结果
+type Result struct {
+	Score    float64  `json:"score"`             // 情感得分，-1（非常消极）到1（非常积极）
+	Label    string   `json:"label"`             // positive, neutral, negative
+	Language string   `json:"language"`          // 文本语言：zh, en, mixed，无法识别时为空
+	Matched  []string `json:"matched,omitempty"` // 命中的情感词
+	Risk     []string `json:"risk,omitempty"`    // 命中的高风险表述（轻生念头），调用方据此进入危机流程
+}
+
+// Analyzer 情感分析器，可替换为其他实现（如调用外部服务）
+type Analyzer interface {
+	// Name 分析器名称，随分析结果保存
+	Name() string
+	// Analyze 分析一段文本的情感倾向
+	Analyze(text string) (Result, error)
+}
+
+// factories 已注册的分析器
+var factories = map[string]func() Analyzer{
+	LexiconName: func() Analyzer { return NewLexiconAnalyzer() },
+}
+
+// Register 注册分析器，name重复时覆盖
+func Register(name string, factory func() Analyzer) {
+	factories[name] = factory
+}
+
+// New 按名称创建分析器，name为空时使用内置的词典分析器
+func New(name string) (Analyzer, error) {
+	if name == "" {
+		name = LexiconName
+	}
+	factory, ok := factories[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownAnalyzer, name)
+	}
+	return factory(), nil
+}
+
+// LabelOf 按得分判断情感倾向
+func LabelOf(score float64) string {
+	switch {
+	case score >= labelThreshold:
+		return LabelPositive
+	case score <= -labelThreshold:
+		return LabelNegative
+	default:
+		return LabelNeutral
+	}
+}
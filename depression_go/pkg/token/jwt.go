@@ -13,26 +13,35 @@ import (
 type Claims struct {
 	UserID   uint   `json:"user_id"`
 	Username string `json:"username"`
+	Role     string `json:"role"`
+	Version  int    `json:"ver"` // 签发时用户的令牌版本，与当前版本不一致时令牌失效
+	Purpose  string `json:"pur,omitempty"` // 令牌用途，访问令牌为空
 	jwt.RegisteredClaims
 }
 
-// GenerateToken 生成JWT令牌
-func GenerateToken(userID uint, username string) (string, error) {
+// PurposeMFA 两步验证令牌：密码已校验、等待输入验证码，只能用于两步验证接口
+const PurposeMFA = "mfa"
+
+// GenerateToken 生成JWT令牌，version为用户当前的令牌版本，sessionID为登录会话ID（写入jti）
+func GenerateToken(userID uint, username, role string, version int, sessionID string) (string, error) {
 	config := configs.GlobalConfig.JWT
 
 	// 设置过期时间
-	expirationTime := time.Now().Add(time.Duration(config.ExpireHours) * time.Hour)
+	expirationTime := time.Now().Add(AccessTTL())
 
 	// 创建声明
 	claims := &Claims{
 		UserID:   userID,
 		Username: username,
+		Role:     role,
+		Version:  version,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 			Issuer:    "depression_ai",
 			Subject:   username,
+			ID:        sessionID, // jti，会话被吊销后令牌失效
 		},
 	}
 
@@ -48,6 +57,34 @@ func GenerateToken(userID uint, username string) (string, error) {
 	return tokenString, nil
 }
 
+// GenerateMFAToken 密码校验通过后签发两步验证令牌，ttl内有效，不关联会话
+func GenerateMFAToken(userID uint, username, role string, version int, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims := &Claims{
+		UserID:   userID,
+		Username: username,
+		Role:     role,
+		Version:  version,
+		Purpose:  PurposeMFA,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Issuer:    "depression_ai",
+			Subject:   username,
+		},
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(configs.GlobalConfig.JWT.Secret))
+}
+
+// AccessTTL 访问令牌有效期，未配置时为15分钟
+func AccessTTL() time.Duration {
+	if minutes := configs.GlobalConfig.JWT.AccessTTLMinutes; minutes > 0 {
+		return time.Duration(minutes) * time.Minute
+	}
+	return 15 * time.Minute
+}
+
 // ParseToken 解析JWT令牌
 func ParseToken(tokenString string) (*Claims, error) {
 	config := configs.GlobalConfig.JWT
@@ -73,10 +110,19 @@ func ParseToken(tokenString string) (*Claims, error) {
 	return nil, errors.New("无效的令牌")
 }
 
-// ValidateToken 验证令牌是否有效
+// ValidateToken 验证访问令牌是否有效，其他用途的令牌视为无效
 func ValidateToken(tokenString string) (bool, *Claims) {
 	claims, err := ParseToken(tokenString)
-	if err != nil {
+	if err != nil || claims.Purpose != "" {
+		return false, nil
+	}
+	return true, claims
+}
+
+// ValidateMFAToken 验证两步验证令牌是否有效
+func ValidateMFAToken(tokenString string) (bool, *Claims) {
+	claims, err := ParseToken(tokenString)
+	if err != nil || claims.Purpose != PurposeMFA {
 		return false, nil
 	}
 	return true, claims
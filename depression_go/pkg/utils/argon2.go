@@ -0,0 +1,136 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+
+	"depression_go/configs"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	argon2Prefix    = "$argon2id$"
+	argon2SaltBytes = 16
+	argon2KeyBytes  = 32
+)
+
+// argon2Params argon2id的计算参数，内存单位为KiB
+type argon2Params struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+}
+
+// currentArgon2Params 当前配置的参数，未加载配置时使用默认值（64MiB、3次迭代、2线程）
+func currentArgon2Params() argon2Params {
+	params := argon2Params{Memory: 64 * 1024, Iterations: 3, Parallelism: 2}
+	if configs.GlobalConfig == nil {
+		return params
+	}
+	cfg := configs.GlobalConfig.Password
+	if cfg.Argon2MemoryKB > 0 {
+		params.Memory = uint32(cfg.Argon2MemoryKB)
+	}
+	if cfg.Argon2Iterations > 0 {
+		params.Iterations = uint32(cfg.Argon2Iterations)
+	}
+	if cfg.Argon2Parallelism > 0 && cfg.Argon2Parallelism <= 255 {
+		params.Parallelism = uint8(cfg.Argon2Parallelism)
+	}
+	return params
+}
+
+var (
+	argon2SlotsOnce sync.Once
+	argon2Slots     chan struct{}
+)
+
+// argon2Key 计算argon2id密钥；同时计算的数量受ARGON2_MAX_CONCURRENT限制，超出的排队等待，
+// 避免大量未认证的登录、注册请求同时占用内存
+func argon2Key(password string, salt []byte, params argon2Params, keyLen uint32) []byte {
+	argon2SlotsOnce.Do(func() {
+		limit := 4
+		if configs.GlobalConfig != nil && configs.GlobalConfig.Password.Argon2MaxConcurrent > 0 {
+			limit = configs.GlobalConfig.Password.Argon2MaxConcurrent
+		}
+		argon2Slots = make(chan struct{}, limit)
+	})
+	argon2Slots <- struct{}{}
+	defer func() { <-argon2Slots }()
+	return argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, keyLen)
+}
+
+// hashArgon2id 生成PHC格式的哈希：$argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>
+func hashArgon2id(password string, params argon2Params) (string, error) {
+	salt := make([]byte, argon2SaltBytes)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	return encodeArgon2id(password, salt, params), nil
+}
+
+// encodeArgon2id 用给定的盐计算并编码为PHC格式
+func encodeArgon2id(password string, salt []byte, params argon2Params) string {
+	key := argon2Key(password, salt, params, argon2KeyBytes)
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2Prefix, argon2.Version, params.Memory, params.Iterations, params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	)
+}
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     string
+)
+
+// DummyPasswordHash 按当前参数生成的固定哈希，账户不存在时用它校验密码，使响应耗时与真实账户一致
+func DummyPasswordHash() string {
+	dummyHashOnce.Do(func() {
+		dummyHash = encodeArgon2id("dummy-password", make([]byte, argon2SaltBytes), currentArgon2Params())
+	})
+	return dummyHash
+}
+
+// checkArgon2id 按哈希中记录的参数重新计算并比较
+func checkArgon2id(password, encoded string) bool {
+	params, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return false
+	}
+	computed := argon2Key(password, salt, params, uint32(len(key)))
+	return subtle.ConstantTimeCompare(computed, key) == 1
+}
+
+// decodeArgon2id 解析PHC格式的argon2id哈希
+func decodeArgon2id(encoded string) (argon2Params, []byte, []byte, error) {
+	var params argon2Params
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return params, nil, nil, fmt.Errorf("不是argon2id哈希")
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, fmt.Errorf("不支持的argon2版本: %s", parts[2])
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return params, nil, nil, fmt.Errorf("argon2参数格式错误: %w", err)
+	}
+	if params.Iterations == 0 || params.Parallelism == 0 {
+		return params, nil, nil, fmt.Errorf("argon2参数无效: %s", parts[3])
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, err
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, fmt.Errorf("argon2哈希格式错误")
+	}
+	return params, salt, key, nil
+}
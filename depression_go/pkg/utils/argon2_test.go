@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestDecodeArgon2id(t *testing.T) {
+	cheap := argon2Params{Memory: 1024, Iterations: 1, Parallelism: 1}
+	valid := encodeArgon2id("secret", make([]byte, argon2SaltBytes), cheap)
+
+	tests := []struct {
+		name       string
+		encoded    string
+		wantErr    bool
+		wantParams argon2Params
+	}{
+		{"有效哈希", valid, false, cheap},
+		{"参数按哈希中记录的解析", "$argon2id$v=19$m=65536,t=3,p=2$c2FsdHNhbHRzYWx0c2FsdA$a2V5a2V5a2V5a2V5", false, argon2Params{Memory: 65536, Iterations: 3, Parallelism: 2}},
+		{"不是argon2id", "$argon2i$v=19$m=1024,t=1,p=1$c2FsdA$a2V5", true, argon2Params{}},
+		{"段数不对", "$argon2id$v=19$m=1024,t=1,p=1$c2FsdA", true, argon2Params{}},
+		{"不支持的版本", "$argon2id$v=16$m=1024,t=1,p=1$c2FsdA$a2V5", true, argon2Params{}},
+		{"参数格式错误", "$argon2id$v=19$memory=1024$c2FsdA$a2V5", true, argon2Params{}},
+		{"迭代次数为0", "$argon2id$v=19$m=1024,t=0,p=1$c2FsdA$a2V5", true, argon2Params{}},
+		{"线程数为0", "$argon2id$v=19$m=1024,t=1,p=0$c2FsdA$a2V5", true, argon2Params{}},
+		{"盐不是base64", "$argon2id$v=19$m=1024,t=1,p=1$!!!$a2V5", true, argon2Params{}},
+		{"密钥为空", "$argon2id$v=19$m=1024,t=1,p=1$c2FsdA$", true, argon2Params{}},
+		{"bcrypt哈希", "$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy", true, argon2Params{}},
+		{"空字符串", "", true, argon2Params{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, salt, key, err := decodeArgon2id(tt.encoded)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeArgon2id() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if params != tt.wantParams {
+				t.Errorf("params = %+v, want %+v", params, tt.wantParams)
+			}
+			if len(salt) == 0 || len(key) == 0 {
+				t.Errorf("salt/key 为空: %d/%d 字节", len(salt), len(key))
+			}
+		})
+	}
+}
+
+func TestNeedsRehash(t *testing.T) {
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	current := encodeArgon2id("secret", make([]byte, argon2SaltBytes), currentArgon2Params())
+	older := encodeArgon2id("secret", make([]byte, argon2SaltBytes), argon2Params{Memory: 1024, Iterations: 1, Parallelism: 1})
+
+	tests := []struct {
+		name    string
+		encoded string
+		want    bool
+	}{
+		{"当前参数的argon2id", current, false},
+		{"旧参数的argon2id", older, true},
+		{"bcrypt", string(bcryptHash), true},
+		{"无法解析", "not-a-hash", true},
+		{"用户不存在时比对的固定哈希", DummyPasswordHash(), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NeedsRehash(tt.encoded); got != tt.want {
+				t.Errorf("NeedsRehash() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// 旧格式的哈希仍能校验通过，升级前后的密码都可以登录
+	for _, encoded := range []string{string(bcryptHash), older, current} {
+		if !CheckPassword("secret", encoded) {
+			t.Errorf("CheckPassword(%q) = false, want true", encoded)
+		}
+		if CheckPassword("wrong", encoded) {
+			t.Errorf("CheckPassword(wrong, %q) = true, want false", encoded)
+		}
+	}
+}
@@ -1,35 +1,41 @@
 package utils
 
 import (
+	"strings"
+
 	"golang.org/x/crypto/bcrypt"
 )
 
 // HashPassword 密码加密
 func HashPassword(password string) (string, error) {
-	// 使用bcrypt进行密码加密，成本因子为12
-	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(password), 12)
-	if err != nil {
-		return "", err
-	}
-	return string(hashedBytes), nil
+	// 使用argon2id进行密码加密，参数见PasswordConfig；不像bcrypt那样截断72字节以后的内容
+	return hashArgon2id(password, currentArgon2Params())
 }
 
-// CheckPassword 验证密码
+// CheckPassword 验证密码，兼容升级前的bcrypt哈希
 func CheckPassword(password, hashedPassword string) bool {
+	if strings.HasPrefix(hashedPassword, argon2Prefix) {
+		return checkArgon2id(password, hashedPassword)
+	}
 	// 比较密码和哈希值
 	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
 	return err == nil
 }
 
-// ValidatePassword 验证密码强度
-func ValidatePassword(password string) (bool, string) {
-	if len(password) < 6 {
-		return false, "密码长度至少6位"
+// NeedsRehash 哈希是否需要升级：bcrypt哈希，或argon2id参数与当前配置不同；登录成功时用明文重新加密
+func NeedsRehash(hashedPassword string) bool {
+	params, _, _, err := decodeArgon2id(hashedPassword)
+	if err != nil {
+		return true
 	}
-	
-	if len(password) > 50 {
-		return false, "密码长度不能超过50位"
+	return params != currentArgon2Params()
+}
+
+// ValidatePassword 验证密码强度，userInputs为用户名、邮箱等不能出现在密码中的信息
+func ValidatePassword(password string, userInputs ...string) (bool, string) {
+	if msg := checkPasswordPolicy(passwordPolicy(), password, userInputs); msg != "" {
+		return false, msg
 	}
 	
 	return true, ""
-} 
\ No newline at end of file
+}
\ No newline at end of file
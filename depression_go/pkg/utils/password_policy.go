@@ -0,0 +1,139 @@
+package utils
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"depression_go/configs"
+)
+
+// commonPasswords 内置的常见弱密码列表（小写，每行一个）
+//
+//go:embed common_passwords.txt
+var commonPasswords string
+
+var (
+	blocklistOnce sync.Once
+	blocklist     map[string]struct{}
+)
+
+// passwordPolicy 当前配置的密码策略，未加载配置时使用默认值
+func passwordPolicy() configs.PasswordConfig {
+	policy := configs.PasswordConfig{
+		MinLength:      8,
+		MaxLength:      128,
+		MinClasses:     2,
+		RejectUserInfo: true,
+		Blocklist:      true,
+	}
+	if configs.GlobalConfig != nil {
+		policy = configs.GlobalConfig.Password
+	}
+	return policy
+}
+
+// checkPasswordPolicy 按策略检查密码，不符合时返回原因
+func checkPasswordPolicy(policy configs.PasswordConfig, password string, userInputs []string) string {
+	length := utf8.RuneCountInString(password)
+	if length < policy.MinLength {
+		return fmt.Sprintf("密码长度至少%d位", policy.MinLength)
+	}
+	if policy.MaxLength > 0 && length > policy.MaxLength {
+		return fmt.Sprintf("密码长度不能超过%d位", policy.MaxLength)
+	}
+	if policy.MinClasses > 1 && characterClasses(password) < policy.MinClasses {
+		return fmt.Sprintf("密码需要包含小写字母、大写字母、数字、符号中的至少%d种", policy.MinClasses)
+	}
+
+	lower := strings.ToLower(password)
+	if policy.RejectUserInfo {
+		for _, input := range userInputs {
+			input = strings.ToLower(strings.TrimSpace(input))
+			candidates := []string{input}
+			// 邮箱同时检查@前的部分
+			if at := strings.LastIndex(input, "@"); at > 0 {
+				candidates = append(candidates, input[:at])
+			}
+			for _, candidate := range candidates {
+				if utf8.RuneCountInString(candidate) >= 3 && strings.Contains(lower, candidate) {
+					return "密码不能包含用户名或邮箱"
+				}
+			}
+		}
+	}
+	if policy.Blocklist && isCommonPassword(lower, policy.BlocklistFile) {
+		return "密码过于常见，请换一个更难猜的密码"
+	}
+	return ""
+}
+
+// characterClasses 密码包含的字符类别数：小写字母、大写字母、数字、其他符号
+func characterClasses(password string) int {
+	var lower, upper, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+	count := 0
+	for _, present := range []bool{lower, upper, digit, symbol} {
+		if present {
+			count++
+		}
+	}
+	return count
+}
+
+// isCommonPassword 是否为常见弱密码；去掉末尾的数字和符号后仍在列表中（如password2024!）也视为弱密码
+func isCommonPassword(lower, extraFile string) bool {
+	blocklistOnce.Do(func() {
+		blocklist = make(map[string]struct{})
+		addBlocklistWords(strings.NewReader(commonPasswords))
+		if extraFile == "" {
+			return
+		}
+		file, err := os.Open(extraFile)
+		if err != nil {
+			log.Printf("读取弱密码列表失败: %v", err)
+			return
+		}
+		defer file.Close()
+		addBlocklistWords(file)
+	})
+
+	if _, ok := blocklist[lower]; ok {
+		return true
+	}
+	base := strings.TrimRightFunc(lower, func(r rune) bool { return !unicode.IsLetter(r) })
+	if base != lower && utf8.RuneCountInString(base) >= 4 {
+		_, ok := blocklist[base]
+		return ok
+	}
+	return false
+}
+
+// addBlocklistWords 按行读取弱密码，忽略空行和#开头的注释
+func addBlocklistWords(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		word := strings.ToLower(strings.TrimSpace(scanner.Text()))
+		if word != "" && !strings.HasPrefix(word, "#") {
+			blocklist[word] = struct{}{}
+		}
+	}
+}
@@ -2,6 +2,7 @@ package routes
 
 import (
 	"depression_go/handlers"
+	"depression_go/internal/models"
 	"depression_go/middleware"
 
 	"github.com/gin-gonic/gin"
@@ -14,6 +15,21 @@ func SetupRoutes(r *gin.Engine) {
 	faceDetectionHandler := handlers.NewFaceDetectionHandler()
 	questionnaireHandler := handlers.NewQuestionnaireHandler()
 	resultHandler := handlers.NewResultHandler()
+	assessmentSessionHandler := handlers.NewAssessmentSessionHandler()
+	adminQuestionHandler := handlers.NewAdminQuestionHandler()
+	adminAssessmentHandler := handlers.NewAdminAssessmentHandler()
+	adminTranslationHandler := handlers.NewAdminTranslationHandler()
+	adminScoringHandler := handlers.NewAdminScoringHandler()
+	adminRiskEventHandler := handlers.NewAdminRiskEventHandler()
+	journalHandler := handlers.NewJournalHandler()
+	checkinHandler := handlers.NewCheckinHandler()
+	trendHandler := handlers.NewTrendHandler()
+	sessionHandler := handlers.NewSessionHandler()
+	accountHandler := handlers.NewAccountHandler()
+	mfaHandler := handlers.NewMFAHandler()
+	oidcHandler := handlers.NewOIDCHandler()
+	accountDeletionHandler := handlers.NewAccountDeletionHandler()
+	adminUserHandler := handlers.NewAdminUserHandler()
 
 	// API版本组
 	api := r.Group("/api/v1")
@@ -26,6 +42,30 @@ func SetupRoutes(r *gin.Engine) {
 		{
 			auth.POST("/register", authHandler.Register)
 			auth.POST("/login", authHandler.Login)
+			//用刷新令牌换取新令牌
+			auth.POST("/refresh", authHandler.Refresh)
+			//验证邮箱（令牌来自验证邮件）
+			auth.POST("/verify-email", accountHandler.VerifyEmail)
+			//找回密码：发送重置邮件，用邮件中的令牌设置新密码
+			auth.POST("/password/forgot", accountHandler.ForgotPassword)
+			auth.POST("/password/reset", accountHandler.ResetPassword)
+			//单点登录：列出提供方、获取授权地址、回调后用code和state换取令牌
+			auth.GET("/oidc/providers", oidcHandler.ListProviders)
+			auth.GET("/oidc/:provider/authorize", oidcHandler.Authorize)
+			auth.POST("/oidc/:provider/callback", oidcHandler.Callback)
+		}
+
+		//查询删除回执（账户删除后无法登录，凭回执编号查询）
+		public.GET("/deletion-receipts/:id", accountDeletionHandler.GetReceipt)
+
+		// 登录第二步，使用登录时返回的两步验证令牌
+		mfaLogin := public.Group("/auth/mfa", middleware.MFAPendingMiddleware())
+		{
+			//校验验证码或恢复码，通过后签发令牌
+			mfaLogin.POST("/verify", mfaHandler.VerifyLogin)
+			//角色要求两步验证而尚未启用时，先绑定再登录
+			mfaLogin.POST("/setup", mfaHandler.Setup)
+			mfaLogin.POST("/confirm", mfaHandler.ConfirmLogin)
 		}
 
 		// 问题相关（公开访问）
@@ -45,6 +85,32 @@ func SetupRoutes(r *gin.Engine) {
 		{
 			//获取用户信息
 			user.GET("/profile", authHandler.GetProfile)
+			//注销，吊销当前令牌
+			user.POST("/logout", authHandler.Logout)
+			//已登录的设备，移除指定会话或除当前会话外的全部会话
+			user.GET("/sessions", sessionHandler.ListSessions)
+			user.DELETE("/sessions/:id", sessionHandler.RevokeSession)
+			user.DELETE("/sessions", sessionHandler.RevokeOtherSessions)
+			//更新用户信息
+			user.PUT("/profile", authHandler.UpdateProfile)
+			//上传头像
+			user.POST("/avatar", authHandler.UploadAvatar)
+			//修改密码，其他设备上的登录失效
+			user.POST("/password", authHandler.ChangePassword)
+			//设置语言偏好
+			user.PUT("/locale", authHandler.UpdateLocale)
+			//重新发送邮箱验证邮件
+			user.POST("/verify-email", accountHandler.ResendVerification)
+			//两步验证：状态、绑定、关闭、重新生成恢复码
+			user.GET("/mfa", mfaHandler.GetStatus)
+			user.POST("/mfa/setup", mfaHandler.Setup)
+			user.POST("/mfa/confirm", mfaHandler.Confirm)
+			user.POST("/mfa/disable", mfaHandler.Disable)
+			user.POST("/mfa/recovery-codes", mfaHandler.RegenerateRecoveryCodes)
+			//注销账户：查询、申请（等待期后删除全部数据）、撤销
+			user.GET("/deletion", accountDeletionHandler.GetDeletion)
+			user.POST("/deletion", accountDeletionHandler.RequestDeletion)
+			user.DELETE("/deletion", accountDeletionHandler.CancelDeletion)
 		}
 
 		// 人脸检测相关
@@ -56,6 +122,31 @@ func SetupRoutes(r *gin.Engine) {
 			face.GET("/history", faceDetectionHandler.GetDetectionHistory)
 		}
 
+		// 心情日记
+		journal := protected.Group("/journal")
+		{
+			//写日记，返回情感分析结果
+			journal.POST("", journalHandler.CreateEntry)
+			journal.GET("", journalHandler.ListEntries)
+			journal.GET("/:id", journalHandler.GetEntry)
+			journal.PUT("/:id", journalHandler.UpdateEntry)
+			journal.DELETE("/:id", journalHandler.DeleteEntry)
+		}
+
+		// 每日打卡
+		checkin := protected.Group("/checkin")
+		{
+			//打卡题目（心情、睡眠、精力）
+			checkin.GET("/items", checkinHandler.GetItems)
+			checkin.POST("", checkinHandler.Submit)
+			//按天汇总
+			checkin.GET("/daily", checkinHandler.GetDaily)
+			//连续打卡天数
+			checkin.GET("/streak", checkinHandler.GetStreak)
+			//趋势
+			checkin.GET("/trend", checkinHandler.GetTrend)
+		}
+
 		// 问卷相关
 		questionnaire := protected.Group("/questionnaire")
 		{
@@ -68,7 +159,115 @@ func SetupRoutes(r *gin.Engine) {
 		{
 			//创建评估
 			assessment.POST("", resultHandler.CreateAssessment)
+			//综合评估（/total为旧路径，保留兼容）
+			assessment.GET("/combined", resultHandler.GetCombinedResult)
+			assessment.POST("/combined", resultHandler.SaveCombinedResult)
 			assessment.GET("/total", resultHandler.GetCombinedResult)
+			//评估历史（分页，可按类型、等级、日期筛选）
+			assessment.GET("/history", resultHandler.GetHistory)
+			//评估详情，含答案及题目
+			assessment.GET("/history/:id", resultHandler.GetHistoryDetail)
+			//纵向趋势及可靠变化
+			assessment.GET("/trends", trendHandler.GetTrends)
+			assessment.GET("/trends/compare", trendHandler.CompareAssessments)
+			//未完成的评估草稿
+			assessment.GET("/drafts", assessmentSessionHandler.ListDrafts)
+			//恢复作答：获取草稿及已保存答案
+			assessment.GET("/:id/session", assessmentSessionHandler.GetSession)
+			//评估所用问卷版本的题目
+			assessment.GET("/:id/questions", assessmentSessionHandler.GetQuestions)
+			//自适应测验：获取下一题
+			assessment.GET("/:id/next", assessmentSessionHandler.NextQuestion)
+			//逐题保存答案
+			assessment.PATCH("/:id/answers/:question_id", assessmentSessionHandler.SaveAnswer)
+			//完成评估并计分
+			assessment.POST("/:id/finalize", assessmentSessionHandler.Finalize)
+		}
+
+		// 管理接口，按角色的权限控制访问
+		admin := protected.Group("/admin")
+		{
+			// 题库管理
+			adminQuestions := admin.Group("/questions", middleware.RequirePermission(models.PermQuestionManage))
+			{
+				adminQuestions.GET("", adminQuestionHandler.ListQuestions)
+				adminQuestions.POST("", adminQuestionHandler.CreateQuestion)
+				//批量调整排序
+				adminQuestions.PUT("/order", adminQuestionHandler.ReorderQuestions)
+				//导入导出（json/csv/fhir）
+				adminQuestions.GET("/export", adminQuestionHandler.ExportQuestions)
+				adminQuestions.POST("/import", adminQuestionHandler.ImportQuestions)
+				adminQuestions.PUT("/:id", adminQuestionHandler.UpdateQuestion)
+				//启用/禁用
+				adminQuestions.PATCH("/:id/status", adminQuestionHandler.SetQuestionStatus)
+				adminQuestions.DELETE("/:id", adminQuestionHandler.DeleteQuestion)
+				adminQuestions.POST("/:id/restore", adminQuestionHandler.RestoreQuestion)
+				//题目翻译
+				adminQuestions.GET("/:id/translations", adminTranslationHandler.ListQuestionTranslations)
+				adminQuestions.PUT("/:id/translations/:locale", adminTranslationHandler.SaveQuestionTranslation)
+				adminQuestions.DELETE("/:id/translations/:locale", adminTranslationHandler.DeleteQuestionTranslation)
+			}
+
+			questionManage := admin.Group("", middleware.RequirePermission(models.PermQuestionManage))
+			{
+				// 问卷版本
+				questionManage.GET("/questionnaire-versions", adminQuestionHandler.ListVersions)
+				questionManage.GET("/questionnaire-versions/:id", adminQuestionHandler.GetVersion)
+
+				// 结果文本（按语言覆盖内置文本）
+				questionManage.GET("/result-texts", adminTranslationHandler.ListResultTexts)
+				questionManage.PUT("/result-texts/:locale/:key", adminTranslationHandler.SaveResultText)
+				questionManage.DELETE("/result-texts/:locale/:key", adminTranslationHandler.DeleteResultText)
+			}
+
+			// 计分方案（等级划分及文本，修改时发布新版本）
+			scoring := admin.Group("/scoring-profiles", middleware.RequirePermission(models.PermScoringManage))
+			{
+				scoring.GET("", adminScoringHandler.ListProfiles)
+				scoring.GET("/current", adminScoringHandler.GetCurrentProfile)
+				scoring.GET("/:id", adminScoringHandler.GetProfile)
+				scoring.POST("", adminScoringHandler.PublishProfile)
+			}
+
+			// 用户与角色管理
+			adminUsers := admin.Group("/users")
+			{
+				adminUsers.GET("", middleware.RequirePermission(models.PermUserRead), adminUserHandler.ListUsers)
+				adminUsers.GET("/:id", middleware.RequirePermission(models.PermUserRead), adminUserHandler.GetUser)
+				adminUsers.PUT("/:id/role", middleware.RequirePermission(models.PermUserManage), adminUserHandler.SetUserRole)
+				//启用/禁用，禁用后该用户的登录立即失效
+				adminUsers.PATCH("/:id/status", middleware.RequirePermission(models.PermUserManage), adminUserHandler.SetUserStatus)
+				//解除登录失败导致的锁定
+				adminUsers.POST("/:id/unlock", middleware.RequirePermission(models.PermUserManage), adminUserHandler.UnlockUser)
+				//重置两步验证（用户丢失身份验证器和恢复码时）
+				adminUsers.DELETE("/:id/mfa", middleware.RequirePermission(models.PermUserManage), adminUserHandler.ResetUserMFA)
+				//代为办理删除请求（可立即删除）、撤销注销申请
+				adminUsers.POST("/:id/deletion", middleware.RequirePermission(models.PermUserManage), adminUserHandler.DeleteUser)
+				adminUsers.DELETE("/:id/deletion", middleware.RequirePermission(models.PermUserManage), adminUserHandler.CancelUserDeletion)
+				// 用户纵向趋势（供咨询师查看）
+				adminUsers.GET("/:id/trends", middleware.RequirePermission(models.PermTrendRead), trendHandler.GetUserTrends)
+			}
+			admin.GET("/roles", middleware.RequirePermission(models.PermUserManage), adminUserHandler.ListRoles)
+			//设置角色是否要求两步验证
+			admin.PUT("/roles/:name/mfa", middleware.RequirePermission(models.PermUserManage), adminUserHandler.SetRoleMFA)
+			//注销申请列表、已删除数据的匿名汇总
+			admin.GET("/deletions", middleware.RequirePermission(models.PermUserManage), adminUserHandler.ListDeletions)
+			admin.GET("/erased-stats", middleware.RequirePermission(models.PermUserRead), adminUserHandler.ErasedDataStats)
+
+			// 评估管理：按原问卷版本重新计分
+			admin.POST("/assessments/:id/rescore", middleware.RequirePermission(models.PermAssessmentRescore), adminAssessmentHandler.RescoreAssessment)
+
+			// 风险事件：查看未解决和未送达通知的事件，接手或解决
+			riskEvents := admin.Group("/risk-events", middleware.RequirePermission(models.PermRiskManage))
+			{
+				riskEvents.GET("", adminRiskEventHandler.ListRiskEvents)
+				riskEvents.PATCH("/:id/status", adminRiskEventHandler.SetRiskEventStatus)
+			}
+
+			// 审计日志
+			admin.GET("/audit-logs", middleware.RequirePermission(models.PermAuditRead), adminQuestionHandler.GetAuditLogs)
+			// 登录记录
+			admin.GET("/login-attempts", middleware.RequirePermission(models.PermAuditRead), adminUserHandler.ListLoginAttempts)
 		}
 	}
 
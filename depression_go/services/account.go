@@ -0,0 +1,306 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"depression_go/configs"
+	"depression_go/internal/models"
+	"depression_go/pkg/i18n"
+	"depression_go/pkg/mailer"
+	"depression_go/pkg/utils"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidUserToken     = errors.New("链接无效或已过期")
+	ErrEmailAlreadyVerified = errors.New("邮箱已验证")
+	ErrMailTooFrequent      = errors.New("邮件发送过于频繁，请稍后再试")
+)
+
+// mailResendInterval 同一用户同一用途的邮件最短发送间隔
+const mailResendInterval = time.Minute
+
+// NewMailer 根据配置创建邮件发送器
+func NewMailer(cfg configs.MailConfig) mailer.Mailer {
+	switch cfg.Driver {
+	case "smtp":
+		if cfg.SMTPHost != "" {
+			return mailer.NewSMTPMailer(mailer.SMTPConfig{
+				Host:     cfg.SMTPHost,
+				Port:     cfg.SMTPPort,
+				Username: cfg.SMTPUsername,
+				Password: cfg.SMTPPassword,
+				Security: cfg.SMTPSecurity,
+				From:     cfg.From,
+			})
+		}
+		log.Println("SMTP_HOST未配置，邮件改为写入日志")
+	case "file":
+		return mailer.NewFileMailer(cfg.Dir, cfg.From)
+	}
+	return mailer.LogMailer{}
+}
+
+// AccountService 账户邮件服务：邮箱验证和找回密码，链接中的令牌一次性有效
+type AccountService struct {
+	db       *gorm.DB
+	mailer   mailer.Mailer
+	tokens   *TokenService
+	lockouts *LockoutService
+	cfg      configs.MailConfig
+}
+
+// NewAccountService 创建账户邮件服务
+func NewAccountService(db *gorm.DB) *AccountService {
+	cfg := configs.MailConfig{
+		From:            "noreply@localhost",
+		AppName:         "抑郁倾向检测系统",
+		LinkBaseURL:     "http://localhost:8088",
+		VerifyTTLHours:  24,
+		ResetTTLMinutes: 30,
+	}
+	if configs.GlobalConfig != nil {
+		cfg = configs.GlobalConfig.Mail
+	}
+	return &AccountService{
+		db:       db,
+		mailer:   NewMailer(cfg),
+		tokens:   NewTokenService(db),
+		lockouts: NewLockoutService(db),
+		cfg:      cfg,
+	}
+}
+
+// SetMailer 替换邮件发送器
+func (s *AccountService) SetMailer(m mailer.Mailer) {
+	s.mailer = m
+}
+
+// SendVerification 发送邮箱验证邮件，之前未使用的验证链接随即失效
+func (s *AccountService) SendVerification(user *models.User, locale string) error {
+	if user.EmailVerifiedAt != nil {
+		return ErrEmailAlreadyVerified
+	}
+	ttl := time.Duration(s.cfg.VerifyTTLHours) * time.Hour
+	raw, err := s.createToken(user, models.TokenPurposeVerifyEmail, ttl)
+	if err != nil {
+		return err
+	}
+	return s.send(user, locale, mailer.TemplateVerifyEmail, "/verify-email", raw, ttl)
+}
+
+// VerifyEmail 用验证链接中的令牌确认邮箱
+func (s *AccountService) VerifyEmail(raw string) (*models.User, error) {
+	var user models.User
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		token, err := useToken(tx, raw, models.TokenPurposeVerifyEmail)
+		if err != nil {
+			return err
+		}
+		if err := tx.First(&user, token.UserID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvalidUserToken
+			}
+			return err
+		}
+		if !strings.EqualFold(user.Email, token.Email) {
+			return ErrInvalidUserToken
+		}
+		if user.EmailVerifiedAt != nil {
+			return nil
+		}
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+		return tx.Model(&user).Update("email_verified_at", now).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// RequestPasswordReset 向邮箱发送重置密码邮件；邮箱不存在或账户被禁用时不发送也不报错，避免据此判断邮箱是否注册
+func (s *AccountService) RequestPasswordReset(email, locale string) error {
+	var user models.User
+	if err := s.db.Where("email = ?", email).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if user.Status == 0 {
+		return nil
+	}
+	if preferred := i18n.Normalize(user.Locale); preferred != "" {
+		locale = preferred
+	}
+	// 发送频繁或失败时同样不报错，只记录日志
+	if err := s.sendReset(&user, locale); err != nil && !errors.Is(err, ErrMailTooFrequent) {
+		log.Printf("发送重置密码邮件失败(用户%d): %v", user.ID, err)
+	}
+	return nil
+}
+
+// ResetPassword 用重置链接中的令牌设置新密码；同时使该用户的所有登录失效、清除登录锁定
+func (s *AccountService) ResetPassword(raw, newPassword string) error {
+	var user models.User
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		token, err := useToken(tx, raw, models.TokenPurposeResetPassword)
+		if err != nil {
+			return err
+		}
+		if err := tx.First(&user, token.UserID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvalidUserToken
+			}
+			return err
+		}
+		if user.Status == 0 {
+			return ErrUserDisabled
+		}
+		// 密码不符合要求时回滚，令牌仍可使用
+		if valid, msg := utils.ValidatePassword(newPassword, user.Username, user.Email); !valid {
+			return fmt.Errorf("%w: %s", ErrWeakPassword, msg)
+		}
+		hashedPassword, err := utils.HashPassword(newPassword)
+		if err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{
+			"password":        hashedPassword,
+			"random_password": false,
+			"token_version":   gorm.Expr("token_version + 1"),
+		}
+		// 能收到重置邮件说明邮箱属于该用户
+		if user.EmailVerifiedAt == nil && strings.EqualFold(user.Email, token.Email) {
+			updates["email_verified_at"] = time.Now()
+		}
+		if err := tx.Model(&user).Updates(updates).Error; err != nil {
+			return err
+		}
+		if err := expireTokens(tx, user.ID, models.TokenPurposeResetPassword); err != nil {
+			return err
+		}
+		return s.tokens.RevokeUser(tx, user.ID, models.RevokeReasonPassword)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.lockouts.RecordSuccess(AccountKey(&user, ""))
+	return nil
+}
+
+// sendReset 生成重置令牌并发送邮件
+func (s *AccountService) sendReset(user *models.User, locale string) error {
+	ttl := time.Duration(s.cfg.ResetTTLMinutes) * time.Minute
+	raw, err := s.createToken(user, models.TokenPurposeResetPassword, ttl)
+	if err != nil {
+		return err
+	}
+	return s.send(user, locale, mailer.TemplateResetPassword, "/reset-password", raw, ttl)
+}
+
+// createToken 生成一次性令牌，同一用途之前未使用的令牌随即失效；距上次发送不足mailResendInterval时返回ErrMailTooFrequent
+func (s *AccountService) createToken(user *models.User, purpose string, ttl time.Duration) (string, error) {
+	raw, err := newRandomToken()
+	if err != nil {
+		return "", err
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var recent int64
+		if err := tx.Model(&models.UserToken{}).
+			Where("user_id = ? AND purpose = ? AND created_at > ?", user.ID, purpose, time.Now().Add(-mailResendInterval)).
+			Count(&recent).Error; err != nil {
+			return err
+		}
+		if recent > 0 {
+			return ErrMailTooFrequent
+		}
+		if err := expireTokens(tx, user.ID, purpose); err != nil {
+			return err
+		}
+		return tx.Create(&models.UserToken{
+			UserID:    user.ID,
+			Purpose:   purpose,
+			TokenHash: hashToken(raw),
+			Email:     user.Email,
+			ExpiresAt: time.Now().Add(ttl),
+		}).Error
+	})
+	return raw, err
+}
+
+// send 渲染模板并发送邮件，链接为LinkBaseURL+path?token=令牌
+func (s *AccountService) send(user *models.User, locale, template, path, raw string, ttl time.Duration) error {
+	return s.SendNotice(user.Email, user.Username, locale, template, mailer.TemplateData{
+		Link:           s.Link(path, "token", raw),
+		ExpiresHours:   int(ttl.Hours()),
+		ExpiresMinutes: int(ttl.Minutes()),
+	})
+}
+
+// SendNotice 渲染模板并发送邮件，AppName和Username由本方法填写；用户可能已被删除，所以直接传邮箱和用户名
+func (s *AccountService) SendNotice(email, username, locale, template string, data mailer.TemplateData) error {
+	if locale = i18n.Normalize(locale); locale == "" {
+		locale = i18n.Default
+	}
+	data.AppName = s.cfg.AppName
+	data.Username = username
+	subject, body, err := mailer.Render(template, locale, data)
+	if err != nil {
+		return err
+	}
+	if err := s.mailer.Send(mailer.Message{To: email, Subject: subject, Body: body}); err != nil {
+		return fmt.Errorf("发送邮件失败: %w", err)
+	}
+	return nil
+}
+
+// Link 前端页面的地址LinkBaseURL+path，key不为空时附带查询参数key=value
+func (s *AccountService) Link(path, key, value string) string {
+	link := strings.TrimRight(s.cfg.LinkBaseURL, "/") + path
+	if key != "" {
+		link += "?" + key + "=" + url.QueryEscape(value)
+	}
+	return link
+}
+
+// useToken 校验并使用一次性令牌；并发使用时只有一个请求成功
+func useToken(tx *gorm.DB, raw, purpose string) (*models.UserToken, error) {
+	var token models.UserToken
+	if err := tx.Where("token_hash = ? AND purpose = ?", hashToken(raw), purpose).First(&token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidUserToken
+		}
+		return nil, err
+	}
+	if token.UsedAt != nil || time.Now().After(token.ExpiresAt) {
+		return nil, ErrInvalidUserToken
+	}
+
+	result := tx.Model(&models.UserToken{}).
+		Where("id = ? AND used_at IS NULL", token.ID).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrInvalidUserToken
+	}
+	return &token, nil
+}
+
+// expireTokens 使用户某一用途未使用的令牌全部失效
+func expireTokens(tx *gorm.DB, userID uint, purpose string) error {
+	return tx.Model(&models.UserToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?", userID, purpose, time.Now()).
+		Update("expires_at", time.Now()).Error
+}
@@ -0,0 +1,620 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"depression_go/configs"
+	"depression_go/internal/models"
+	"depression_go/pkg/mailer"
+	"depression_go/pkg/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrDeletionPending         = errors.New("已有等待中的注销申请")
+	ErrDeletionNotPending      = errors.New("没有等待中的注销申请")
+	ErrDeletionReceiptNotFound = errors.New("删除回执不存在")
+	ErrDeletionReauth          = errors.New("请提供当前密码或两步验证码")
+	ErrDeletionSSOReauth       = errors.New("请先通过单点登录重新认证，10分钟内提交注销申请")
+)
+
+// deletionRunInterval 检查到期注销申请的间隔
+const deletionRunInterval = 10 * time.Minute
+
+// deletionSSOReauthWindow 未设置密码的单点登录账户申请注销前，需要在这段时间内重新认证
+const deletionSSOReauthWindow = 10 * time.Minute
+
+// 匿名汇总的类别
+const (
+	erasedMetricUsers          = "users"
+	erasedMetricAssessments    = "assessments"
+	erasedMetricFaceDetections = "face_detections"
+	erasedMetricJournalEntries = "journal_entries"
+	erasedMetricRiskEvents     = "risk_events"
+)
+
+// DeletionFilter 注销申请列表的筛选条件，零值表示不限
+type DeletionFilter struct {
+	Status string
+	UserID uint
+}
+
+// AccountDeletionService 账户注销：申请后等待一段时间（可撤销），到期后彻底删除账户和全部数据，只保留匿名汇总计数和删除回执
+type AccountDeletionService struct {
+	db       *gorm.DB
+	accounts *AccountService
+	lockouts *LockoutService
+	mfa      *MFAService
+	grace    time.Duration
+}
+
+// NewAccountDeletionService 创建账户注销服务
+func NewAccountDeletionService(db *gorm.DB) *AccountDeletionService {
+	cfg := configs.DeletionConfig{GraceDays: 14}
+	if configs.GlobalConfig != nil {
+		cfg = configs.GlobalConfig.Deletion
+	}
+	return &AccountDeletionService{
+		db:       db,
+		accounts: NewAccountService(db),
+		lockouts: NewLockoutService(db),
+		mfa:      NewMFAService(db),
+		grace:    time.Duration(cfg.GraceDays) * 24 * time.Hour,
+	}
+}
+
+// Request 用户自助申请注销，校验当前密码或两步验证码；等待期内账户照常使用，可以撤销
+func (s *AccountDeletionService) Request(actx AuditContext, user *models.User, req models.AccountDeletionRequest, locale string) (*models.DeletionReceiptResponse, error) {
+	if err := s.reauthenticate(user, req, actx.IP); err != nil {
+		return nil, err
+	}
+
+	deletion, err := s.create(actx, user.ID, models.DeletionSourceSelf, "", time.Now().Add(s.grace))
+	if err != nil {
+		return nil, err
+	}
+
+	// 通知邮件发送失败不影响申请，用户在设置页面仍能看到并撤销
+	if err := s.accounts.SendNotice(user.Email, user.Username, userLocale(user, locale), mailer.TemplateDeletionScheduled, mailer.TemplateData{
+		Link:        s.accounts.Link("/account/deletion", "", ""),
+		ScheduledAt: deletion.ScheduledAt.Format("2006-01-02 15:04"),
+		ReceiptID:   deletion.ReceiptID,
+	}); err != nil {
+		log.Printf("发送注销提醒邮件失败(用户%d): %v", user.ID, err)
+	}
+	return toDeletionReceipt(deletion), nil
+}
+
+// reauthenticate 申请注销前确认本人：密码和验证码任选其一，错误计入登录失败次数；
+// 单点登录创建且从未设置密码的账户不知道密码，需要先通过单点登录重新认证
+func (s *AccountDeletionService) reauthenticate(user *models.User, req models.AccountDeletionRequest, ip string) error {
+	if req.Password == "" && req.Code == "" {
+		if user.RandomPassword {
+			return s.recentSSOLogin(user.ID)
+		}
+		return ErrDeletionReauth
+	}
+
+	accountKey := AccountKey(user, "")
+	if err := s.lockouts.Check(accountKey, ip); err != nil {
+		return err
+	}
+	var err error
+	if req.Password != "" {
+		if !utils.CheckPassword(req.Password, user.Password) {
+			err = ErrWrongPassword
+		}
+	} else {
+		err = s.mfa.Verify(user.ID, req.Code, "")
+	}
+	if errors.Is(err, ErrWrongPassword) || errors.Is(err, ErrInvalidMFACode) {
+		if lockErr := s.lockouts.RecordFailure(accountKey, ip); lockErr != nil {
+			return lockErr
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	s.lockouts.RecordSuccess(accountKey)
+	return nil
+}
+
+// recentSSOLogin 关联的提供方在deletionSSOReauthWindow内认证过本人（ID令牌的auth_time）
+func (s *AccountDeletionService) recentSSOLogin(userID uint) error {
+	var count int64
+	err := s.db.Model(&models.UserIdentity{}).
+		Where("user_id = ? AND authenticated_at >= ?", userID, time.Now().Add(-deletionSSOReauthWindow)).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrDeletionSSOReauth
+	}
+	return nil
+}
+
+// AdminRequest 管理员代为办理线下收到的删除请求；immediate为true时立即删除，已有等待中的申请时提前执行
+func (s *AccountDeletionService) AdminRequest(actx AuditContext, userID uint, reason string, immediate bool) (*models.AdminDeletionResponse, error) {
+	if actx.ActorID == userID {
+		return nil, ErrSelfManagement
+	}
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	scheduledAt := time.Now().Add(s.grace)
+	if immediate {
+		scheduledAt = time.Now()
+	}
+	deletion, err := s.create(actx, userID, models.DeletionSourceAdmin, reason, scheduledAt)
+	if errors.Is(err, ErrDeletionPending) && immediate {
+		deletion, err = s.pending(userID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if immediate {
+		if err := s.Execute(actx, deletion); err != nil {
+			return nil, err
+		}
+		if err := s.db.First(deletion, deletion.ID).Error; err != nil {
+			return nil, err
+		}
+	}
+	return toAdminDeletion(deletion), nil
+}
+
+// Status 当前等待中的注销申请，没有时返回nil
+func (s *AccountDeletionService) Status(userID uint) (*models.DeletionReceiptResponse, error) {
+	deletion, err := s.pending(userID)
+	if errors.Is(err, ErrDeletionNotPending) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toDeletionReceipt(deletion), nil
+}
+
+// Cancel 撤销等待中的注销申请，用户本人和管理员都可以撤销
+func (s *AccountDeletionService) Cancel(actx AuditContext, userID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var deletion models.AccountDeletion
+		if err := tx.Where("user_id = ? AND status = ?", userID, models.DeletionStatusPending).First(&deletion).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrDeletionNotPending
+			}
+			return err
+		}
+		// 定时任务可能正在执行删除，只有仍在等待中才能撤销
+		now := time.Now()
+		result := tx.Model(&models.AccountDeletion{}).
+			Where("id = ? AND status = ?", deletion.ID, models.DeletionStatusPending).
+			Updates(map[string]interface{}{"status": models.DeletionStatusCancelled, "cancelled_at": now})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrDeletionNotPending
+		}
+		return RecordAudit(tx, actx, "deletion_cancel", auditResourceUser, userID, nil, nil)
+	})
+}
+
+// Receipt 按回执编号查询删除回执，不需要登录（账户删除后无法登录）
+func (s *AccountDeletionService) Receipt(receiptID string) (*models.DeletionReceiptResponse, error) {
+	var deletion models.AccountDeletion
+	if err := s.db.Where("receipt_id = ?", receiptID).First(&deletion).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDeletionReceiptNotFound
+		}
+		return nil, err
+	}
+	return toDeletionReceipt(&deletion), nil
+}
+
+// List 分页列出注销申请，按申请时间倒序
+func (s *AccountDeletionService) List(filter DeletionFilter, page, pageSize int) ([]models.AdminDeletionResponse, int64, error) {
+	query := s.db.Model(&models.AccountDeletion{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var deletions []models.AccountDeletion
+	if err := query.Order("created_at DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&deletions).Error; err != nil {
+		return nil, 0, err
+	}
+
+	list := make([]models.AdminDeletionResponse, 0, len(deletions))
+	for i := range deletions {
+		list = append(list, *toAdminDeletion(&deletions[i]))
+	}
+	return list, total, nil
+}
+
+// Stats 已删除数据的匿名汇总，metric为空时返回全部类别
+func (s *AccountDeletionService) Stats(metric string) ([]models.ErasedDataStat, error) {
+	query := s.db.Model(&models.ErasedDataStat{})
+	if metric != "" {
+		query = query.Where("metric = ?", metric)
+	}
+	var stats []models.ErasedDataStat
+	err := query.Order("period, metric, dimension").Find(&stats).Error
+	return stats, err
+}
+
+// RunDue 执行所有已到期的注销申请，单个失败时记录原因，下次重试
+func (s *AccountDeletionService) RunDue() (int, error) {
+	var due []models.AccountDeletion
+	if err := s.db.Where("status = ? AND scheduled_at <= ?", models.DeletionStatusPending, time.Now()).
+		Order("scheduled_at").Find(&due).Error; err != nil {
+		return 0, err
+	}
+
+	done := 0
+	for i := range due {
+		if err := s.Execute(AuditContext{}, &due[i]); err != nil {
+			// 执行前已被撤销
+			if errors.Is(err, ErrDeletionNotPending) {
+				continue
+			}
+			log.Printf("执行注销申请%d失败: %v", due[i].ID, err)
+			s.db.Model(&due[i]).Update("last_error", truncate(err.Error(), 1000))
+			continue
+		}
+		done++
+	}
+	return done, nil
+}
+
+// StartPurge 后台定期执行到期的注销申请，ctx取消时退出
+func (s *AccountDeletionService) StartPurge(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(deletionRunInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				count, err := s.RunDue()
+				if err != nil {
+					log.Printf("检查到期的注销申请失败: %v", err)
+				} else if count > 0 {
+					log.Printf("已删除%d个注销的账户", count)
+				}
+			}
+		}
+	}()
+}
+
+// Execute 彻底删除账户及其全部数据：先把要删除的数据计入匿名汇总，再在同一事务内物理删除，最后删除图片文件并发送回执
+func (s *AccountDeletionService) Execute(actx AuditContext, deletion *models.AccountDeletion) error {
+	var user models.User
+	var files []string
+	var summary models.DeletionSummary
+	found := false
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// 先占住申请，与撤销互斥
+		now := time.Now()
+		result := tx.Model(&models.AccountDeletion{}).
+			Where("id = ? AND status = ?", deletion.ID, models.DeletionStatusPending).
+			Updates(map[string]interface{}{"status": models.DeletionStatusCompleted, "completed_at": now})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrDeletionNotPending
+		}
+
+		userID := deletion.UserID
+		err := tx.Unscoped().First(&user, userID).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		found = err == nil
+
+		if err := aggregateErasedData(tx, userID, &user, found); err != nil {
+			return err
+		}
+		if files, err = userFiles(tx, userID); err != nil {
+			return err
+		}
+
+		// 登录记录还包括按用户名、邮箱记录的失败尝试
+		attemptQuery, attemptArgs := "user_id = ?", []interface{}{userID}
+		if found {
+			attemptQuery, attemptArgs = "user_id = ? OR identifier IN ?", []interface{}{userID, []string{user.Username, user.Email}}
+		}
+
+		// 物理删除，答案和风险事件先于评估删除
+		deletes := []struct {
+			model interface{}
+			query string
+			args  []interface{}
+			count *int64
+		}{
+			{&models.Answer{}, "user_id = ?", []interface{}{userID}, &summary.Answers},
+			{&models.RiskEvent{}, "user_id = ?", []interface{}{userID}, &summary.RiskEvents},
+			{&models.Assessment{}, "user_id = ?", []interface{}{userID}, &summary.Assessments},
+			{&models.FaceDetection{}, "user_id = ?", []interface{}{userID}, &summary.FaceDetections},
+			{&models.JournalEntry{}, "user_id = ?", []interface{}{userID}, &summary.JournalEntries},
+			{&models.RefreshToken{}, "user_id = ?", []interface{}{userID}, &summary.Sessions},
+			{&models.UserSession{}, "user_id = ?", []interface{}{userID}, &summary.Sessions},
+			{&models.LoginAttempt{}, attemptQuery, attemptArgs, &summary.LoginAttempts},
+			{&models.UserToken{}, "user_id = ?", []interface{}{userID}, nil},
+			{&models.UserMFA{}, "user_id = ?", []interface{}{userID}, nil},
+			{&models.MFARecoveryCode{}, "user_id = ?", []interface{}{userID}, nil},
+			{&models.UserIdentity{}, "user_id = ?", []interface{}{userID}, nil},
+		}
+		for _, d := range deletes {
+			result := tx.Unscoped().Where(d.query, d.args...).Delete(d.model)
+			if result.Error != nil {
+				return result.Error
+			}
+			if d.count != nil {
+				*d.count += result.RowsAffected
+			}
+		}
+
+		// 审计日志保留操作记录，去掉其中的用户资料快照
+		if err := tx.Model(&models.AuditLog{}).
+			Where("resource_type = ? AND resource_id = ?", auditResourceUser, userID).
+			Updates(map[string]interface{}{"before": "", "after": ""}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Delete(&models.User{}, userID).Error; err != nil {
+			return err
+		}
+		if err := RecordAudit(tx, actx, "erase", auditResourceUser, userID, nil, nil); err != nil {
+			return err
+		}
+		return saveDeletionSummary(tx, deletion.ID, &summary)
+	})
+	if err != nil {
+		return err
+	}
+
+	// 文件在事务提交后删除，删除失败只计数，不影响已删除的数据
+	for _, file := range files {
+		if err := os.Remove(file); err != nil {
+			if !os.IsNotExist(err) {
+				log.Printf("删除文件失败: %v", err)
+			}
+			summary.FilesMissing++
+			continue
+		}
+		summary.Files++
+	}
+	if err := saveDeletionSummary(s.db, deletion.ID, &summary); err != nil {
+		log.Printf("保存删除回执失败(申请%d): %v", deletion.ID, err)
+	}
+
+	if !found {
+		return nil
+	}
+	s.lockouts.RecordSuccess(AccountKey(&user, ""))
+	if err := s.accounts.SendNotice(user.Email, user.Username, userLocale(&user, ""), mailer.TemplateDeletionCompleted, mailer.TemplateData{
+		Link:        s.accounts.Link("/deletion-receipt", "id", deletion.ReceiptID),
+		ScheduledAt: time.Now().Format("2006-01-02 15:04"),
+		ReceiptID:   deletion.ReceiptID,
+	}); err != nil {
+		log.Printf("发送删除回执邮件失败(申请%d): %v", deletion.ID, err)
+	}
+	return nil
+}
+
+// create 创建注销申请，同一用户只能有一个等待中的申请
+func (s *AccountDeletionService) create(actx AuditContext, userID uint, source, reason string, scheduledAt time.Time) (*models.AccountDeletion, error) {
+	deletion := models.AccountDeletion{
+		UserID:      userID,
+		Source:      source,
+		RequestedBy: actx.ActorID,
+		Reason:      reason,
+		Status:      models.DeletionStatusPending,
+		ScheduledAt: scheduledAt,
+		ReceiptID:   uuid.New().String(),
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var pending int64
+		if err := tx.Model(&models.AccountDeletion{}).
+			Where("user_id = ? AND status = ?", userID, models.DeletionStatusPending).
+			Count(&pending).Error; err != nil {
+			return err
+		}
+		if pending > 0 {
+			return ErrDeletionPending
+		}
+		if err := tx.Create(&deletion).Error; err != nil {
+			return err
+		}
+		return RecordAudit(tx, actx, "deletion_request", auditResourceUser, userID, nil, map[string]interface{}{
+			"source":       source,
+			"reason":       reason,
+			"scheduled_at": scheduledAt,
+			"receipt_id":   deletion.ReceiptID,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &deletion, nil
+}
+
+// pending 用户等待中的注销申请
+func (s *AccountDeletionService) pending(userID uint) (*models.AccountDeletion, error) {
+	var deletion models.AccountDeletion
+	err := s.db.Where("user_id = ? AND status = ?", userID, models.DeletionStatusPending).First(&deletion).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrDeletionNotPending
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &deletion, nil
+}
+
+// aggregateErasedData 把即将删除的数据按月份和类别累加到匿名汇总中
+func aggregateErasedData(tx *gorm.DB, userID uint, user *models.User, found bool) error {
+	counts := make(map[[3]string]int64)
+	add := func(t time.Time, metric, dimension string) {
+		counts[[3]string{t.Format("2006-01"), metric, dimension}]++
+	}
+
+	if found {
+		add(user.CreatedAt, erasedMetricUsers, user.Role)
+	}
+
+	var assessments []models.Assessment
+	if err := tx.Select("created_at", "type", "level").
+		Where("user_id = ? AND status = ?", userID, models.AssessmentStatusCompleted).Find(&assessments).Error; err != nil {
+		return err
+	}
+	for _, a := range assessments {
+		add(a.CreatedAt, erasedMetricAssessments, a.Type+":"+a.Level)
+	}
+
+	var detections []models.FaceDetection
+	if err := tx.Select("created_at", "emotion").Where("user_id = ?", userID).Find(&detections).Error; err != nil {
+		return err
+	}
+	for _, d := range detections {
+		add(d.CreatedAt, erasedMetricFaceDetections, d.Emotion)
+	}
+
+	var entries []models.JournalEntry
+	if err := tx.Select("created_at", "sentiment_label").Where("user_id = ?", userID).Find(&entries).Error; err != nil {
+		return err
+	}
+	for _, e := range entries {
+		add(e.CreatedAt, erasedMetricJournalEntries, e.SentimentLabel)
+	}
+
+	var events []models.RiskEvent
+	if err := tx.Select("created_at", "status").Where("user_id = ?", userID).Find(&events).Error; err != nil {
+		return err
+	}
+	for _, e := range events {
+		add(e.CreatedAt, erasedMetricRiskEvents, e.Status)
+	}
+
+	now := time.Now()
+	for key, count := range counts {
+		stat := models.ErasedDataStat{Period: key[0], Metric: key[1], Dimension: truncate(key[2], 50), Count: count, UpdatedAt: now}
+		if err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "period"}, {Name: "metric"}, {Name: "dimension"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"count":      gorm.Expr("`count` + ?", count),
+				"updated_at": now,
+			}),
+		}).Create(&stat).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// userFiles 用户的图片文件（人脸检测图片、头像），只返回上传目录内的文件
+// 头像按文件名avatar_<userID>_*查找，不信任avatar字段，也能清理替换后残留的旧头像
+func userFiles(tx *gorm.DB, userID uint) ([]string, error) {
+	var paths []string
+	if err := tx.Unscoped().Model(&models.FaceDetection{}).Where("user_id = ?", userID).Pluck("image_path", &paths).Error; err != nil {
+		return nil, err
+	}
+	avatars, err := filepath.Glob(filepath.Join(UploadDir(), avatarPrefix(userID)+"*"))
+	if err != nil {
+		return nil, err
+	}
+	paths = append(paths, avatars...)
+
+	dir, err := filepath.Abs(UploadDir())
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		abs, err := filepath.Abs(path)
+		if err != nil || seen[abs] || !strings.HasPrefix(abs, dir+string(filepath.Separator)) {
+			continue
+		}
+		seen[abs] = true
+		files = append(files, abs)
+	}
+	return files, nil
+}
+
+// saveDeletionSummary 保存删除回执中的数据条数
+func saveDeletionSummary(db *gorm.DB, deletionID uint, summary *models.DeletionSummary) error {
+	data, err := json.Marshal(summary)
+	if err != nil {
+		return err
+	}
+	return db.Model(&models.AccountDeletion{}).Where("id = ?", deletionID).
+		Updates(map[string]interface{}{"summary": string(data), "last_error": ""}).Error
+}
+
+// userLocale 邮件语言：用户的语言偏好优先，其次是请求语言
+func userLocale(user *models.User, locale string) string {
+	if user.Locale != "" {
+		return user.Locale
+	}
+	return locale
+}
+
+// toDeletionReceipt 转换为删除回执
+func toDeletionReceipt(deletion *models.AccountDeletion) *models.DeletionReceiptResponse {
+	receipt := &models.DeletionReceiptResponse{
+		ReceiptID:   deletion.ReceiptID,
+		Status:      deletion.Status,
+		Source:      deletion.Source,
+		RequestedAt: deletion.CreatedAt,
+		ScheduledAt: deletion.ScheduledAt,
+		CancelledAt: deletion.CancelledAt,
+		CompletedAt: deletion.CompletedAt,
+	}
+	if deletion.Summary != "" {
+		var summary models.DeletionSummary
+		if err := json.Unmarshal([]byte(deletion.Summary), &summary); err == nil {
+			receipt.Deleted = &summary
+		}
+	}
+	return receipt
+}
+
+// toAdminDeletion 转换为管理员查看的注销申请
+func toAdminDeletion(deletion *models.AccountDeletion) *models.AdminDeletionResponse {
+	return &models.AdminDeletionResponse{
+		ID:                      deletion.ID,
+		UserID:                  deletion.UserID,
+		RequestedBy:             deletion.RequestedBy,
+		Reason:                  deletion.Reason,
+		LastError:               deletion.LastError,
+		DeletionReceiptResponse: *toDeletionReceipt(deletion),
+	}
+}
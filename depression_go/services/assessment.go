@@ -0,0 +1,808 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"depression_go/configs"
+	"depression_go/internal/models"
+	"depression_go/pkg/i18n"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAssessmentNotFound     = errors.New("评估不存在")
+	ErrAssessmentClosed       = errors.New("评估已完成或已过期，无法继续作答")
+	ErrQuestionNotFound       = errors.New("问题不存在")
+	ErrNoAnswers              = errors.New("评估尚未作答，无法提交")
+	ErrAssessmentNotCompleted = errors.New("评估尚未完成，无法重新计分")
+	ErrInvalidMode            = errors.New("不支持的作答模式，仅支持fixed、adaptive")
+	ErrNotAdaptive            = errors.New("该评估不是自适应测验")
+	ErrNoAdaptiveItems        = errors.New("当前问卷没有配置IRT参数的题目，无法使用自适应测验")
+	ErrNotQuestionnaire       = errors.New("该评估不是问卷评估，无法进行该操作")
+	ErrInvalidAnswerValue     = errors.New("选项值超出范围")
+	ErrAdaptiveNotDone        = errors.New("自适应测验尚未满足结束条件（包括全部风险条目），请继续作答")
+)
+
+// nonQuestionnaireTypes 不按问卷作答和计分的评估类型：每日打卡、综合评估
+var nonQuestionnaireTypes = []string{models.AssessmentTypeCheckin, models.AssessmentTypeCombined}
+
+// isQuestionnaireType 判断评估类型是否按问卷作答和计分
+func isQuestionnaireType(assessmentType string) bool {
+	for _, t := range nonQuestionnaireTypes {
+		if assessmentType == t {
+			return false
+		}
+	}
+	return true
+}
+
+// AssessmentService 评估会话服务：草稿创建、逐题保存、恢复与最终计分
+type AssessmentService struct {
+	db       *gorm.DB
+	draftTTL time.Duration
+	crisis   *CrisisService
+	versions *QuestionnaireVersionService
+	scoring  *ScoringService
+}
+
+// AnswerOutcome 单题保存结果
+type AnswerOutcome struct {
+	Answer *models.Answer
+	Crisis *models.CrisisInfo // 触发风险条目时不为空
+}
+
+// FinalizeOutcome 评估完成结果
+type FinalizeOutcome struct {
+	Assessment *models.Assessment
+	Result     models.AssessmentResult
+	Crisis     *models.CrisisInfo // 评估中有风险条目被触发时不为空
+	Quality    *QualityResult     // 作答质量检查结果
+}
+
+// NewAssessmentService 创建评估会话服务
+func NewAssessmentService(db *gorm.DB) *AssessmentService {
+	ttl := 72 * time.Hour
+	if configs.GlobalConfig != nil && configs.GlobalConfig.Assessment.DraftTTLHours > 0 {
+		ttl = time.Duration(configs.GlobalConfig.Assessment.DraftTTLHours) * time.Hour
+	}
+	return &AssessmentService{
+		db:       db,
+		draftTTL: ttl,
+		crisis:   NewCrisisService(db),
+		versions: NewQuestionnaireVersionService(db),
+		scoring:  NewScoringService(db),
+	}
+}
+
+// CreateDraft 创建进行中的评估草稿，mode为空时按完整问卷作答，order_mode为空时按题库顺序
+func (s *AssessmentService) CreateDraft(userID uint, req models.AssessmentCreateRequest) (*models.Assessment, error) {
+	var assessment *models.Assessment
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		assessment, err = s.createDraft(tx, userID, req)
+		return err
+	})
+	return assessment, err
+}
+
+func (s *AssessmentService) createDraft(tx *gorm.DB, userID uint, req models.AssessmentCreateRequest) (*models.Assessment, error) {
+	if !isQuestionnaireType(req.Type) {
+		return nil, ErrNotQuestionnaire
+	}
+	mode := req.Mode
+	if mode == "" {
+		mode = models.AssessmentModeFixed
+	}
+	if mode != models.AssessmentModeFixed && mode != models.AssessmentModeAdaptive {
+		return nil, ErrInvalidMode
+	}
+	orderMode := req.OrderMode
+	if orderMode == "" {
+		orderMode = models.QuestionOrderFixed
+	}
+	if !ValidOrderMode(orderMode) {
+		return nil, ErrInvalidOrderMode
+	}
+	if mode == models.AssessmentModeAdaptive && orderMode != models.QuestionOrderFixed {
+		return nil, ErrAdaptiveOrderMode
+	}
+
+	// 记录作答时的问卷版本，之后题库如何修改都不影响该评估的渲染与计分
+	version, err := s.versions.Current(tx)
+	if err != nil {
+		return nil, err
+	}
+	versioned, err := s.versions.Questions(tx, version.ID)
+	if err != nil {
+		return nil, err
+	}
+	questions := make([]models.Question, 0, len(versioned))
+	for _, q := range versioned {
+		questions = append(questions, q.Question)
+	}
+
+	if mode == models.AssessmentModeAdaptive {
+		hasItems := false
+		for _, question := range questions {
+			if _, ok := parseIRTItem(question); ok {
+				hasItems = true
+				break
+			}
+		}
+		if !hasItems {
+			return nil, ErrNoAdaptiveItems
+		}
+	}
+
+	// 记录本次评估展示的题目顺序，自适应测验的顺序由作答过程决定
+	var seed int64
+	var order string
+	if mode == models.AssessmentModeFixed {
+		switch {
+		case req.OrderSeed != nil:
+			seed = *req.OrderSeed
+		case orderMode == models.QuestionOrderCounterbalanced:
+			if seed, err = counterbalancedSeed(tx, version.ID, countBlocks(questions)); err != nil {
+				return nil, err
+			}
+		case orderMode != models.QuestionOrderFixed:
+			seed = NewOrderSeed()
+		}
+		ids := make([]uint, 0, len(questions))
+		for _, question := range OrderQuestions(questions, orderMode, seed) {
+			ids = append(ids, question.ID)
+		}
+		data, err := json.Marshal(ids)
+		if err != nil {
+			return nil, err
+		}
+		order = string(data)
+	}
+
+	expiresAt := time.Now().Add(s.draftTTL)
+	assessment := models.Assessment{
+		UserID:                 userID,
+		Title:                  req.Title,
+		Type:                   req.Type,
+		Status:                 models.AssessmentStatusInProgress,
+		ExpiresAt:              &expiresAt,
+		QuestionnaireVersionID: version.ID,
+		Mode:                   mode,
+		OrderMode:              orderMode,
+		OrderSeed:              seed,
+		QuestionOrder:          order,
+	}
+	if err := tx.Create(&assessment).Error; err != nil {
+		return nil, err
+	}
+	return &assessment, nil
+}
+
+// GetOwned 获取属于该用户的评估，已超时的草稿会被标记为过期
+func (s *AssessmentService) GetOwned(userID, assessmentID uint) (*models.Assessment, error) {
+	return s.getOwned(s.db, userID, assessmentID)
+}
+
+func (s *AssessmentService) getOwned(tx *gorm.DB, userID, assessmentID uint) (*models.Assessment, error) {
+	var assessment models.Assessment
+	if err := tx.Where("id = ? AND user_id = ?", assessmentID, userID).First(&assessment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAssessmentNotFound
+		}
+		return nil, err
+	}
+
+	if assessment.Status == models.AssessmentStatusInProgress &&
+		assessment.ExpiresAt != nil && assessment.ExpiresAt.Before(time.Now()) {
+		if err := tx.Model(&assessment).Update("status", models.AssessmentStatusExpired).Error; err != nil {
+			return nil, err
+		}
+		assessment.Status = models.AssessmentStatusExpired
+	}
+	return &assessment, nil
+}
+
+// ListDrafts 列出用户所有未过期的草稿，便于在任意设备上继续作答
+func (s *AssessmentService) ListDrafts(userID uint) ([]models.Assessment, error) {
+	var drafts []models.Assessment
+	err := s.db.Where("user_id = ? AND status = ? AND (expires_at IS NULL OR expires_at > ?)",
+		userID, models.AssessmentStatusInProgress, time.Now()).
+		Order("updated_at DESC").
+		Find(&drafts).Error
+	return drafts, err
+}
+
+// HistoryFilter 评估历史的筛选条件，空值表示不筛选
+type HistoryFilter struct {
+	Type  string
+	Level string
+	From  *time.Time // 创建时间不早于From
+	To    *time.Time // 创建时间早于To
+}
+
+// History 分页查询用户的评估历史（含草稿和每日打卡），按创建时间倒序
+func (s *AssessmentService) History(userID uint, filter HistoryFilter, page, pageSize int) ([]models.Assessment, int64, error) {
+	query := s.db.Model(&models.Assessment{}).Where("user_id = ?", userID)
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.Level != "" {
+		query = query.Where("level = ?", filter.Level)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var assessments []models.Assessment
+	err := query.Order("created_at DESC, id DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&assessments).Error
+	return assessments, total, err
+}
+
+// GetAnswers 获取评估已保存的答案，按作答时的问卷版本渲染题目
+func (s *AssessmentService) GetAnswers(assessmentID uint) ([]models.AnswerWithQuestion, error) {
+	var answers []models.Answer
+	if err := s.db.Where("assessment_id = ?", assessmentID).Order("id ASC").Find(&answers).Error; err != nil {
+		return nil, err
+	}
+	return s.versions.RenderAnswers(s.db, answers)
+}
+
+// GetQuestions 获取评估所用问卷版本的题目，按评估记录的顺序排列，恢复作答时题目和顺序与开始时保持一致
+func (s *AssessmentService) GetQuestions(userID, assessmentID uint) ([]VersionedQuestion, error) {
+	assessment, err := s.GetOwned(userID, assessmentID)
+	if err != nil {
+		return nil, err
+	}
+	if assessment.QuestionnaireVersionID == 0 {
+		return nil, ErrVersionNotFound
+	}
+	questions, err := s.versions.Questions(s.db, assessment.QuestionnaireVersionID)
+	if err != nil {
+		return nil, err
+	}
+
+	order := questionOrder(assessment)
+	if len(order) == 0 {
+		return questions, nil
+	}
+	sort.SliceStable(questions, func(i, j int) bool {
+		return orderPosition(order, questions[i].Question.ID) < orderPosition(order, questions[j].Question.ID)
+	})
+	return questions, nil
+}
+
+// SaveAnswer 保存单题答案，同一题重复提交时覆盖之前的答案；
+// 风险条目被非零作答时立即进入危机流程，不等到评估完成
+func (s *AssessmentService) SaveAnswer(userID, assessmentID uint, req models.AnswerRequest) (*AnswerOutcome, error) {
+	var answer *models.Answer
+	var events []models.RiskEvent
+	var crisis *models.CrisisInfo
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		assessment, err := s.getOwned(tx, userID, assessmentID)
+		if err != nil {
+			return err
+		}
+		var event *models.RiskEvent
+		answer, event, err = s.saveAnswer(tx, assessment, req)
+		if err != nil {
+			return err
+		}
+		if event != nil {
+			events = append(events, *event)
+		}
+		// 自适应测验每次作答后更新θ估计，便于恢复作答时查看进度
+		if assessment.Mode == models.AssessmentModeAdaptive {
+			step, err := s.adaptiveStep(tx, assessment)
+			if err != nil {
+				return err
+			}
+			if err := tx.Model(assessment).Updates(map[string]interface{}{
+				"theta_estimate": step.Theta,
+				"theta_se":       step.ThetaSE,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		// 评估一旦触发风险条目，后续每次保存都持续返回危机信息
+		if assessment.RiskFlagged {
+			crisis, err = s.crisis.CrisisInfo(tx, assessment.ID)
+			if err != nil {
+				return err
+			}
+		}
+		return s.touchDraft(tx, assessment)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.crisis.Dispatch(events)
+	return &AnswerOutcome{Answer: answer, Crisis: crisis}, nil
+}
+
+func (s *AssessmentService) saveAnswer(tx *gorm.DB, assessment *models.Assessment, req models.AnswerRequest) (*models.Answer, *models.RiskEvent, error) {
+	if assessment.Status != models.AssessmentStatusInProgress {
+		return nil, nil, ErrAssessmentClosed
+	}
+
+	// 按评估记录的问卷版本取题目内容和权重
+	question, revisionID, err := s.versions.ResolveQuestion(tx, assessment.QuestionnaireVersionID, req.QuestionID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 选项值必须落在题目的选项范围内，否则会直接放大或抵消得分
+	if question.Type != models.QuestionTypeText && (req.AnswerValue < 1 || req.AnswerValue > optionCount(question)) {
+		return nil, nil, fmt.Errorf("%w: 问题%d", ErrInvalidAnswerValue, question.ID)
+	}
+
+	score := CalculateQuestionScore(question.Score, req.AnswerValue)
+	content := GetAnswerText(question, req.AnswerValue)
+	// 客户端未上报作答用时记为0，不参与作答过快检查
+	responseTime := req.ResponseTimeMs
+	if responseTime < 0 {
+		responseTime = 0
+	}
+
+	var answer models.Answer
+	err = tx.Where("assessment_id = ? AND question_id = ?", assessment.ID, question.ID).First(&answer).Error
+	switch {
+	case err == nil:
+		if err := tx.Model(&answer).Updates(map[string]interface{}{
+			"content":              content,
+			"score":                score,
+			"answer_value":         req.AnswerValue,
+			"question_revision_id": revisionID,
+			"response_time_ms":     responseTime,
+		}).Error; err != nil {
+			return nil, nil, err
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		// 记录题目展示的位置；没有预先确定顺序的评估（自适应测验、历史草稿）按作答先后记录
+		position := orderPosition(questionOrder(assessment), question.ID)
+		if position == 0 {
+			var count int64
+			if err := tx.Model(&models.Answer{}).Where("assessment_id = ?", assessment.ID).Count(&count).Error; err != nil {
+				return nil, nil, err
+			}
+			position = int(count) + 1
+		}
+		answer = models.Answer{
+			UserID:             assessment.UserID,
+			QuestionID:         question.ID,
+			AssessmentID:       assessment.ID,
+			Content:            content,
+			Score:              score,
+			AnswerValue:        req.AnswerValue,
+			QuestionRevisionID: revisionID,
+			ResponseTimeMs:     responseTime,
+			Position:           position,
+		}
+		if err := tx.Create(&answer).Error; err != nil {
+			return nil, nil, err
+		}
+	default:
+		return nil, nil, err
+	}
+
+	event, err := s.crisis.DetectRisk(tx, assessment, question, req.AnswerValue, content)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &answer, event, nil
+}
+
+// NextQuestion 自适应测验的下一步：返回信息量最大的下一题，或提示可以结束作答
+func (s *AssessmentService) NextQuestion(userID, assessmentID uint) (*CATStep, error) {
+	assessment, err := s.GetOwned(userID, assessmentID)
+	if err != nil {
+		return nil, err
+	}
+	if assessment.Mode != models.AssessmentModeAdaptive {
+		return nil, ErrNotAdaptive
+	}
+	if assessment.Status != models.AssessmentStatusInProgress {
+		return nil, ErrAssessmentClosed
+	}
+	return s.adaptiveStep(s.db, assessment)
+}
+
+// adaptiveStep 按评估的问卷版本和已保存的答案计算自适应测验的当前状态
+func (s *AssessmentService) adaptiveStep(tx *gorm.DB, assessment *models.Assessment) (*CATStep, error) {
+	questions, err := s.versions.Questions(tx, assessment.QuestionnaireVersionID)
+	if err != nil {
+		return nil, err
+	}
+
+	var answers []models.Answer
+	if err := tx.Where("assessment_id = ?", assessment.ID).Find(&answers).Error; err != nil {
+		return nil, err
+	}
+	values := make(map[uint]int, len(answers))
+	for _, answer := range answers {
+		values[answer.QuestionID] = answer.AnswerValue
+	}
+
+	step := nextAdaptiveStep(questions, values, catSettings())
+	return &step, nil
+}
+
+// adaptiveResult 自适应测验的结果：θ映射为0-100分后按计分方案划分等级
+func (s *AssessmentService) adaptiveResult(tx *gorm.DB, assessment *models.Assessment, profile *models.ScoringProfile) (models.AssessmentResult, error) {
+	step, err := s.adaptiveStep(tx, assessment)
+	if err != nil {
+		return models.AssessmentResult{}, err
+	}
+	theta, se := step.Theta, step.ThetaSE
+	result := CalculateAssessmentResult(profile, ThetaToScore(theta), 100)
+	result.Theta = &theta
+	result.ThetaSE = &se
+	return result, nil
+}
+
+// touchDraft 顺延草稿的过期时间
+func (s *AssessmentService) touchDraft(tx *gorm.DB, assessment *models.Assessment) error {
+	expiresAt := time.Now().Add(s.draftTTL)
+	assessment.ExpiresAt = &expiresAt
+	return tx.Model(assessment).Update("expires_at", expiresAt).Error
+}
+
+// Finalize 完成评估：汇总已保存的答案并计分
+func (s *AssessmentService) Finalize(userID, assessmentID uint) (*FinalizeOutcome, error) {
+	var outcome *FinalizeOutcome
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		assessment, err := s.getOwned(tx, userID, assessmentID)
+		if err != nil {
+			return err
+		}
+		outcome, err = s.finalize(tx, assessment)
+		return err
+	})
+	return outcome, err
+}
+
+func (s *AssessmentService) finalize(tx *gorm.DB, assessment *models.Assessment) (*FinalizeOutcome, error) {
+	if assessment.Status != models.AssessmentStatusInProgress {
+		return nil, ErrAssessmentClosed
+	}
+
+	var answers []models.Answer
+	if err := tx.Where("assessment_id = ?", assessment.ID).Find(&answers).Error; err != nil {
+		return nil, err
+	}
+	if len(answers) == 0 {
+		return nil, ErrNoAnswers
+	}
+	// 自适应测验须达到停止条件并答完全部风险条目，否则θ的误差过大，风险筛查也可能被跳过
+	if assessment.Mode == models.AssessmentModeAdaptive {
+		step, err := s.adaptiveStep(tx, assessment)
+		if err != nil {
+			return nil, err
+		}
+		if !step.Done {
+			return nil, ErrAdaptiveNotDone
+		}
+	}
+
+	result, err := s.score(tx, assessment, answers)
+	if err != nil {
+		return nil, err
+	}
+	totalScore := result.Score
+
+	quality, err := s.evaluateQuality(tx, assessment, answers)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"total_score":        totalScore,
+		"max_score":          result.MaxScore,
+		"level":              result.Level,
+		"result":             result.Description,
+		"scoring_profile_id": result.ScoringProfileID,
+		"status":             models.AssessmentStatusCompleted,
+		"completed_at":       now,
+		"expires_at":         nil,
+		"quality_flag":       assessment.QualityFlag,
+		"quality_confidence": assessment.QualityConfidence,
+		"quality_reasons":    assessment.QualityReasons,
+	}
+	if result.Theta != nil {
+		updates["theta_estimate"] = *result.Theta
+		updates["theta_se"] = *result.ThetaSE
+		assessment.ThetaEstimate = result.Theta
+		assessment.ThetaSE = result.ThetaSE
+	}
+	if err := tx.Model(assessment).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+	assessment.TotalScore = totalScore
+	assessment.MaxScore = result.MaxScore
+	assessment.Level = result.Level
+	assessment.Result = result.Description
+	assessment.ScoringProfileID = result.ScoringProfileID
+	assessment.Status = models.AssessmentStatusCompleted
+	assessment.CompletedAt = &now
+	assessment.ExpiresAt = nil
+
+	// 风险条目的结果不能被总分掩盖，单独返回危机信息
+	crisis, err := s.crisis.CrisisInfo(tx, assessment.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FinalizeOutcome{
+		Assessment: assessment,
+		Result:     result,
+		Crisis:     crisis,
+		Quality:    quality,
+	}, nil
+}
+
+// SubmitAll 一次性提交全部答案：在同一事务内创建草稿、保存答案并完成计分。
+// 客户端按随机顺序展示题目时须提供展示时使用的顺序和种子，以便还原每道题的位置
+func (s *AssessmentService) SubmitAll(userID uint, draft models.AssessmentCreateRequest, reqs []models.AnswerRequest) (*FinalizeOutcome, error) {
+	if draft.OrderMode != "" && draft.OrderMode != models.QuestionOrderFixed && draft.OrderSeed == nil {
+		return nil, ErrOrderSeedRequired
+	}
+	draft.Mode = models.AssessmentModeFixed
+
+	var outcome *FinalizeOutcome
+	var events []models.RiskEvent
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		assessment, err := s.createDraft(tx, userID, draft)
+		if err != nil {
+			return err
+		}
+		for _, req := range reqs {
+			_, event, err := s.saveAnswer(tx, assessment, req)
+			if err != nil {
+				return err
+			}
+			if event != nil {
+				events = append(events, *event)
+			}
+		}
+		outcome, err = s.finalize(tx, assessment)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.crisis.Dispatch(events)
+	return outcome, nil
+}
+
+// Rescore 按评估作答时的问卷版本和当前计分方案重新计分（用于计分规则修正后的重算），返回新结果
+func (s *AssessmentService) Rescore(actx AuditContext, assessmentID uint) (*FinalizeOutcome, error) {
+	var outcome *FinalizeOutcome
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var assessment models.Assessment
+		if err := tx.First(&assessment, assessmentID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrAssessmentNotFound
+			}
+			return err
+		}
+		if assessment.Status != models.AssessmentStatusCompleted {
+			return ErrAssessmentNotCompleted
+		}
+		if !isQuestionnaireType(assessment.Type) {
+			return ErrNotQuestionnaire
+		}
+		before := assessment
+
+		var answers []models.Answer
+		if err := tx.Where("assessment_id = ?", assessment.ID).Find(&answers).Error; err != nil {
+			return err
+		}
+
+		for i := range answers {
+			answer := &answers[i]
+			// 版本化之前的答案没有记录选项值，保留原得分
+			if answer.QuestionRevisionID != 0 && answer.AnswerValue != 0 {
+				var revision models.QuestionRevision
+				if err := tx.First(&revision, answer.QuestionRevisionID).Error; err != nil {
+					return err
+				}
+				score := CalculateQuestionScore(revision.Score, answer.AnswerValue)
+				if score != answer.Score {
+					if err := tx.Model(answer).Update("score", score).Error; err != nil {
+						return err
+					}
+					answer.Score = score
+				}
+			}
+		}
+
+		// 按当前的计分方案重新划分等级，自适应测验按作答时的IRT参数重新估计θ
+		result, err := s.score(tx, &assessment, answers)
+		if err != nil {
+			return err
+		}
+		updates := map[string]interface{}{
+			"total_score":        result.Score,
+			"max_score":          result.MaxScore,
+			"level":              result.Level,
+			"result":             result.Description,
+			"scoring_profile_id": result.ScoringProfileID,
+		}
+		if result.Theta != nil {
+			updates["theta_estimate"] = *result.Theta
+			updates["theta_se"] = *result.ThetaSE
+			assessment.ThetaEstimate = result.Theta
+			assessment.ThetaSE = result.ThetaSE
+		}
+
+		// 题目的配对设置可能已修正，同时重新检查作答质量
+		quality, err := s.evaluateQuality(tx, &assessment, answers)
+		if err != nil {
+			return err
+		}
+		updates["quality_flag"] = assessment.QualityFlag
+		updates["quality_confidence"] = assessment.QualityConfidence
+		updates["quality_reasons"] = assessment.QualityReasons
+
+		if err := tx.Model(&assessment).Updates(updates).Error; err != nil {
+			return err
+		}
+		assessment.TotalScore = result.Score
+		assessment.MaxScore = result.MaxScore
+		assessment.Level = result.Level
+		assessment.Result = result.Description
+		assessment.ScoringProfileID = result.ScoringProfileID
+
+		if err := RecordAudit(tx, actx, "rescore", "assessment", assessment.ID, before, assessment); err != nil {
+			return err
+		}
+
+		crisis, err := s.crisis.CrisisInfo(tx, assessment.ID)
+		if err != nil {
+			return err
+		}
+		outcome = &FinalizeOutcome{Assessment: &assessment, Result: result, Crisis: crisis, Quality: quality}
+		return nil
+	})
+	return outcome, err
+}
+
+// ExpireDrafts 将超时未完成的草稿标记为过期，返回处理条数
+func (s *AssessmentService) ExpireDrafts() (int64, error) {
+	result := s.db.Model(&models.Assessment{}).
+		Where("status = ? AND expires_at IS NOT NULL AND expires_at < ?", models.AssessmentStatusInProgress, time.Now()).
+		Update("status", models.AssessmentStatusExpired)
+	return result.RowsAffected, result.Error
+}
+
+// StartDraftExpiry 后台定期清理过期草稿，ctx取消时退出
+func (s *AssessmentService) StartDraftExpiry(ctx context.Context) {
+	interval := 30 * time.Minute
+	if configs.GlobalConfig != nil && configs.GlobalConfig.Assessment.ExpiryCheckInterval > 0 {
+		interval = time.Duration(configs.GlobalConfig.Assessment.ExpiryCheckInterval) * time.Minute
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				count, err := s.ExpireDrafts()
+				if err != nil {
+					log.Printf("清理过期评估草稿失败: %v", err)
+				} else if count > 0 {
+					log.Printf("已将%d个评估草稿标记为过期", count)
+				}
+			}
+		}
+	}()
+}
+
+// score 按当前计分方案计算评估结果：完整问卷的满分按作答时的问卷版本计算，
+// 自适应测验将θ映射为0-100分
+func (s *AssessmentService) score(tx *gorm.DB, assessment *models.Assessment, answers []models.Answer) (models.AssessmentResult, error) {
+	profile, err := s.scoring.Current(tx)
+	if err != nil {
+		return models.AssessmentResult{}, err
+	}
+	if assessment.Mode == models.AssessmentModeAdaptive {
+		return s.adaptiveResult(tx, assessment, profile)
+	}
+
+	var totalScore int
+	for _, answer := range answers {
+		totalScore += answer.Score
+	}
+	maxScore, err := s.maxScore(tx, assessment, answers)
+	if err != nil {
+		return models.AssessmentResult{}, err
+	}
+	return CalculateAssessmentResult(profile, totalScore, maxScore), nil
+}
+
+// maxScore 评估的满分：按作答时问卷版本的全部题目计算；
+// 版本化之前的评估没有题目集合，按已作答的题目计算
+func (s *AssessmentService) maxScore(tx *gorm.DB, assessment *models.Assessment, answers []models.Answer) (int, error) {
+	var questions []models.Question
+	if assessment.QuestionnaireVersionID != 0 {
+		versioned, err := s.versions.Questions(tx, assessment.QuestionnaireVersionID)
+		if err != nil {
+			return 0, err
+		}
+		for _, q := range versioned {
+			questions = append(questions, q.Question)
+		}
+	} else {
+		var err error
+		if questions, err = s.versions.AnsweredQuestions(tx, answers); err != nil {
+			return 0, err
+		}
+	}
+	return MaxScore(questions), nil
+}
+
+// CalculateAssessmentResult 按计分方案计算评估结果：等级由得分占满分的百分比决定，
+// 描述和建议使用默认语言（存储用），展示时按请求语言重新获取
+func CalculateAssessmentResult(profile *models.ScoringProfile, totalScore, maxScore int) models.AssessmentResult {
+	percentage := scorePercentage(totalScore, maxScore)
+	bands := ParseScoreBands(profile)
+	band := findBand(bands, percentage)
+
+	result := models.AssessmentResult{
+		Level:       band.Level,
+		Score:       totalScore,
+		MaxScore:    maxScore,
+		Percentage:  percentage,
+		Description: i18n.T(i18n.Default, "assessment."+band.Level+".description"),
+		Suggestions: i18n.T(i18n.Default, "assessment."+band.Level+".suggestions"),
+	}
+	if text, ok := bandText(bands, band.Level, i18n.Default); ok {
+		result.Description = text.Description
+		result.Suggestions = text.Suggestions
+	}
+	if profile != nil {
+		result.ScoringProfileID = profile.ID
+	}
+	return result
+}
+
+// GetAnswerText 获取选项对应的文本内容
+func GetAnswerText(question models.Question, answerValue int) string {
+	// 解析问题的选项列表（假设options字段是JSON字符串）
+	var options []string
+	if err := json.Unmarshal([]byte(question.Options), &options); err != nil {
+		return fmt.Sprintf("选项%d", answerValue) // 解析失败时返回默认值
+	}
+
+	// 检查选项值是否有效（数组索引从0开始）
+	if answerValue > 0 && answerValue <= len(options) {
+		return options[answerValue-1]
+	}
+
+	return fmt.Sprintf("无效选项%d", answerValue)
+}
+
+// CalculateQuestionScore 计算单题得分
+func CalculateQuestionScore(questionWeight int, answerValue int) int {
+	return answerValue * questionWeight
+}
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"encoding/json"
+
+	"depression_go/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// AuditContext 审计上下文：操作人与来源
+type AuditContext struct {
+	ActorID uint
+	IP      string
+}
+
+// RecordAudit 在事务内写入审计日志，before/after为nil时对应字段留空
+func RecordAudit(tx *gorm.DB, actx AuditContext, action, resourceType string, resourceID uint, before, after interface{}) error {
+	entry := models.AuditLog{
+		ActorID:      actx.ActorID,
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		IP:           actx.IP,
+	}
+	if before != nil {
+		data, err := json.Marshal(before)
+		if err != nil {
+			return err
+		}
+		entry.Before = string(data)
+	}
+	if after != nil {
+		data, err := json.Marshal(after)
+		if err != nil {
+			return err
+		}
+		entry.After = string(data)
+	}
+	return tx.Create(&entry).Error
+}
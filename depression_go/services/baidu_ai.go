@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"depression_go/internal/models"
+	"depression_go/pkg/i18n"
 )
 
 // BaiduAIService 百度AI服务
@@ -134,71 +135,73 @@ func (s *BaiduAIService) AnalyzeEmotion(imagePath string) (*models.EmotionResult
 	}, nil
 }
 
-// calculateEmotionScore 计算情绪得分和等级
+// calculateEmotionScore 计算情绪得分和等级，描述使用默认语言
 func (s *BaiduAIService) calculateEmotionScore(emotion string, confidence float64) (int, string, string) {
 	var score int
 	var level string
-	var description string
 	switch emotion {
 	case "sad":
 		score = int(confidence * 100)
 		if score >= 80 {
 			level = "severe"
-			description = "检测到明显的悲伤情绪，建议寻求专业心理咨询"
 		} else if score >= 60 {
 			level = "moderate"
-			description = "检测到中等程度的悲伤情绪，建议适当调节心情"
 		} else if score >= 40 {
 			level = "mild"
-			description = "检测到轻微的悲伤情绪，属于正常范围"
 		} else {
 			level = "normal"
-			description = "情绪状态正常"
 		}
 	case "angry":
 		score = int(confidence * 90)
 		if score >= 70 {
 			level = "moderate"
-			description = "检测到愤怒情绪，建议冷静处理"
 		} else {
 			level = "mild"
-			description = "检测到轻微愤怒，属于正常情绪波动"
 		}
 	case "fear":
 		score = int(confidence * 85)
 		if score >= 70 {
 			level = "moderate"
-			description = "检测到恐惧情绪，建议寻求支持"
 		} else {
 			level = "mild"
-			description = "检测到轻微恐惧，属于正常反应"
 		}
 	case "disgust":
 		score = int(confidence * 80)
 		level = "mild"
-		description = "检测到厌恶情绪，属于正常反应"
 	case "surprise":
 		score = int(confidence * 60)
 		level = "normal"
-		description = "检测到惊讶情绪，属于正常反应"
 	case "happy":
 		score = int(confidence * 50)
 		level = "normal"
-		description = "检测到快乐情绪，情绪状态良好"
 	default: // neutral
 		score = int(confidence * 30)
 		level = "normal"
-		description = "情绪状态平静，属于正常范围"
 	}
-	return score, level, description
+	return score, level, i18n.T(i18n.Default, EmotionTextKey(emotion, level))
+}
+
+// EmotionTextKey 情绪描述文本的key，未知情绪按平静处理
+func EmotionTextKey(emotion, level string) string {
+	switch emotion {
+	case "sad", "angry", "fear", "disgust", "surprise", "happy":
+		return "emotion." + emotion + "." + level
+	default:
+		return "emotion.neutral.normal"
+	}
+}
+
+// UploadDir 上传文件的保存目录，由UPLOAD_PATH配置
+func UploadDir() string {
+	if uploadPath := os.Getenv("UPLOAD_PATH"); uploadPath != "" {
+		return uploadPath
+	}
+	return "./uploads"
 }
 
 // SaveImage 保存上传的图片
 func (s *BaiduAIService) SaveImage(file io.Reader, filename string) (string, error) {
-	uploadPath := os.Getenv("UPLOAD_PATH")
-	if uploadPath == "" {
-		uploadPath = "./uploads"
-	}
+	uploadPath := UploadDir()
 	if err := os.MkdirAll(uploadPath, 0755); err != nil {
 		return "", fmt.Errorf("创建上传目录失败: %v", err)
 	}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"encoding/json"
+	"math"
+
+	"depression_go/configs"
+	"depression_go/internal/models"
+)
+
+// 自适应测验停止原因
+const (
+	CATStopTargetSE  = "target_se"      // θ估计的标准误达到目标
+	CATStopMaxItems  = "max_items"      // 达到最多题数
+	CATStopExhausted = "pool_exhausted" // 题库中已没有可选的题目
+)
+
+// θ的数值积分网格，先验为标准正态分布
+const (
+	thetaMin  = -4.0
+	thetaMax  = 4.0
+	thetaStep = 0.05
+)
+
+// CATSettings 自适应测验参数
+type CATSettings struct {
+	TargetSE float64
+	MinItems int
+	MaxItems int
+}
+
+// CATStep 自适应测验的下一步：下一道题或已结束
+type CATStep struct {
+	Done       bool               // 是否可以结束作答
+	StopReason string             // 结束原因
+	Question   *VersionedQuestion // 下一道题，结束时为空
+	Theta      float64            // 当前θ估计
+	ThetaSE    float64            // 当前θ估计的标准误
+	Answered   int                // 已作答题数
+}
+
+// irtItem 等级反应模型（GRM）的题目参数
+type irtItem struct {
+	a float64   // 区分度
+	b []float64 // 各类别间的阈值，递增
+}
+
+// irtResponse 一次作答：题目参数与作答类别（0开始）
+type irtResponse struct {
+	item     irtItem
+	category int
+}
+
+// catSettings 读取自适应测验配置
+func catSettings() CATSettings {
+	settings := CATSettings{TargetSE: 0.3, MinItems: 3, MaxItems: 12}
+	if configs.GlobalConfig != nil {
+		cfg := configs.GlobalConfig.Assessment
+		if cfg.CATTargetSE > 0 {
+			settings.TargetSE = cfg.CATTargetSE
+		}
+		if cfg.CATMinItems > 0 {
+			settings.MinItems = cfg.CATMinItems
+		}
+		if cfg.CATMaxItems > 0 {
+			settings.MaxItems = cfg.CATMaxItems
+		}
+	}
+	return settings
+}
+
+// parseIRTItem 解析题目的IRT参数，区分度为0或参数不完整时不参与自适应测验
+func parseIRTItem(question models.Question) (irtItem, bool) {
+	if question.IRTDiscrimination <= 0 || question.IRTThresholds == "" {
+		return irtItem{}, false
+	}
+	var thresholds []float64
+	if err := json.Unmarshal([]byte(question.IRTThresholds), &thresholds); err != nil || len(thresholds) == 0 {
+		return irtItem{}, false
+	}
+	return irtItem{a: question.IRTDiscrimination, b: thresholds}, true
+}
+
+// cumulative P*_k(θ)：作答类别不低于k的概率，P*_0=1，P*_K=0
+func (it irtItem) cumulative(theta float64, k int) float64 {
+	if k <= 0 {
+		return 1
+	}
+	if k > len(it.b) {
+		return 0
+	}
+	return 1 / (1 + math.Exp(-it.a*(theta-it.b[k-1])))
+}
+
+// probability 作答为类别k的概率
+func (it irtItem) probability(theta float64, k int) float64 {
+	return it.cumulative(theta, k) - it.cumulative(theta, k+1)
+}
+
+// information 题目在θ处的Fisher信息量
+func (it irtItem) information(theta float64) float64 {
+	var info float64
+	for k := 0; k <= len(it.b); k++ {
+		upper, lower := it.cumulative(theta, k), it.cumulative(theta, k+1)
+		p := upper - lower
+		if p <= 1e-12 {
+			continue
+		}
+		derivative := it.a * (upper*(1-upper) - lower*(1-lower))
+		info += derivative * derivative / p
+	}
+	return info
+}
+
+// estimateTheta 以EAP（后验均值）估计θ，标准误为后验标准差；没有作答时返回先验（0, 1）
+func estimateTheta(responses []irtResponse) (float64, float64) {
+	var sumW, sumWT, sumWT2 float64
+	for theta := thetaMin; theta <= thetaMax+1e-9; theta += thetaStep {
+		weight := math.Exp(-theta * theta / 2)
+		for _, r := range responses {
+			weight *= math.Max(r.item.probability(theta, r.category), 1e-300)
+		}
+		sumW += weight
+		sumWT += weight * theta
+		sumWT2 += weight * theta * theta
+	}
+	if sumW == 0 {
+		return 0, 1
+	}
+	mean := sumWT / sumW
+	variance := sumWT2/sumW - mean*mean
+	return mean, math.Sqrt(math.Max(variance, 0))
+}
+
+// ThetaToScore 将θ映射到0-100的分数（θ从-3到3线性映射），以便沿用分数等级
+func ThetaToScore(theta float64) int {
+	score := int(math.Round((theta + 3) / 6 * 100))
+	if score < 0 {
+		return 0
+	}
+	if score > 100 {
+		return 100
+	}
+	return score
+}
+
+// nextAdaptiveStep 根据已作答情况选择下一题：
+// 满足停止条件前选择在当前θ处信息量最大的题目；停止条件满足后，
+// 尚未作答的风险条目仍会依次出题，保证风险筛查不被跳过
+func nextAdaptiveStep(questions []VersionedQuestion, answers map[uint]int, settings CATSettings) CATStep {
+	var responses []irtResponse
+	for _, q := range questions {
+		value, answered := answers[q.Question.ID]
+		if !answered {
+			continue
+		}
+		if item, ok := parseIRTItem(q.Question); ok && value >= 1 && value <= len(item.b)+1 {
+			responses = append(responses, irtResponse{item: item, category: value - 1})
+		}
+	}
+	theta, se := estimateTheta(responses)
+	step := CATStep{Theta: theta, ThetaSE: se, Answered: len(answers)}
+
+	switch {
+	case len(responses) >= settings.MinItems && se <= settings.TargetSE:
+		step.StopReason = CATStopTargetSE
+	case len(answers) >= settings.MaxItems:
+		step.StopReason = CATStopMaxItems
+	}
+
+	if step.StopReason == "" {
+		var best *VersionedQuestion
+		bestInfo := -1.0
+		for i := range questions {
+			q := &questions[i]
+			if _, answered := answers[q.Question.ID]; answered {
+				continue
+			}
+			item, ok := parseIRTItem(q.Question)
+			if !ok {
+				continue
+			}
+			if info := item.information(theta); info > bestInfo {
+				best, bestInfo = q, info
+			}
+		}
+		if best != nil {
+			step.Question = best
+			return step
+		}
+		step.StopReason = CATStopExhausted
+	}
+
+	for i := range questions {
+		q := &questions[i]
+		if _, answered := answers[q.Question.ID]; !answered && q.Question.RiskItem {
+			step.Question = q
+			return step
+		}
+	}
+	step.Done = true
+	return step
+}
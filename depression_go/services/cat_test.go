@@ -0,0 +1,85 @@
+package services
+
+import (
+	"math"
+	"testing"
+)
+
+func TestIRTItemInformation(t *testing.T) {
+	tests := []struct {
+		name  string
+		item  irtItem
+		theta float64
+		want  float64
+	}{
+		// 两类别题目的信息量为 a²·P·(1-P)，在阈值处P=0.5
+		{"二分类在阈值处", irtItem{a: 1, b: []float64{0}}, 0, 0.25},
+		{"区分度加倍信息量为四倍", irtItem{a: 2, b: []float64{0}}, 0, 1},
+		{"阈值平移", irtItem{a: 1, b: []float64{1}}, 1, 0.25},
+		{"远离阈值信息量很小", irtItem{a: 1, b: []float64{0}}, 4, 1 / (1 + math.Exp(-4)) * (1 - 1/(1+math.Exp(-4)))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.item.information(tt.theta); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("information(%v) = %v, want %v", tt.theta, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIRTItemInformationPeaksNearThresholds(t *testing.T) {
+	item := irtItem{a: 1.5, b: []float64{-1, 0, 1}}
+	center := item.information(0)
+	for _, theta := range []float64{-3, -2, 2, 3} {
+		if info := item.information(theta); info >= center {
+			t.Errorf("information(%v) = %v, want < information(0) = %v", theta, info, center)
+		}
+	}
+}
+
+func TestEstimateTheta(t *testing.T) {
+	item := irtItem{a: 1.5, b: []float64{-1, 0, 1}}
+	tests := []struct {
+		name      string
+		responses []irtResponse
+		wantMin   float64
+		wantMax   float64
+		maxSE     float64
+	}{
+		{"没有作答时为先验", nil, -0.01, 0.01, 1},
+		{"全部选最低类别", []irtResponse{{item, 0}, {item, 0}, {item, 0}}, -4, -1, 0.9},
+		{"全部选最高类别", []irtResponse{{item, 3}, {item, 3}, {item, 3}}, 1, 4, 0.9},
+		{"中间类别", []irtResponse{{item, 1}, {item, 2}}, -0.5, 0.5, 0.9},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			theta, se := estimateTheta(tt.responses)
+			if theta < tt.wantMin || theta > tt.wantMax {
+				t.Errorf("theta = %v, want in [%v, %v]", theta, tt.wantMin, tt.wantMax)
+			}
+			if se <= 0 || se > tt.maxSE {
+				t.Errorf("se = %v, want in (0, %v]", se, tt.maxSE)
+			}
+		})
+	}
+}
+
+func TestEstimateThetaSymmetricAndShrinkingSE(t *testing.T) {
+	item := irtItem{a: 1.2, b: []float64{-0.5, 0.5}}
+	low, _ := estimateTheta([]irtResponse{{item, 0}, {item, 0}})
+	high, _ := estimateTheta([]irtResponse{{item, 2}, {item, 2}})
+	if math.Abs(low+high) > 1e-6 {
+		t.Errorf("对称作答的估计应互为相反数: low = %v, high = %v", low, high)
+	}
+
+	var responses []irtResponse
+	previous := math.Inf(1)
+	for i := 0; i < 5; i++ {
+		responses = append(responses, irtResponse{item, 1})
+		_, se := estimateTheta(responses)
+		if se >= previous {
+			t.Errorf("第%d题后标准误 %v 未减小（之前 %v）", i+1, se, previous)
+		}
+		previous = se
+	}
+}
@@ -0,0 +1,444 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"depression_go/configs"
+	"depression_go/internal/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidCheckin = errors.New("打卡数据不合法")
+	ErrCheckinLimit   = errors.New("今天的打卡次数已达上限")
+	ErrNoCheckinItems = errors.New("没有启用的打卡题目")
+)
+
+const (
+	// checkinDateLayout 按天汇总使用的日期格式
+	checkinDateLayout = "2006-01-02"
+	// checkinTrendThreshold 斜率绝对值低于该值（每天变化的选项值）视为平稳
+	checkinTrendThreshold = 0.05
+)
+
+// defaultCheckinItems 初始打卡题目：心情（1-10滑块）、睡眠、精力
+var defaultCheckinItems = []models.Question{
+	{
+		Title:   "此刻的心情如何？",
+		Type:    models.QuestionTypeSingle,
+		Options: `["1","2","3","4","5","6","7","8","9","10"]`,
+	},
+	{
+		Title:   "昨晚睡得怎么样？",
+		Type:    models.QuestionTypeSingle,
+		Options: `["很差","较差","一般","较好","很好"]`,
+	},
+	{
+		Title:   "现在精力如何？",
+		Type:    models.QuestionTypeSingle,
+		Options: `["非常疲惫","有些疲惫","一般","比较充沛","非常充沛"]`,
+	},
+}
+
+// CheckinService 每日打卡服务：轻量的心情、睡眠、精力打卡，按天汇总并统计连续天数和趋势
+type CheckinService struct {
+	db           *gorm.DB
+	maxPerDay    int
+	versions     *QuestionnaireVersionService
+	localization *LocalizationService
+}
+
+// checkinAnswer 打卡答案及打卡时间
+type checkinAnswer struct {
+	AssessmentID uint
+	QuestionID   uint
+	AnswerValue  int
+	CreatedAt    time.Time
+}
+
+// NewCheckinService 创建每日打卡服务
+func NewCheckinService(db *gorm.DB) *CheckinService {
+	s := &CheckinService{
+		db:           db,
+		maxPerDay:    5,
+		versions:     NewQuestionnaireVersionService(db),
+		localization: NewLocalizationService(db),
+	}
+	if configs.GlobalConfig != nil && configs.GlobalConfig.Checkin.MaxPerDay > 0 {
+		s.maxPerDay = configs.GlobalConfig.Checkin.MaxPerDay
+	}
+	return s
+}
+
+// Items 获取启用的打卡题目，题库中从未有过打卡题目时写入初始题目
+func (s *CheckinService) Items(locale string) ([]models.Question, error) {
+	var questions []models.Question
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		questions, err = s.items(tx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.localization.LocalizeQuestions(locale, questions); err != nil {
+		return nil, err
+	}
+	return questions, nil
+}
+
+// items 启用的打卡题目，必要时写入初始题目
+func (s *CheckinService) items(tx *gorm.DB) ([]models.Question, error) {
+	var total int64
+	if err := tx.Unscoped().Model(&models.Question{}).Where("category = ?", models.QuestionCategoryCheckin).Count(&total).Error; err != nil {
+		return nil, err
+	}
+	if total == 0 {
+		for i, item := range defaultCheckinItems {
+			question := item
+			question.Category = models.QuestionCategoryCheckin
+			question.Score = 1
+			question.OrderNum = i + 1
+			if err := tx.Create(&question).Error; err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var questions []models.Question
+	err := tx.Where("category = ? AND status = ?", models.QuestionCategoryCheckin, 1).
+		Order("order_num ASC, id ASC").
+		Find(&questions).Error
+	return questions, err
+}
+
+// Submit 提交一次打卡，保存为类型为checkin的已完成评估
+func (s *CheckinService) Submit(userID uint, req models.CheckinRequest) (*models.Assessment, []models.Answer, error) {
+	var assessment models.Assessment
+	var answers []models.Answer
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		items, err := s.items(tx)
+		if err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			return ErrNoCheckinItems
+		}
+		byID := make(map[uint]*models.Question, len(items))
+		for i := range items {
+			byID[items[i].ID] = &items[i]
+		}
+
+		seen := make(map[uint]bool, len(req.Answers))
+		for _, answer := range req.Answers {
+			question, ok := byID[answer.QuestionID]
+			if !ok {
+				return fmt.Errorf("%w: 问题%d不是启用的打卡题目", ErrInvalidCheckin, answer.QuestionID)
+			}
+			if seen[answer.QuestionID] {
+				return fmt.Errorf("%w: 问题%d重复作答", ErrInvalidCheckin, answer.QuestionID)
+			}
+			seen[answer.QuestionID] = true
+			if answer.AnswerValue < 1 || answer.AnswerValue > optionCount(*question) {
+				return fmt.Errorf("%w: 问题%d的选项值超出范围", ErrInvalidCheckin, answer.QuestionID)
+			}
+		}
+
+		// 每天的打卡次数有上限，避免重复提交放大当天的权重
+		var today int64
+		if err := tx.Model(&models.Assessment{}).
+			Where("user_id = ? AND type = ? AND created_at >= ?", userID, models.AssessmentTypeCheckin, startOfDay(time.Now())).
+			Count(&today).Error; err != nil {
+			return err
+		}
+		if int(today) >= s.maxPerDay {
+			return ErrCheckinLimit
+		}
+
+		now := time.Now()
+		assessment = models.Assessment{
+			UserID:      userID,
+			Title:       "每日打卡",
+			Type:        models.AssessmentTypeCheckin,
+			Status:      models.AssessmentStatusCompleted,
+			CompletedAt: &now,
+			Mode:        models.AssessmentModeFixed,
+			OrderMode:   models.QuestionOrderFixed,
+		}
+		if err := tx.Create(&assessment).Error; err != nil {
+			return err
+		}
+
+		for i, item := range req.Answers {
+			question := byID[item.QuestionID]
+			// 记录作答时的题目修订，之后修改打卡题目不影响历史记录的展示
+			revision, err := s.versions.ensureRevision(tx, question)
+			if err != nil {
+				return err
+			}
+			answer := models.Answer{
+				UserID:             userID,
+				QuestionID:         question.ID,
+				AssessmentID:       assessment.ID,
+				Content:            GetAnswerText(*question, item.AnswerValue),
+				Score:              CalculateQuestionScore(question.Score, item.AnswerValue),
+				AnswerValue:        item.AnswerValue,
+				QuestionRevisionID: revision.ID,
+				ResponseTimeMs:     max(item.ResponseTimeMs, 0),
+				Position:           i + 1,
+			}
+			if err := tx.Create(&answer).Error; err != nil {
+				return err
+			}
+			assessment.TotalScore += answer.Score
+			assessment.MaxScore += CalculateQuestionScore(question.Score, optionCount(*question))
+			answers = append(answers, answer)
+		}
+
+		return tx.Model(&assessment).Updates(map[string]interface{}{
+			"total_score": assessment.TotalScore,
+			"max_score":   assessment.MaxScore,
+		}).Error
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return &assessment, answers, nil
+}
+
+// Daily 最近days天（含今天）每天的打卡汇总，按日期倒序，没有打卡的日期不返回
+func (s *CheckinService) Daily(userID uint, days int, locale string) ([]models.CheckinDay, error) {
+	answers, err := s.answersSince(userID, startOfDay(time.Now()).AddDate(0, 0, 1-days))
+	if err != nil {
+		return nil, err
+	}
+	titles, err := s.titles(answers, locale)
+	if err != nil {
+		return nil, err
+	}
+
+	type sum struct {
+		total float64
+		count int
+	}
+	checkins := make(map[string]map[uint]bool)
+	sums := make(map[string]map[uint]*sum)
+	for _, answer := range answers {
+		date := answer.CreatedAt.In(time.Local).Format(checkinDateLayout)
+		if checkins[date] == nil {
+			checkins[date] = make(map[uint]bool)
+			sums[date] = make(map[uint]*sum)
+		}
+		checkins[date][answer.AssessmentID] = true
+		if sums[date][answer.QuestionID] == nil {
+			sums[date][answer.QuestionID] = &sum{}
+		}
+		sums[date][answer.QuestionID].total += float64(answer.AnswerValue)
+		sums[date][answer.QuestionID].count++
+	}
+
+	result := make([]models.CheckinDay, 0, len(sums))
+	for date, items := range sums {
+		day := models.CheckinDay{Date: date, Checkins: len(checkins[date])}
+		for questionID, item := range items {
+			day.Items = append(day.Items, models.CheckinItemAverage{
+				QuestionID: questionID,
+				Title:      titles[questionID],
+				Average:    roundTo(item.total/float64(item.count), 2),
+				Count:      item.count,
+			})
+		}
+		sort.Slice(day.Items, func(i, j int) bool { return day.Items[i].QuestionID < day.Items[j].QuestionID })
+		result = append(result, day)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Date > result[j].Date })
+	return result, nil
+}
+
+// Streak 连续打卡天数
+func (s *CheckinService) Streak(userID uint) (*models.CheckinStreak, error) {
+	var times []time.Time
+	if err := s.db.Model(&models.Assessment{}).
+		Where("user_id = ? AND type = ?", userID, models.AssessmentTypeCheckin).
+		Order("created_at ASC").
+		Pluck("created_at", &times).Error; err != nil {
+		return nil, err
+	}
+
+	streak := &models.CheckinStreak{}
+	dates := make(map[string]bool)
+	var previous time.Time
+	run := 0
+	for _, t := range times {
+		day := startOfDay(t)
+		date := day.Format(checkinDateLayout)
+		if dates[date] {
+			continue
+		}
+		dates[date] = true
+		if !previous.IsZero() && previous.AddDate(0, 0, 1).Equal(day) {
+			run++
+		} else {
+			run = 1
+		}
+		previous = day
+		streak.Longest = max(streak.Longest, run)
+		streak.LastDate = date
+	}
+	streak.Total = len(dates)
+
+	// 今天还没打卡时，截至昨天的连续天数仍然有效
+	day := startOfDay(time.Now())
+	if !dates[day.Format(checkinDateLayout)] {
+		day = day.AddDate(0, 0, -1)
+	}
+	for dates[day.Format(checkinDateLayout)] {
+		streak.Current++
+		day = day.AddDate(0, 0, -1)
+	}
+	return streak, nil
+}
+
+// Trend 最近days天每道打卡题目的每日平均值及线性趋势
+func (s *CheckinService) Trend(userID uint, days int, locale string) ([]models.CheckinTrend, error) {
+	from := startOfDay(time.Now()).AddDate(0, 0, 1-days)
+	answers, err := s.answersSince(userID, from)
+	if err != nil {
+		return nil, err
+	}
+	titles, err := s.titles(answers, locale)
+	if err != nil {
+		return nil, err
+	}
+
+	type sum struct {
+		total float64
+		count int
+	}
+	sums := make(map[uint]map[string]*sum)
+	for _, answer := range answers {
+		date := answer.CreatedAt.In(time.Local).Format(checkinDateLayout)
+		if sums[answer.QuestionID] == nil {
+			sums[answer.QuestionID] = make(map[string]*sum)
+		}
+		if sums[answer.QuestionID][date] == nil {
+			sums[answer.QuestionID][date] = &sum{}
+		}
+		sums[answer.QuestionID][date].total += float64(answer.AnswerValue)
+		sums[answer.QuestionID][date].count++
+	}
+
+	trends := make([]models.CheckinTrend, 0, len(sums))
+	for questionID, byDate := range sums {
+		trend := models.CheckinTrend{QuestionID: questionID, Title: titles[questionID]}
+		var xs, ys []float64
+		for date, item := range byDate {
+			average := item.total / float64(item.count)
+			trend.Points = append(trend.Points, models.CheckinTrendPoint{Date: date, Average: roundTo(average, 2)})
+			day, _ := time.ParseInLocation(checkinDateLayout, date, time.Local)
+			xs = append(xs, math.Round(day.Sub(from).Hours()/24))
+			ys = append(ys, average)
+		}
+		sort.Slice(trend.Points, func(i, j int) bool { return trend.Points[i].Date < trend.Points[j].Date })
+
+		trend.Slope = roundTo(linearSlope(xs, ys), 3)
+		switch {
+		case trend.Slope > checkinTrendThreshold:
+			trend.Direction = models.TrendUp
+		case trend.Slope < -checkinTrendThreshold:
+			trend.Direction = models.TrendDown
+		default:
+			trend.Direction = models.TrendStable
+		}
+		trends = append(trends, trend)
+	}
+	sort.Slice(trends, func(i, j int) bool { return trends[i].QuestionID < trends[j].QuestionID })
+	return trends, nil
+}
+
+// answersSince 用户自from起的全部打卡答案
+func (s *CheckinService) answersSince(userID uint, from time.Time) ([]checkinAnswer, error) {
+	var answers []checkinAnswer
+	err := s.db.Table("answers").
+		Select("answers.assessment_id, answers.question_id, answers.answer_value, assessments.created_at").
+		Joins("JOIN assessments ON assessments.id = answers.assessment_id").
+		Where("assessments.user_id = ? AND assessments.type = ? AND assessments.created_at >= ?",
+			userID, models.AssessmentTypeCheckin, from).
+		Where("assessments.deleted_at IS NULL AND answers.deleted_at IS NULL").
+		Order("assessments.created_at ASC").
+		Scan(&answers).Error
+	return answers, err
+}
+
+// Titles 按请求语言获取打卡题目的标题，已删除的题目也保留标题
+func (s *CheckinService) Titles(questionIDs []uint, locale string) (map[uint]string, error) {
+	titles := make(map[uint]string, len(questionIDs))
+	if len(questionIDs) == 0 {
+		return titles, nil
+	}
+
+	var questions []models.Question
+	if err := s.db.Unscoped().Where("id IN ?", questionIDs).Find(&questions).Error; err != nil {
+		return nil, err
+	}
+	if err := s.localization.LocalizeQuestions(locale, questions); err != nil {
+		return nil, err
+	}
+	for _, question := range questions {
+		titles[question.ID] = question.Title
+	}
+	return titles, nil
+}
+
+// titles 打卡答案涉及的题目标题
+func (s *CheckinService) titles(answers []checkinAnswer, locale string) (map[uint]string, error) {
+	var ids []uint
+	seen := make(map[uint]bool)
+	for _, answer := range answers {
+		if !seen[answer.QuestionID] {
+			seen[answer.QuestionID] = true
+			ids = append(ids, answer.QuestionID)
+		}
+	}
+	return s.Titles(ids, locale)
+}
+
+// startOfDay 服务器时区下t所在日期的零点
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.In(time.Local).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.Local)
+}
+
+// linearSlope 最小二乘拟合的斜率，少于2个不同的x时为0
+func linearSlope(xs, ys []float64) float64 {
+	n := float64(len(xs))
+	if n < 2 {
+		return 0
+	}
+	var sumX, sumY float64
+	for i := range xs {
+		sumX += xs[i]
+		sumY += ys[i]
+	}
+	meanX, meanY := sumX/n, sumY/n
+	var cov, variance float64
+	for i := range xs {
+		cov += (xs[i] - meanX) * (ys[i] - meanY)
+		variance += (xs[i] - meanX) * (xs[i] - meanX)
+	}
+	if variance == 0 {
+		return 0
+	}
+	return cov / variance
+}
+
+// roundTo 保留digits位小数
+func roundTo(value float64, digits int) float64 {
+	factor := math.Pow(10, float64(digits))
+	return math.Round(value*factor) / factor
+}
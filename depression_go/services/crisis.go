@@ -0,0 +1,435 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"depression_go/configs"
+	"depression_go/internal/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrRiskEventNotFound   = errors.New("风险事件不存在")
+	ErrRiskEventTransition = errors.New("风险事件已解决或已处于该状态")
+)
+
+const (
+	auditResourceRiskEvent = "risk_event"
+
+	// riskStatusUnresolved 列表筛选：未解决（open和acknowledged）
+	riskStatusUnresolved = "unresolved"
+	// riskNotifyRetryInterval 重试未送达通知的间隔
+	riskNotifyRetryInterval = 5 * time.Minute
+	// riskNotifyGrace 事件创建后等待首次通知完成的时间，之后仍未送达的才由定时任务重试
+	riskNotifyGrace = time.Minute
+)
+
+// RiskEventFilter 风险事件列表的筛选条件，零值表示不限
+type RiskEventFilter struct {
+	Status      string // open, acknowledged, resolved, unresolved
+	UserID      uint
+	Source      string
+	Undelivered bool // 只看尚未送达通知的
+}
+
+// crisisMessage 触发危机流程时返回给用户的提示
+const crisisMessage = "我们注意到您可能正在经历非常困难的时刻。您并不孤单，请立即联系以下求助热线或身边信任的人；如有紧急危险，请拨打110或120。我们的专业人员也已收到通知，会尽快与您联系。"
+
+// defaultCrisisResources 未配置CRISIS_RESOURCES时使用的求助热线
+var defaultCrisisResources = []models.CrisisResource{
+	{Name: "全国心理援助热线", Phone: "12356"},
+	{Name: "希望24热线", Phone: "400-161-9995", Description: "24小时生命危机干预"},
+	{Name: "北京心理危机研究与干预中心", Phone: "010-82951332", Description: "24小时"},
+	{Name: "紧急求助", Phone: "110 / 120", Description: "遇到紧急危险时"},
+}
+
+// RiskNotifier 风险事件通知器，可按部署环境替换实现
+type RiskNotifier interface {
+	Notify(event models.RiskEvent, responders []configs.CrisisResponder) error
+}
+
+// LogNotifier 仅将风险事件写入日志，适用于本地开发
+type LogNotifier struct{}
+
+// Notify 记录风险事件日志
+func (LogNotifier) Notify(event models.RiskEvent, responders []configs.CrisisResponder) error {
+	for _, responder := range responders {
+		log.Printf("[危机通知] 风险事件#%d 用户#%d %s -> %s %s(%s)",
+			event.ID, event.UserID, riskOrigin(event),
+			responder.Role, responder.Name, responder.Contact)
+	}
+	if len(responders) == 0 {
+		log.Printf("[危机通知] 风险事件#%d 用户#%d %s 未配置响应人员", event.ID, event.UserID, riskOrigin(event))
+	}
+	return nil
+}
+
+// riskOrigin 风险事件来源的简短描述，用于日志
+func riskOrigin(event models.RiskEvent) string {
+	if event.JournalEntryID != nil {
+		return fmt.Sprintf("日记#%d", *event.JournalEntryID)
+	}
+	if event.AssessmentID != nil && event.QuestionID != nil {
+		return fmt.Sprintf("评估#%d 问题#%d", *event.AssessmentID, *event.QuestionID)
+	}
+	return event.Source
+}
+
+// WebhookNotifier 以JSON形式将风险事件推送到外部系统（值班平台、即时通讯机器人等）
+type WebhookNotifier struct {
+	URL    string
+	client *http.Client
+}
+
+// NewWebhookNotifier 创建webhook通知器
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{
+		URL:    url,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Notify 推送风险事件
+func (n *WebhookNotifier) Notify(event models.RiskEvent, responders []configs.CrisisResponder) error {
+	type responderPayload struct {
+		Role    string `json:"role"`
+		Name    string `json:"name"`
+		Contact string `json:"contact"`
+	}
+	payload := struct {
+		EventID        uint               `json:"event_id"`
+		UserID         uint               `json:"user_id"`
+		Source         string             `json:"source"`
+		AssessmentID   *uint              `json:"assessment_id,omitempty"`
+		QuestionID     *uint              `json:"question_id,omitempty"`
+		JournalEntryID *uint              `json:"journal_entry_id,omitempty"`
+		AnswerValue    int                `json:"answer_value"`
+		Answer         string             `json:"answer"`
+		CreatedAt      time.Time          `json:"created_at"`
+		Responders     []responderPayload `json:"responders"`
+	}{
+		EventID:        event.ID,
+		UserID:         event.UserID,
+		Source:         event.Source,
+		AssessmentID:   event.AssessmentID,
+		QuestionID:     event.QuestionID,
+		JournalEntryID: event.JournalEntryID,
+		AnswerValue:    event.AnswerValue,
+		Answer:         event.AnswerContent,
+		CreatedAt:      event.CreatedAt,
+	}
+	for _, responder := range responders {
+		payload.Responders = append(payload.Responders, responderPayload(responder))
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("序列化通知内容失败: %v", err)
+	}
+	resp, err := n.client.Post(n.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("发送webhook通知失败: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook返回异常状态码: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// NewRiskNotifier 根据配置创建通知器
+func NewRiskNotifier(cfg configs.CrisisConfig) RiskNotifier {
+	switch cfg.Notifier {
+	case "webhook":
+		if cfg.WebhookURL != "" {
+			return NewWebhookNotifier(cfg.WebhookURL)
+		}
+		log.Println("CRISIS_WEBHOOK_URL未配置，危机通知改为写入日志")
+	}
+	return LogNotifier{}
+}
+
+// CrisisService 危机干预服务：识别风险作答、记录风险事件并通知响应人员
+type CrisisService struct {
+	db         *gorm.DB
+	notifier   RiskNotifier
+	responders []configs.CrisisResponder
+	resources  []models.CrisisResource
+}
+
+// NewCrisisService 创建危机干预服务
+func NewCrisisService(db *gorm.DB) *CrisisService {
+	var cfg configs.CrisisConfig
+	if configs.GlobalConfig != nil {
+		cfg = configs.GlobalConfig.Crisis
+	}
+
+	resources := defaultCrisisResources
+	if len(cfg.Resources) > 0 {
+		resources = make([]models.CrisisResource, 0, len(cfg.Resources))
+		for _, resource := range cfg.Resources {
+			resources = append(resources, models.CrisisResource(resource))
+		}
+	}
+
+	return &CrisisService{
+		db:         db,
+		notifier:   NewRiskNotifier(cfg),
+		responders: cfg.Responders,
+		resources:  resources,
+	}
+}
+
+// SetNotifier 替换通知器
+func (s *CrisisService) SetNotifier(notifier RiskNotifier) {
+	s.notifier = notifier
+}
+
+// DetectRisk 检查作答是否触发风险条目，触发时在事务内创建风险事件；
+// 同一评估同一问题只记录一次，返回新创建的事件（未触发或已存在时为nil）
+func (s *CrisisService) DetectRisk(tx *gorm.DB, assessment *models.Assessment, question models.Question, answerValue int, content string) (*models.RiskEvent, error) {
+	if !question.IsRiskAnswer(answerValue) {
+		return nil, nil
+	}
+
+	if !assessment.RiskFlagged {
+		if err := tx.Model(assessment).Update("risk_flagged", true).Error; err != nil {
+			return nil, err
+		}
+		assessment.RiskFlagged = true
+	}
+
+	var existing models.RiskEvent
+	err := tx.Where("assessment_id = ? AND question_id = ?", assessment.ID, question.ID).First(&existing).Error
+	if err == nil {
+		return nil, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	event := models.RiskEvent{
+		UserID:        assessment.UserID,
+		Source:        models.RiskSourceAssessment,
+		AssessmentID:  &assessment.ID,
+		QuestionID:    &question.ID,
+		AnswerValue:   answerValue,
+		AnswerContent: content,
+		Status:        models.RiskEventStatusOpen,
+	}
+	if err := tx.Create(&event).Error; err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// DetectJournalRisk 日记中出现轻生表述时在事务内创建风险事件；
+// 同一篇日记有未解决的事件时不重复记录，返回新创建的事件（未命中或已存在时为nil）
+func (s *CrisisService) DetectJournalRisk(tx *gorm.DB, entry *models.JournalEntry, phrases []string) (*models.RiskEvent, error) {
+	if len(phrases) == 0 {
+		return nil, nil
+	}
+
+	var existing models.RiskEvent
+	err := tx.Where("journal_entry_id = ? AND status <> ?", entry.ID, models.RiskEventStatusResolved).First(&existing).Error
+	if err == nil {
+		return nil, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	event := models.RiskEvent{
+		UserID:         entry.UserID,
+		Source:         models.RiskSourceJournal,
+		JournalEntryID: &entry.ID,
+		AnswerContent:  truncate(strings.Join(phrases, "、"), 500),
+		Status:         models.RiskEventStatusOpen,
+	}
+	if err := tx.Create(&event).Error; err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// Dispatch 在事务提交后异步通知响应人员，并记录通知结果；失败的由StartNotifyRetry定期重试
+func (s *CrisisService) Dispatch(events []models.RiskEvent) {
+	for _, event := range events {
+		go func(event models.RiskEvent) {
+			s.deliver(event)
+		}(event)
+	}
+}
+
+// deliver 通知响应人员并记录结果，返回是否送达
+func (s *CrisisService) deliver(event models.RiskEvent) bool {
+	updates := map[string]interface{}{"notify_attempts": gorm.Expr("notify_attempts + 1")}
+	err := s.notifier.Notify(event, s.responders)
+	if err != nil {
+		log.Printf("风险事件#%d通知失败: %v", event.ID, err)
+		updates["notify_error"] = err.Error()
+	} else {
+		updates["notified_at"] = time.Now()
+		updates["notify_error"] = ""
+	}
+	if err := s.db.Model(&models.RiskEvent{}).Where("id = ?", event.ID).Updates(updates).Error; err != nil {
+		log.Printf("更新风险事件#%d通知状态失败: %v", event.ID, err)
+	}
+	return err == nil
+}
+
+// RetryUndelivered 重新通知尚未送达的待处理风险事件，返回本次送达的数量；
+// 刚创建的事件可能仍在首次通知中，跳过riskNotifyGrace内创建的事件
+func (s *CrisisService) RetryUndelivered() (int, error) {
+	var events []models.RiskEvent
+	if err := s.db.Where("notified_at IS NULL AND status = ? AND created_at <= ?",
+		models.RiskEventStatusOpen, time.Now().Add(-riskNotifyGrace)).
+		Order("id ASC").Find(&events).Error; err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for _, event := range events {
+		if s.deliver(event) {
+			delivered++
+		}
+	}
+	return delivered, nil
+}
+
+// StartNotifyRetry 后台定期重试未送达的风险事件通知，ctx取消时退出
+func (s *CrisisService) StartNotifyRetry(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(riskNotifyRetryInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				count, err := s.RetryUndelivered()
+				if err != nil {
+					log.Printf("重试风险事件通知失败: %v", err)
+				} else if count > 0 {
+					log.Printf("已补发%d个风险事件通知", count)
+				}
+			}
+		}
+	}()
+}
+
+// List 分页列出风险事件，按创建时间倒序，带上用户（便于联系）和触发的问题
+func (s *CrisisService) List(filter RiskEventFilter, page, pageSize int) ([]models.RiskEvent, int64, error) {
+	query := s.db.Model(&models.RiskEvent{})
+	switch filter.Status {
+	case "":
+	case riskStatusUnresolved:
+		query = query.Where("status <> ?", models.RiskEventStatusResolved)
+	default:
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.Source != "" {
+		query = query.Where("source = ?", filter.Source)
+	}
+	if filter.Undelivered {
+		query = query.Where("notified_at IS NULL")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var events []models.RiskEvent
+	err := query.Preload("User").Preload("Question").
+		Order("created_at DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&events).Error
+	return events, total, err
+}
+
+// SetStatus 响应人员接手或解决风险事件：open可以接手或直接解决，acknowledged只能解决，已解决的不能再修改
+func (s *CrisisService) SetStatus(actx AuditContext, eventID uint, req models.RiskEventStatusRequest) (*models.RiskEvent, error) {
+	var event models.RiskEvent
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&event, eventID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrRiskEventNotFound
+			}
+			return err
+		}
+		if event.Status == models.RiskEventStatusResolved || event.Status == req.Status {
+			return ErrRiskEventTransition
+		}
+
+		before := map[string]interface{}{"status": event.Status, "handler_id": event.HandlerID, "handler_note": event.HandlerNote}
+		now := time.Now()
+		updates := map[string]interface{}{"status": req.Status, "handler_id": actx.ActorID, "handler_note": req.Note}
+		if req.Status == models.RiskEventStatusAcknowledged || event.AcknowledgedAt == nil {
+			updates["acknowledged_at"] = now
+		}
+		if req.Status == models.RiskEventStatusResolved {
+			updates["resolved_at"] = now
+		}
+		// 条件更新，避免两名响应人员同时处理时互相覆盖
+		result := tx.Model(&event).Where("status = ?", event.Status).Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrRiskEventTransition
+		}
+		if err := tx.First(&event, eventID).Error; err != nil {
+			return err
+		}
+		after := map[string]interface{}{"status": event.Status, "handler_id": event.HandlerID, "handler_note": event.HandlerNote}
+		return RecordAudit(tx, actx, "risk_event_"+req.Status, auditResourceRiskEvent, event.ID, before, after)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// CrisisInfo 查询评估的全部风险事件并生成危机响应信息，未触发时返回nil
+func (s *CrisisService) CrisisInfo(tx *gorm.DB, assessmentID uint) (*models.CrisisInfo, error) {
+	var events []models.RiskEvent
+	if err := tx.Where("assessment_id = ?", assessmentID).Order("id ASC").Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return s.buildCrisisInfo(events), nil
+}
+
+// JournalCrisisInfo 查询日记的风险事件并生成危机响应信息，未触发时返回nil
+func (s *CrisisService) JournalCrisisInfo(tx *gorm.DB, entryID uint) (*models.CrisisInfo, error) {
+	var events []models.RiskEvent
+	if err := tx.Where("journal_entry_id = ?", entryID).Order("id ASC").Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return s.buildCrisisInfo(events), nil
+}
+
+// buildCrisisInfo 根据风险事件生成危机响应信息
+func (s *CrisisService) buildCrisisInfo(events []models.RiskEvent) *models.CrisisInfo {
+	if len(events) == 0 {
+		return nil
+	}
+	info := &models.CrisisInfo{
+		Triggered: true,
+		Message:   crisisMessage,
+		Resources: s.resources,
+	}
+	for _, event := range events {
+		info.RiskEventIDs = append(info.RiskEventIDs, event.ID)
+	}
+	return info
+}
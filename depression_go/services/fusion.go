@@ -0,0 +1,294 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+	"time"
+
+	"depression_go/configs"
+	"depression_go/internal/models"
+	"depression_go/pkg/i18n"
+
+	"gorm.io/gorm"
+)
+
+var ErrNoFusionInputs = errors.New("没有可用于综合评估的问卷评估、人脸检测或心情日记")
+
+// defaultFusionWeights 默认权重：缺少日记时按比例归一化，问卷与人脸检测约为70%和30%
+var defaultFusionWeights = map[string]float64{
+	models.ModalityQuestionnaire: 0.6,
+	models.ModalityFace:          0.25,
+	models.ModalityJournal:       0.15,
+}
+
+// fusionModalities 支持的模态，按该顺序输出
+var fusionModalities = []string{models.ModalityQuestionnaire, models.ModalityFace, models.ModalityJournal}
+
+// FusionService 综合评估服务：按配置的权重融合问卷、人脸检测和心情日记，
+// 缺少的模态不参与并相应降低置信度，保存时融合结果记为类型为combined的评估
+type FusionService struct {
+	db           *gorm.DB
+	journal      *JournalService
+	scoring      *ScoringService
+	localization *LocalizationService
+	modalities   []string
+	weights      map[string]float64
+	maxGap       time.Duration
+}
+
+// FusionOutcome 综合评估结果
+type FusionOutcome struct {
+	Assessment    *models.Assessment
+	Inputs        []models.FusionInput
+	Questionnaire *models.Assessment    // 参与融合的问卷评估，未参与时为nil
+	Face          *models.FaceDetection // 参与融合的人脸检测，未参与时为nil
+	Journal       *JournalSignal        // 参与融合的日记信号，未参与时为nil
+	Reused        bool                  // 输入与上一次综合评估相同，直接返回上一次的结果
+	Saved         bool                  // Assessment为已保存的综合评估，否则为未保存的预览
+}
+
+// NewFusionService 创建综合评估服务
+func NewFusionService(db *gorm.DB) *FusionService {
+	s := &FusionService{
+		db:           db,
+		journal:      NewJournalService(db),
+		scoring:      NewScoringService(db),
+		localization: NewLocalizationService(db),
+		modalities:   fusionModalities,
+		weights:      defaultFusionWeights,
+		maxGap:       72 * time.Hour,
+	}
+	if configs.GlobalConfig == nil {
+		return s
+	}
+
+	cfg := configs.GlobalConfig.Fusion
+	if len(cfg.Modalities) > 0 {
+		var modalities []string
+		for _, modality := range fusionModalities {
+			for _, enabled := range cfg.Modalities {
+				if enabled == modality {
+					modalities = append(modalities, modality)
+				}
+			}
+		}
+		if len(modalities) > 0 {
+			s.modalities = modalities
+		} else {
+			log.Printf("综合评估模态配置无效，使用全部模态: %v", cfg.Modalities)
+		}
+	}
+	if len(cfg.Weights) > 0 {
+		weights := make(map[string]float64, len(fusionModalities))
+		for _, modality := range fusionModalities {
+			weights[modality] = cfg.Weights[modality]
+		}
+		s.weights = weights
+	}
+	if cfg.MaxGapHours > 0 {
+		s.maxGap = time.Duration(cfg.MaxGapHours) * time.Hour
+	}
+	return s
+}
+
+// Preview 计算综合评估但不保存；输入与上一次保存的综合评估相同时返回该记录
+func (s *FusionService) Preview(userID uint, includeLowQuality bool) (*FusionOutcome, error) {
+	return s.fuse(userID, includeLowQuality, false)
+}
+
+// Combine 计算并保存综合评估；输入与上一次保存的综合评估相同时不重复保存
+func (s *FusionService) Combine(userID uint, includeLowQuality bool) (*FusionOutcome, error) {
+	return s.fuse(userID, includeLowQuality, true)
+}
+
+// fuse 取各模态最近的记录融合为综合评估；includeLowQuality为false时跳过低质量的问卷评估
+func (s *FusionService) fuse(userID uint, includeLowQuality, save bool) (*FusionOutcome, error) {
+	outcome := &FusionOutcome{}
+	inputs, err := s.collect(userID, includeLowQuality, outcome)
+	if err != nil {
+		return nil, err
+	}
+
+	// 以最新的输入为准，与之间隔超过maxGap的较旧输入不参与融合
+	var newest time.Time
+	for _, input := range inputs {
+		if input.Date != nil && input.Date.After(newest) {
+			newest = *input.Date
+		}
+	}
+	var used, enabled float64
+	for i := range inputs {
+		input := &inputs[i]
+		enabled += input.Weight
+		if input.Status == models.FusionInputUsed && newest.Sub(*input.Date) > s.maxGap {
+			input.Status = models.FusionInputStale
+		}
+		if input.Status == models.FusionInputUsed {
+			used += input.Weight
+		}
+	}
+	if used <= 0 {
+		return nil, ErrNoFusionInputs
+	}
+
+	var combined float64
+	for i := range inputs {
+		input := &inputs[i]
+		if input.Status != models.FusionInputUsed {
+			continue
+		}
+		input.Applied = roundTo(input.Weight/used, 3)
+		combined += float64(input.Score) * input.Weight / used
+	}
+	s.dropUnused(inputs, outcome)
+	outcome.Inputs = inputs
+
+	score := int(math.Round(combined))
+	confidence := 0.0
+	if enabled > 0 {
+		confidence = roundTo(used/enabled, 2)
+	}
+	data, err := json.Marshal(inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	// 输入没有变化时不重复保存
+	var last models.Assessment
+	err = s.db.Where("user_id = ? AND type = ?", userID, models.AssessmentTypeCombined).
+		Order("created_at DESC, id DESC").
+		First(&last).Error
+	if err == nil && last.FusionInputs == string(data) {
+		outcome.Assessment = &last
+		outcome.Reused = true
+		outcome.Saved = true
+		return outcome, nil
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	// 只读取最新的计分方案，预览时不发布初始方案
+	profile, err := s.scoring.latest(s.db)
+	if err != nil {
+		return nil, err
+	}
+	level := CombinedLevel(ParseScoreBands(profile), score)
+	description, _ := s.localization.CombinedText(i18n.Default, level)
+	now := time.Now()
+	assessment := models.Assessment{
+		UserID:       userID,
+		Title:        "综合评估",
+		Type:         models.AssessmentTypeCombined,
+		TotalScore:   score,
+		MaxScore:     100,
+		Level:        level,
+		Result:       description,
+		Status:       models.AssessmentStatusCompleted,
+		CompletedAt:  &now,
+		Mode:         models.AssessmentModeFixed,
+		OrderMode:    models.QuestionOrderFixed,
+		Confidence:   confidence,
+		FusionInputs: string(data),
+	}
+	if profile != nil {
+		assessment.ScoringProfileID = profile.ID
+	}
+	outcome.Assessment = &assessment
+	if !save {
+		return outcome, nil
+	}
+	if err := s.db.Create(&assessment).Error; err != nil {
+		return nil, err
+	}
+	outcome.Saved = true
+	return outcome, nil
+}
+
+// collect 查询各模态最近的记录，未启用的模态不输出
+func (s *FusionService) collect(userID uint, includeLowQuality bool, outcome *FusionOutcome) ([]models.FusionInput, error) {
+	inputs := make([]models.FusionInput, 0, len(s.modalities))
+	for _, modality := range s.modalities {
+		input := models.FusionInput{Modality: modality, Status: models.FusionInputMissing, Weight: s.weights[modality]}
+
+		switch modality {
+		case models.ModalityQuestionnaire:
+			query := s.db.Where("user_id = ? AND status = ? AND type NOT IN ?",
+				userID, models.AssessmentStatusCompleted, nonQuestionnaireTypes)
+			if !includeLowQuality {
+				query = query.Where("(quality_flag IS NULL OR quality_flag <> ?)", models.QualityFlagLow)
+			}
+			var assessment models.Assessment
+			err := query.Order("created_at DESC, id DESC").First(&assessment).Error
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, err
+			}
+			if err == nil {
+				// 问卷按得分占满分的百分比折算为0-100分（满分未记录的历史评估沿用原分数）
+				date := assessmentDate(assessment)
+				input.Status, input.SourceID, input.Level, input.Date = models.FusionInputUsed, assessment.ID, assessment.Level, &date
+				input.Score = assessment.TotalScore
+				if assessment.MaxScore > 0 {
+					input.Score = assessment.TotalScore * 100 / assessment.MaxScore
+				}
+				outcome.Questionnaire = &assessment
+			}
+
+		case models.ModalityFace:
+			var detection models.FaceDetection
+			err := s.db.Where("user_id = ?", userID).Order("created_at DESC, id DESC").First(&detection).Error
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, err
+			}
+			if err == nil {
+				date := detection.CreatedAt
+				input.Status, input.SourceID, input.Level, input.Date = models.FusionInputUsed, detection.ID, detection.Level, &date
+				input.Score = detection.Score
+				outcome.Face = &detection
+			}
+
+		case models.ModalityJournal:
+			signal, err := s.journal.RecentSignal(userID)
+			if err != nil {
+				return nil, err
+			}
+			if signal != nil {
+				date := signal.Latest
+				input.Status, input.Level, input.Date = models.FusionInputUsed, signal.Level, &date
+				input.Score = signal.Score
+				outcome.Journal = signal
+			}
+		}
+
+		// 权重为0的模态不参与融合
+		if input.Weight <= 0 && input.Status == models.FusionInputUsed {
+			input.Status = models.FusionInputMissing
+		}
+		inputs = append(inputs, input)
+	}
+	return inputs, nil
+}
+
+// dropUnused 清除未参与融合的模态在结果中的记录
+func (s *FusionService) dropUnused(inputs []models.FusionInput, outcome *FusionOutcome) {
+	for _, input := range inputs {
+		if input.Status == models.FusionInputUsed {
+			continue
+		}
+		switch input.Modality {
+		case models.ModalityQuestionnaire:
+			outcome.Questionnaire = nil
+		case models.ModalityFace:
+			outcome.Face = nil
+		case models.ModalityJournal:
+			outcome.Journal = nil
+		}
+	}
+}
+
+// CombinedLevel 按计分方案的等级划分综合得分（0-100，即百分比）
+func CombinedLevel(bands []models.ScoreBand, score int) string {
+	return findBand(bands, float64(score)).Level
+}
@@ -0,0 +1,250 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"depression_go/configs"
+	"depression_go/internal/models"
+	"depression_go/pkg/sentiment"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrJournalNotFound = errors.New("日记不存在")
+	ErrInvalidJournal  = errors.New("日记数据不合法")
+)
+
+// JournalService 心情日记服务：保存日记并做情感分析，为综合评估提供日记信号；
+// 日记中出现轻生表述时与风险条目一样进入危机流程
+type JournalService struct {
+	db         *gorm.DB
+	crisis     *CrisisService
+	scoring    *ScoringService
+	analyzer   sentiment.Analyzer
+	maxLength  int
+	windowDays int
+}
+
+// JournalOutcome 保存日记的结果
+type JournalOutcome struct {
+	Entry     *models.JournalEntry
+	Sentiment *sentiment.Result
+	Crisis    *models.CrisisInfo // 日记中出现轻生表述时的危机信息，未触发时为nil
+}
+
+// JournalSignal 一段时间内日记的综合信号
+type JournalSignal struct {
+	Score   int       // 各篇日记抑郁倾向信号的平均值（0-100）
+	Level   string    // 按平均值划分的等级
+	Entries int       // 参与计算的日记篇数
+	Since   time.Time // 统计的起始时间
+	Latest  time.Time // 最近一篇日记的时间
+}
+
+// NewJournalService 创建心情日记服务，配置的分析器不存在时回退到内置词典分析器
+func NewJournalService(db *gorm.DB) *JournalService {
+	s := &JournalService{db: db, crisis: NewCrisisService(db), scoring: NewScoringService(db), maxLength: 2000, windowDays: 7}
+	name := ""
+	if configs.GlobalConfig != nil {
+		cfg := configs.GlobalConfig.Journal
+		name = cfg.Analyzer
+		if cfg.MaxLength > 0 {
+			s.maxLength = cfg.MaxLength
+		}
+		if cfg.WindowDays > 0 {
+			s.windowDays = cfg.WindowDays
+		}
+	}
+
+	analyzer, err := sentiment.New(name)
+	if err != nil {
+		log.Printf("情感分析器配置无效，使用内置词典分析器: %v", err)
+		analyzer = sentiment.NewLexiconAnalyzer()
+	}
+	s.analyzer = analyzer
+	return s
+}
+
+// Create 新建日记并分析情感
+func (s *JournalService) Create(userID uint, req models.JournalEntryRequest) (*JournalOutcome, error) {
+	entry := models.JournalEntry{UserID: userID}
+	result, err := s.analyze(&entry, req)
+	if err != nil {
+		return nil, err
+	}
+	return s.save(&entry, result, func(tx *gorm.DB) error {
+		return tx.Create(&entry).Error
+	})
+}
+
+// Update 修改日记内容并重新分析
+func (s *JournalService) Update(userID, entryID uint, req models.JournalEntryRequest) (*JournalOutcome, error) {
+	entry, err := s.Get(userID, entryID)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.analyze(entry, req)
+	if err != nil {
+		return nil, err
+	}
+	return s.save(entry, result, func(tx *gorm.DB) error {
+		return tx.Model(entry).Updates(map[string]interface{}{
+			"content":         entry.Content,
+			"mood":            entry.Mood,
+			"sentiment_score": entry.SentimentScore,
+			"sentiment_label": entry.SentimentLabel,
+			"language":        entry.Language,
+			"analyzer":        entry.Analyzer,
+			"score":           entry.Score,
+			"level":           entry.Level,
+		}).Error
+	})
+}
+
+// save 在同一事务内保存日记并检查轻生表述，提交后通知响应人员
+func (s *JournalService) save(entry *models.JournalEntry, result *sentiment.Result, write func(tx *gorm.DB) error) (*JournalOutcome, error) {
+	outcome := &JournalOutcome{Entry: entry, Sentiment: result}
+	var events []models.RiskEvent
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := write(tx); err != nil {
+			return err
+		}
+		if len(result.Risk) == 0 {
+			return nil
+		}
+		event, err := s.crisis.DetectJournalRisk(tx, entry, result.Risk)
+		if err != nil {
+			return err
+		}
+		if event != nil {
+			events = append(events, *event)
+		}
+		outcome.Crisis, err = s.crisis.JournalCrisisInfo(tx, entry.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.crisis.Dispatch(events)
+	return outcome, nil
+}
+
+// Delete 删除日记
+func (s *JournalService) Delete(userID, entryID uint) error {
+	entry, err := s.Get(userID, entryID)
+	if err != nil {
+		return err
+	}
+	return s.db.Delete(entry).Error
+}
+
+// Get 获取属于该用户的日记
+func (s *JournalService) Get(userID, entryID uint) (*models.JournalEntry, error) {
+	var entry models.JournalEntry
+	if err := s.db.Where("id = ? AND user_id = ?", entryID, userID).First(&entry).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrJournalNotFound
+		}
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// List 分页列出用户的日记，按时间倒序
+func (s *JournalService) List(userID uint, page, pageSize int) ([]models.JournalEntry, int64, error) {
+	var total int64
+	if err := s.db.Model(&models.JournalEntry{}).Where("user_id = ?", userID).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var entries []models.JournalEntry
+	err := s.db.Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&entries).Error
+	return entries, total, err
+}
+
+// RecentSignal 最近windowDays天日记的综合信号，期间没有日记时返回nil
+func (s *JournalService) RecentSignal(userID uint) (*JournalSignal, error) {
+	since := time.Now().AddDate(0, 0, -s.windowDays)
+	var row struct {
+		Total   float64
+		Entries int
+		Latest  *time.Time
+	}
+	if err := s.db.Model(&models.JournalEntry{}).
+		Select("COALESCE(SUM(score), 0) AS total, COUNT(*) AS entries, MAX(created_at) AS latest").
+		Where("user_id = ? AND created_at >= ?", userID, since).
+		Scan(&row).Error; err != nil {
+		return nil, err
+	}
+	if row.Entries == 0 || row.Latest == nil {
+		return nil, nil
+	}
+	score := int(math.Round(row.Total / float64(row.Entries)))
+	level, err := s.level(score)
+	if err != nil {
+		return nil, err
+	}
+	return &JournalSignal{Score: score, Level: level, Entries: row.Entries, Since: since, Latest: *row.Latest}, nil
+}
+
+// analyze 校验请求并分析情感，结果写入entry
+func (s *JournalService) analyze(entry *models.JournalEntry, req models.JournalEntryRequest) (*sentiment.Result, error) {
+	content := strings.TrimSpace(req.Content)
+	if content == "" {
+		return nil, fmt.Errorf("%w: 内容不能为空", ErrInvalidJournal)
+	}
+	if utf8.RuneCountInString(content) > s.maxLength {
+		return nil, fmt.Errorf("%w: 内容不能超过%d字", ErrInvalidJournal, s.maxLength)
+	}
+	if req.Mood != nil && (*req.Mood < 1 || *req.Mood > 10) {
+		return nil, fmt.Errorf("%w: 心情评分必须在1-10之间", ErrInvalidJournal)
+	}
+
+	result, err := s.analyzer.Analyze(content)
+	if err != nil {
+		return nil, err
+	}
+	score := JournalScore(result.Score)
+	level, err := s.level(score)
+	if err != nil {
+		return nil, err
+	}
+	entry.Content = content
+	entry.Mood = req.Mood
+	entry.SentimentScore = result.Score
+	entry.SentimentLabel = result.Label
+	entry.Language = result.Language
+	entry.Analyzer = s.analyzer.Name()
+	entry.Score = score
+	entry.Level = level
+	return &result, nil
+}
+
+// level 按最新的计分方案划分日记信号的等级，尚无方案时使用初始划分
+func (s *JournalService) level(score int) (string, error) {
+	profile, err := s.scoring.latest(s.db)
+	if err != nil {
+		return "", err
+	}
+	return JournalLevel(ParseScoreBands(profile), score), nil
+}
+
+// JournalScore 将情感得分换算为抑郁倾向信号（0-100）：只有消极情感计分，中性和积极为0
+func JournalScore(sentimentScore float64) int {
+	return int(math.Round(math.Max(0, -sentimentScore) * 100))
+}
+
+// JournalLevel 按计分方案的等级划分抑郁倾向信号（0-100，即百分比）
+func JournalLevel(bands []models.ScoreBand, score int) string {
+	return findBand(bands, float64(score)).Level
+}
@@ -0,0 +1,381 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"depression_go/internal/models"
+	"depression_go/pkg/i18n"
+
+	"gorm.io/gorm"
+)
+
+// 审计日志中的翻译资源类型
+const (
+	auditResourceQuestionTranslation = "question_translation"
+	auditResourceResultText          = "result_text"
+)
+
+var (
+	ErrUnsupportedLocale   = errors.New("不支持的语言")
+	ErrUnknownTextKey      = errors.New("未知的文本key")
+	ErrTranslationNotFound = errors.New("翻译不存在")
+	ErrInvalidTranslation  = errors.New("翻译数据不合法")
+)
+
+// LocalizationService 本地化服务：题目翻译和结果文本，按请求语言回退到默认语言
+type LocalizationService struct {
+	db *gorm.DB
+}
+
+// NewLocalizationService 创建本地化服务
+func NewLocalizationService(db *gorm.DB) *LocalizationService {
+	return &LocalizationService{db: db}
+}
+
+// Text 获取结果文本：依次尝试请求语言和默认语言，每种语言先查数据库覆盖再查内置文本
+func (s *LocalizationService) Text(locale, key string) string {
+	for _, l := range i18n.Fallbacks(locale) {
+		if text, ok := s.lookup(l, key); ok {
+			return text
+		}
+	}
+	return key
+}
+
+// lookup 在单一语言下查找结果文本，数据库覆盖优先
+func (s *LocalizationService) lookup(locale, key string) (string, bool) {
+	var override models.ResultTextTranslation
+	if err := s.db.Where("text_key = ? AND locale = ?", key, locale).First(&override).Error; err == nil {
+		return override.Text, true
+	}
+	return i18n.Lookup(locale, key)
+}
+
+// LocalizeResult 按语言替换评估结果的描述和建议：每种语言先用计分方案中该等级的文本，再用结果文本
+func (s *LocalizationService) LocalizeResult(locale string, result *models.AssessmentResult) {
+	var bands []models.ScoreBand
+	if result.ScoringProfileID != 0 {
+		var profile models.ScoringProfile
+		if err := s.db.First(&profile, result.ScoringProfileID).Error; err == nil {
+			bands = ParseScoreBands(&profile)
+		}
+	}
+
+	for _, l := range i18n.Fallbacks(locale) {
+		if text, ok := bandText(bands, result.Level, l); ok {
+			result.Description, result.Suggestions = text.Description, text.Suggestions
+			return
+		}
+		if description, ok := s.lookup(l, "assessment."+result.Level+".description"); ok {
+			result.Description = description
+			result.Suggestions = s.Text(l, "assessment."+result.Level+".suggestions")
+			return
+		}
+	}
+}
+
+// CombinedText 获取综合评估的描述和建议
+func (s *LocalizationService) CombinedText(locale, level string) (string, string) {
+	return s.Text(locale, "combined."+level+".description"), s.Text(locale, "combined."+level+".suggestions")
+}
+
+// EmotionText 获取情绪分析描述
+func (s *LocalizationService) EmotionText(locale, emotion, level string) string {
+	return s.Text(locale, EmotionTextKey(emotion, level))
+}
+
+// translations 查询一批问题在请求语言下的全部翻译及其依据的修订（默认语言无需翻译）
+func (s *LocalizationService) translations(locale string, questionIDs []uint) (map[uint][]models.QuestionTranslation, error) {
+	result := map[uint][]models.QuestionTranslation{}
+	locale = i18n.Normalize(locale)
+	if locale == "" || locale == i18n.Default || len(questionIDs) == 0 {
+		return result, nil
+	}
+
+	var list []models.QuestionTranslation
+	if err := s.db.Preload("Revision").
+		Where("locale = ? AND question_id IN ?", locale, questionIDs).
+		Order("question_revision_id DESC").
+		Find(&list).Error; err != nil {
+		return nil, err
+	}
+	for _, translation := range list {
+		result[translation.QuestionID] = append(result[translation.QuestionID], translation)
+	}
+	return result, nil
+}
+
+// translates 判断翻译所依据的修订与展示的原文是否一致
+func translates(translation models.QuestionTranslation, title, description, options string) bool {
+	revision := translation.Revision
+	return revision.ID != 0 &&
+		revision.Title == title &&
+		revision.Description == description &&
+		revision.Options == options
+}
+
+// applyTranslation 在候选翻译中找到与原文一致的最新一条替换题目文本，
+// 原文修改后没有重新翻译时保留原文；翻译中没有的字段保留原文
+func applyTranslation(title, description, options *string, candidates []models.QuestionTranslation) {
+	for _, translation := range candidates {
+		if !translates(translation, *title, *description, *options) {
+			continue
+		}
+		*title = translation.Title
+		if translation.Description != "" {
+			*description = translation.Description
+		}
+		if translation.Options != "" {
+			*options = translation.Options
+		}
+		return
+	}
+}
+
+// LocalizeQuestions 按语言翻译题目，没有翻译或翻译已过期的题目保留默认语言原文
+func (s *LocalizationService) LocalizeQuestions(locale string, questions []models.Question) error {
+	ids := make([]uint, 0, len(questions))
+	for _, question := range questions {
+		ids = append(ids, question.ID)
+	}
+	translations, err := s.translations(locale, ids)
+	if err != nil {
+		return err
+	}
+	for i := range questions {
+		applyTranslation(&questions[i].Title, &questions[i].Description, &questions[i].Options, translations[questions[i].ID])
+	}
+	return nil
+}
+
+// LocalizeAnswers 按语言翻译答案所属的题目，使用与作答时修订一致的翻译
+func (s *LocalizationService) LocalizeAnswers(locale string, answers []models.AnswerWithQuestion) error {
+	ids := make([]uint, 0, len(answers))
+	for _, answer := range answers {
+		ids = append(ids, answer.QuestionID)
+	}
+	translations, err := s.translations(locale, ids)
+	if err != nil {
+		return err
+	}
+	for i := range answers {
+		question := &answers[i].Question
+		applyTranslation(&question.Title, &question.Description, &question.Options, translations[answers[i].QuestionID])
+	}
+	return nil
+}
+
+// ListQuestionTranslations 获取问题每种语言的最新翻译，依据的修订与当前内容不一致时标记为过期
+func (s *LocalizationService) ListQuestionTranslations(questionID uint) ([]models.QuestionTranslation, error) {
+	var question models.Question
+	if err := s.db.First(&question, questionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %d", ErrQuestionNotFound, questionID)
+		}
+		return nil, err
+	}
+
+	var list []models.QuestionTranslation
+	if err := s.db.Preload("Revision").
+		Where("question_id = ?", questionID).
+		Order("locale ASC, question_revision_id DESC").
+		Find(&list).Error; err != nil {
+		return nil, err
+	}
+	translations := make([]models.QuestionTranslation, 0, len(list))
+	for _, translation := range list {
+		if len(translations) > 0 && translations[len(translations)-1].Locale == translation.Locale {
+			continue
+		}
+		translation.Stale = !translates(translation, question.Title, question.Description, question.Options)
+		translations = append(translations, translation)
+	}
+	return translations, nil
+}
+
+// SaveQuestionTranslation 按问题当前修订新增或更新翻译，选项数量必须与原题一致；
+// 旧修订的翻译保留，用于回看历史评估
+func (s *LocalizationService) SaveQuestionTranslation(actx AuditContext, questionID uint, locale string, req models.QuestionTranslationRequest) (*models.QuestionTranslation, error) {
+	normalized := i18n.Normalize(locale)
+	if normalized == "" || normalized != locale {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedLocale, locale)
+	}
+	if locale == i18n.Default {
+		return nil, fmt.Errorf("%w: 默认语言请直接修改题库", ErrInvalidTranslation)
+	}
+
+	var translation models.QuestionTranslation
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var question models.Question
+		if err := tx.First(&question, questionID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("%w: %d", ErrQuestionNotFound, questionID)
+			}
+			return err
+		}
+		if err := validateTranslation(question, req); err != nil {
+			return err
+		}
+		revision, err := NewQuestionnaireVersionService(s.db).ensureRevision(tx, &question)
+		if err != nil {
+			return err
+		}
+
+		var before *models.QuestionTranslation
+		err = tx.Where("question_id = ? AND question_revision_id = ? AND locale = ?", questionID, revision.ID, locale).First(&translation).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err == nil {
+			existing := translation
+			before = &existing
+		}
+
+		translation.QuestionID = questionID
+		translation.QuestionRevisionID = revision.ID
+		translation.Locale = locale
+		translation.Title = req.Title
+		translation.Description = req.Description
+		translation.Options = req.Options
+		if err := tx.Omit("Revision").Save(&translation).Error; err != nil {
+			return err
+		}
+		return RecordAudit(tx, actx, "save", auditResourceQuestionTranslation, questionID, before, translation)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &translation, nil
+}
+
+// DeleteQuestionTranslation 删除问题在该语言下的最新翻译，旧修订的翻译保留用于回看历史评估
+func (s *LocalizationService) DeleteQuestionTranslation(actx AuditContext, questionID uint, locale string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var translation models.QuestionTranslation
+		if err := tx.Where("question_id = ? AND locale = ?", questionID, locale).
+			Order("question_revision_id DESC").
+			First(&translation).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrTranslationNotFound
+			}
+			return err
+		}
+		if err := tx.Unscoped().Delete(&translation).Error; err != nil {
+			return err
+		}
+		return RecordAudit(tx, actx, "delete", auditResourceQuestionTranslation, questionID, translation, nil)
+	})
+}
+
+// ListResultTexts 列出指定语言下全部结果文本及其覆盖情况
+func (s *LocalizationService) ListResultTexts(locale string) ([]models.ResultTextItem, error) {
+	normalized := i18n.Normalize(locale)
+	if normalized == "" || normalized != locale {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedLocale, locale)
+	}
+
+	var overrides []models.ResultTextTranslation
+	if err := s.db.Where("locale = ?", locale).Find(&overrides).Error; err != nil {
+		return nil, err
+	}
+	byKey := map[string]models.ResultTextTranslation{}
+	for _, override := range overrides {
+		byKey[override.TextKey] = override
+	}
+
+	keys := i18n.Keys()
+	items := make([]models.ResultTextItem, 0, len(keys))
+	for _, key := range keys {
+		item := models.ResultTextItem{
+			Key:     key,
+			Locale:  locale,
+			Builtin: i18n.T(locale, key),
+		}
+		if override, ok := byKey[key]; ok {
+			updatedAt := override.UpdatedAt
+			item.Text = override.Text
+			item.Overridden = true
+			item.UpdatedAt = &updatedAt
+		} else {
+			item.Text = s.Text(locale, key)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// SaveResultText 覆盖指定语言的结果文本
+func (s *LocalizationService) SaveResultText(actx AuditContext, locale, key, text string) (*models.ResultTextTranslation, error) {
+	normalized := i18n.Normalize(locale)
+	if normalized == "" || normalized != locale {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedLocale, locale)
+	}
+	if !i18n.HasKey(key) {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownTextKey, key)
+	}
+
+	var override models.ResultTextTranslation
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var before *models.ResultTextTranslation
+		err := tx.Where("text_key = ? AND locale = ?", key, locale).First(&override).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err == nil {
+			existing := override
+			before = &existing
+		}
+
+		override.TextKey = key
+		override.Locale = locale
+		override.Text = text
+		if err := tx.Save(&override).Error; err != nil {
+			return err
+		}
+		return RecordAudit(tx, actx, "save", auditResourceResultText, override.ID, before, override)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &override, nil
+}
+
+// DeleteResultText 删除覆盖，恢复为内置文本
+func (s *LocalizationService) DeleteResultText(actx AuditContext, locale, key string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var override models.ResultTextTranslation
+		if err := tx.Where("text_key = ? AND locale = ?", key, locale).First(&override).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrTranslationNotFound
+			}
+			return err
+		}
+		if err := tx.Unscoped().Delete(&override).Error; err != nil {
+			return err
+		}
+		return RecordAudit(tx, actx, "delete", auditResourceResultText, override.ID, override, nil)
+	})
+}
+
+// validateTranslation 校验翻译：选择题的选项必须是与原题数量相同的JSON字符串数组
+func validateTranslation(question models.Question, req models.QuestionTranslationRequest) error {
+	if question.Type == models.QuestionTypeText {
+		return nil
+	}
+
+	var original []string
+	_ = json.Unmarshal([]byte(question.Options), &original)
+	if req.Options == "" {
+		return fmt.Errorf("%w: 选择题必须提供选项翻译", ErrInvalidTranslation)
+	}
+	var options []string
+	if err := json.Unmarshal([]byte(req.Options), &options); err != nil {
+		return fmt.Errorf("%w: 选项必须是JSON字符串数组", ErrInvalidTranslation)
+	}
+	if len(options) != len(original) {
+		return fmt.Errorf("%w: 选项数量(%d)与原题(%d)不一致", ErrInvalidTranslation, len(options), len(original))
+	}
+	return nil
+}
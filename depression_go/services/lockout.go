@@ -0,0 +1,359 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"depression_go/configs"
+	"depression_go/internal/models"
+	"depression_go/pkg/lockout"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrAccountLocked    = errors.New("登录失败次数过多，账户已临时锁定")
+	ErrTooManyAttempts  = errors.New("该IP登录失败次数过多，请稍后再试")
+	ErrLockoutNotLocked = errors.New("该账户未被锁定")
+)
+
+// lockoutPurgeInterval 清理过期失败计数的间隔（仅数据库存储需要）
+const lockoutPurgeInterval = time.Hour
+
+// LockoutError 登录被锁定，Until为解除时间；errors.Is可匹配ErrAccountLocked或ErrTooManyAttempts
+type LockoutError struct {
+	Cause error
+	Until time.Time
+}
+
+func (e *LockoutError) Error() string {
+	return e.Cause.Error()
+}
+
+func (e *LockoutError) Unwrap() error {
+	return e.Cause
+}
+
+// RetryAfter 距离解除锁定的秒数，至少为1
+func (e *LockoutError) RetryAfter() int {
+	seconds := int(math.Ceil(time.Until(e.Until).Seconds()))
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}
+
+// LoginAttemptFilter 登录记录查询条件，零值表示不限
+type LoginAttemptFilter struct {
+	UserID     uint
+	Identifier string
+	IP         string
+	Success    *bool
+}
+
+// LockoutService 登录防暴力破解：按账户和来源IP累计连续失败次数，超过阈值后按指数增长的时长锁定
+type LockoutService struct {
+	db    *gorm.DB
+	store lockout.Store
+	cfg   configs.LockoutConfig
+}
+
+var (
+	lockoutStoreOnce sync.Once
+	lockoutStore     lockout.Store
+)
+
+// NewLockoutService 创建登录锁定服务，失败计数存储在进程内共享
+func NewLockoutService(db *gorm.DB) *LockoutService {
+	cfg := configs.LockoutConfig{
+		MaxAccountFailures: 5,
+		MaxIPFailures:      20,
+		BaseLockSeconds:    60,
+		MaxLockMinutes:     60,
+		WindowMinutes:      15,
+	}
+	if configs.GlobalConfig != nil {
+		cfg = configs.GlobalConfig.Lockout
+	}
+
+	lockoutStoreOnce.Do(func() {
+		lockoutStore = NewLockoutStore(db, cfg)
+	})
+	return &LockoutService{db: db, store: lockoutStore, cfg: cfg}
+}
+
+// NewLockoutStore 根据配置创建失败计数存储
+func NewLockoutStore(db *gorm.DB, cfg configs.LockoutConfig) lockout.Store {
+	switch cfg.Store {
+	case "db":
+		return NewDBLockoutStore(db)
+	case "redis":
+		if cfg.RedisAddr != "" {
+			return lockout.NewRedisStore(cfg.RedisAddr, cfg.RedisPassword, cfg.RedisDB)
+		}
+		log.Println("LOCKOUT_REDIS_ADDR未配置，登录失败计数改为保存在内存中")
+	}
+	return lockout.NewMemoryStore()
+}
+
+// AccountKey 账户的计数键；用户不存在时按填写的用户名计数，使不存在的账户与存在的账户表现一致
+func AccountKey(user *models.User, identifier string) string {
+	if user != nil {
+		return fmt.Sprintf("user:%d", user.ID)
+	}
+	return "login:" + strings.ToLower(strings.TrimSpace(identifier))
+}
+
+// ipKey 来源IP的计数键
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
+
+// Check 校验密码前检查来源IP和账户是否处于锁定中，锁定时返回*LockoutError。
+// 计数存储不可用时只记录日志并放行，避免存储故障导致所有人无法登录
+func (s *LockoutService) Check(accountKey, ip string) error {
+	now := time.Now()
+	if ip != "" {
+		state, err := s.store.Get(ipKey(ip))
+		if err != nil {
+			log.Printf("读取登录失败计数失败: %v", err)
+		} else if state.Locked(now) {
+			return &LockoutError{Cause: ErrTooManyAttempts, Until: state.LockedUntil}
+		}
+	}
+
+	state, err := s.store.Get(accountKey)
+	if err != nil {
+		log.Printf("读取登录失败计数失败: %v", err)
+		return nil
+	}
+	if state.Locked(now) {
+		return &LockoutError{Cause: ErrAccountLocked, Until: state.LockedUntil}
+	}
+	return nil
+}
+
+// RecordFailure 记录一次失败；本次失败触发锁定时返回*LockoutError，账户锁定优先于IP锁定
+func (s *LockoutService) RecordFailure(accountKey, ip string) error {
+	var locked error
+	if ip != "" {
+		if err := s.fail(ipKey(ip), s.cfg.MaxIPFailures, ErrTooManyAttempts); err != nil {
+			locked = err
+		}
+	}
+	if err := s.fail(accountKey, s.cfg.MaxAccountFailures, ErrAccountLocked); err != nil {
+		locked = err
+	}
+	return locked
+}
+
+// RecordSuccess 登录成功后清零账户的失败次数；IP的计数不清零，避免攻击者用自己的账户重置计数
+func (s *LockoutService) RecordSuccess(accountKey string) {
+	if err := s.store.Reset(accountKey); err != nil {
+		log.Printf("清除登录失败计数失败: %v", err)
+	}
+}
+
+// Unlock 管理员解除账户锁定并清零失败次数，写入审计日志
+func (s *LockoutService) Unlock(actx AuditContext, userID uint) error {
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+
+	key := AccountKey(&user, "")
+	state, err := s.store.Get(key)
+	if err != nil {
+		return err
+	}
+	if state.Failures == 0 && state.LockedUntil.IsZero() {
+		return ErrLockoutNotLocked
+	}
+	if err := s.store.Reset(key); err != nil {
+		return err
+	}
+
+	before := map[string]interface{}{"failures": state.Failures, "locked_until": nullableTime(state.LockedUntil)}
+	return RecordAudit(s.db, actx, "unlock", auditResourceUser, userID, before, nil)
+}
+
+// LogAttempt 写入登录记录，失败时只记录日志，不影响登录
+func (s *LockoutService) LogAttempt(attempt models.LoginAttempt) {
+	attempt.Identifier = truncate(attempt.Identifier, 100)
+	attempt.UserAgent = truncate(attempt.UserAgent, 255)
+	if err := s.db.Create(&attempt).Error; err != nil {
+		log.Printf("写入登录记录失败: %v", err)
+	}
+}
+
+// Attempts 分页查询登录记录，按时间倒序
+func (s *LockoutService) Attempts(filter LoginAttemptFilter, page, pageSize int) ([]models.LoginAttempt, int64, error) {
+	query := s.db.Model(&models.LoginAttempt{})
+	if filter.UserID > 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.Identifier != "" {
+		query = query.Where("identifier = ?", filter.Identifier)
+	}
+	if filter.IP != "" {
+		query = query.Where("ip = ?", filter.IP)
+	}
+	if filter.Success != nil {
+		query = query.Where("success = ?", *filter.Success)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var attempts []models.LoginAttempt
+	err := query.Order("id DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&attempts).Error
+	return attempts, total, err
+}
+
+// StartPurge 数据库存储时后台定期清理过期的失败计数，ctx取消时退出
+func (s *LockoutService) StartPurge(ctx context.Context) {
+	purger, ok := s.store.(lockout.Purger)
+	if !ok {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(lockoutPurgeInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := purger.Purge(); err != nil {
+					log.Printf("清理登录失败计数失败: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// fail 累加失败次数，达到阈值后锁定：第max次失败锁定base时长，之后每次翻倍，不超过上限
+func (s *LockoutService) fail(key string, max int, cause error) error {
+	window := time.Duration(s.cfg.WindowMinutes) * time.Minute
+	state, err := s.store.Fail(key, window)
+	if err != nil {
+		log.Printf("记录登录失败计数失败: %v", err)
+		return nil
+	}
+	if state.Failures < max {
+		return nil
+	}
+
+	duration := LockDuration(state.Failures-max, time.Duration(s.cfg.BaseLockSeconds)*time.Second, time.Duration(s.cfg.MaxLockMinutes)*time.Minute)
+	until := time.Now().Add(duration)
+	// 锁定结束后仍保留计数一个窗口期，期间再失败会锁定更久
+	if err := s.store.Lock(key, until, duration+window); err != nil {
+		log.Printf("写入登录锁定失败: %v", err)
+		return nil
+	}
+	return &LockoutError{Cause: cause, Until: until}
+}
+
+// LockDuration 超过阈值exceeded次后的锁定时长：base * 2^exceeded，不超过max
+func LockDuration(exceeded int, base, max time.Duration) time.Duration {
+	duration := base
+	for i := 0; i < exceeded && duration < max; i++ {
+		duration *= 2
+	}
+	if max > 0 && duration > max {
+		duration = max
+	}
+	return duration
+}
+
+// nullableTime 零值时间转为nil，用于审计日志
+func nullableTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// DBLockoutStore 基于数据库的失败计数存储，适用于没有Redis的多实例部署
+type DBLockoutStore struct {
+	db *gorm.DB
+}
+
+// NewDBLockoutStore 创建数据库存储
+func NewDBLockoutStore(db *gorm.DB) *DBLockoutStore {
+	return &DBLockoutStore{db: db}
+}
+
+// Get 获取当前状态，已过期的记录视为不存在
+func (d *DBLockoutStore) Get(key string) (lockout.State, error) {
+	var row models.LoginLockout
+	err := d.db.Where("lock_key = ? AND expires_at > ?", key, time.Now()).First(&row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return lockout.State{}, nil
+	}
+	if err != nil {
+		return lockout.State{}, err
+	}
+	return rowState(row), nil
+}
+
+// Fail 以upsert原子地累加失败次数，记录已过期时从1重新计数
+func (d *DBLockoutStore) Fail(key string, ttl time.Duration) (lockout.State, error) {
+	now := time.Now()
+	row := models.LoginLockout{LockKey: key, Failures: 1, ExpiresAt: now.Add(ttl)}
+	// MySQL按顺序执行赋值，expires_at必须最后更新，前面的条件才能读到旧值
+	err := d.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "lock_key"}},
+		DoUpdates: []clause.Assignment{
+			{Column: clause.Column{Name: "failures"}, Value: gorm.Expr("IF(expires_at <= ?, 1, failures + 1)", now)},
+			{Column: clause.Column{Name: "locked_until"}, Value: gorm.Expr("IF(expires_at <= ?, NULL, locked_until)", now)},
+			{Column: clause.Column{Name: "expires_at"}, Value: row.ExpiresAt},
+		},
+	}).Create(&row).Error
+	if err != nil {
+		return lockout.State{}, err
+	}
+
+	if err := d.db.Where("lock_key = ?", key).First(&row).Error; err != nil {
+		return lockout.State{}, err
+	}
+	return rowState(row), nil
+}
+
+// Lock 锁定到until
+func (d *DBLockoutStore) Lock(key string, until time.Time, ttl time.Duration) error {
+	return d.db.Model(&models.LoginLockout{}).
+		Where("lock_key = ?", key).
+		Updates(map[string]interface{}{"locked_until": until, "expires_at": time.Now().Add(ttl)}).Error
+}
+
+// Reset 清除状态
+func (d *DBLockoutStore) Reset(key string) error {
+	return d.db.Where("lock_key = ?", key).Delete(&models.LoginLockout{}).Error
+}
+
+// Purge 删除已过期的记录
+func (d *DBLockoutStore) Purge() (int64, error) {
+	result := d.db.Where("expires_at <= ?", time.Now()).Delete(&models.LoginLockout{})
+	return result.RowsAffected, result.Error
+}
+
+// rowState 数据库记录转为状态
+func rowState(row models.LoginLockout) lockout.State {
+	state := lockout.State{Failures: row.Failures}
+	if row.LockedUntil != nil {
+		state.LockedUntil = *row.LockedUntil
+	}
+	return state
+}
@@ -0,0 +1,30 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLockDuration(t *testing.T) {
+	tests := []struct {
+		name      string
+		exceeded  int
+		base, max time.Duration
+		want      time.Duration
+	}{
+		{"刚达到阈值", 0, time.Minute, time.Hour, time.Minute},
+		{"超过一次翻倍", 1, time.Minute, time.Hour, 2 * time.Minute},
+		{"超过三次", 3, time.Minute, time.Hour, 8 * time.Minute},
+		{"不超过上限", 10, time.Minute, time.Hour, time.Hour},
+		{"翻倍后超过上限取上限", 6, time.Minute, time.Hour, time.Hour},
+		{"次数为负按基础时长", -1, time.Minute, time.Hour, time.Minute},
+		{"上限小于基础时长", 2, time.Minute, 30 * time.Second, 30 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LockDuration(tt.exceeded, tt.base, tt.max); got != tt.want {
+				t.Errorf("LockDuration(%d, %v, %v) = %v, want %v", tt.exceeded, tt.base, tt.max, got, tt.want)
+			}
+		})
+	}
+}
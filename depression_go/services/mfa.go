@@ -0,0 +1,436 @@
+package services
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"depression_go/configs"
+	"depression_go/internal/models"
+	"depression_go/pkg/token"
+	"depression_go/pkg/totp"
+	"depression_go/pkg/utils"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrMFANotEnabled     = errors.New("未启用两步验证")
+	ErrMFAAlreadyEnabled = errors.New("已启用两步验证")
+	ErrMFANotSetup       = errors.New("请先生成两步验证密钥")
+	ErrInvalidMFACode    = errors.New("验证码错误")
+	ErrMFARequiredByRole = errors.New("所属角色要求两步验证，不能关闭")
+	ErrMFAKeyMissing     = errors.New("服务端未配置MFA_ENCRYPTION_KEY，暂时无法使用两步验证")
+)
+
+const (
+	// totpSkew 允许前后各一个时间步（30秒）的时钟偏差
+	totpSkew = 1
+	// recoveryCodeCount 每次生成的恢复码数量
+	recoveryCodeCount = 10
+
+	auditResourceRole = "role"
+)
+
+// MFAService TOTP两步验证：绑定身份验证器、生成恢复码、登录时校验验证码，以及按角色要求两步验证
+type MFAService struct {
+	db         *gorm.DB
+	rbac       *RBACService
+	issuer     string
+	key        [32]byte
+	keySet     bool // 未配置加密口令时不能加解密TOTP密钥
+	pendingTTL time.Duration
+}
+
+// NewMFAService 创建两步验证服务
+func NewMFAService(db *gorm.DB) *MFAService {
+	cfg := configs.MFAConfig{Issuer: "抑郁倾向检测系统", PendingTTLMinutes: 5}
+	if configs.GlobalConfig != nil {
+		cfg = configs.GlobalConfig.MFA
+	}
+	return &MFAService{
+		db:         db,
+		rbac:       NewRBACService(db),
+		issuer:     cfg.Issuer,
+		key:        sha256.Sum256([]byte(cfg.EncryptionKey)),
+		keySet:     cfg.EncryptionKey != "",
+		pendingTTL: time.Duration(cfg.PendingTTLMinutes) * time.Minute,
+	}
+}
+
+// Challenge 密码校验通过后判断是否需要两步验证：已启用或所属角色要求时签发两步验证令牌，否则返回nil
+func (s *MFAService) Challenge(user *models.User) (*models.MFAChallengeResponse, error) {
+	enabled, err := s.enabled(s.db, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	required, err := s.rbac.RequiresMFA(user.Role)
+	if err != nil {
+		return nil, err
+	}
+	if !enabled && !required {
+		return nil, nil
+	}
+
+	mfaToken, err := token.GenerateMFAToken(user.ID, user.Username, user.Role, user.TokenVersion, s.pendingTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &models.MFAChallengeResponse{
+		MFARequired:        true,
+		EnrollmentRequired: !enabled,
+		MFAToken:           mfaToken,
+		ExpiresIn:          int(s.pendingTTL.Seconds()),
+	}, nil
+}
+
+// Status 两步验证状态
+func (s *MFAService) Status(user *models.User) (*models.MFAStatusResponse, error) {
+	required, err := s.rbac.RequiresMFA(user.Role)
+	if err != nil {
+		return nil, err
+	}
+	status := &models.MFAStatusResponse{Required: required}
+
+	var mfa models.UserMFA
+	err = s.db.Where("user_id = ? AND confirmed_at IS NOT NULL", user.ID).First(&mfa).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return status, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	status.Enabled = true
+	status.ConfirmedAt = mfa.ConfirmedAt
+
+	var remaining int64
+	if err := s.db.Model(&models.MFARecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", user.ID).
+		Count(&remaining).Error; err != nil {
+		return nil, err
+	}
+	status.RecoveryCodesRemaining = int(remaining)
+	return status, nil
+}
+
+// Setup 生成新的TOTP密钥，确认前不生效；已启用时需要先关闭
+func (s *MFAService) Setup(user *models.User) (*models.MFASetupResponse, error) {
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := s.seal(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var mfa models.UserMFA
+		err := tx.Where("user_id = ?", user.ID).First(&mfa).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return tx.Create(&models.UserMFA{UserID: user.ID, Secret: sealed}).Error
+		}
+		if err != nil {
+			return err
+		}
+		if mfa.ConfirmedAt != nil {
+			return ErrMFAAlreadyEnabled
+		}
+		// 未确认的密钥直接替换
+		return tx.Model(&mfa).Updates(map[string]interface{}{"secret": sealed, "last_used_step": 0}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.MFASetupResponse{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(s.issuer, user.Email, secret),
+	}, nil
+}
+
+// Confirm 用身份验证器上的验证码确认绑定，启用两步验证并返回恢复码
+func (s *MFAService) Confirm(user *models.User, code string) ([]string, error) {
+	var codes []string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var mfa models.UserMFA
+		if err := tx.Where("user_id = ?", user.ID).First(&mfa).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrMFANotSetup
+			}
+			return err
+		}
+		if mfa.ConfirmedAt != nil {
+			return ErrMFAAlreadyEnabled
+		}
+		step, err := s.validate(&mfa, code)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Model(&mfa).Updates(map[string]interface{}{
+			"confirmed_at":   time.Now(),
+			"last_used_step": step,
+		}).Error; err != nil {
+			return err
+		}
+		codes, err = replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	return codes, err
+}
+
+// Verify 登录时校验验证码或恢复码，恢复码使用后失效
+func (s *MFAService) Verify(userID uint, code, recoveryCode string) error {
+	if recoveryCode != "" {
+		return s.useRecoveryCode(userID, recoveryCode)
+	}
+
+	var mfa models.UserMFA
+	if err := s.db.Where("user_id = ? AND confirmed_at IS NOT NULL", userID).First(&mfa).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrMFANotEnabled
+		}
+		return err
+	}
+	step, err := s.validate(&mfa, code)
+	if err != nil {
+		return err
+	}
+	// 同一验证码只能使用一次，并发请求中只有一个成功
+	result := s.db.Model(&models.UserMFA{}).
+		Where("id = ? AND last_used_step < ?", mfa.ID, step).
+		Update("last_used_step", step)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidMFACode
+	}
+	return nil
+}
+
+// Disable 校验密码和验证码后关闭两步验证；所属角色要求两步验证时不能关闭
+func (s *MFAService) Disable(user *models.User, password, code string) error {
+	required, err := s.rbac.RequiresMFA(user.Role)
+	if err != nil {
+		return err
+	}
+	if required {
+		return ErrMFARequiredByRole
+	}
+	if !utils.CheckPassword(password, user.Password) {
+		return ErrWrongPassword
+	}
+	if err := s.Verify(user.ID, code, ""); err != nil {
+		return err
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return removeMFA(tx, user.ID)
+	})
+}
+
+// RegenerateRecoveryCodes 校验验证码后重新生成恢复码，之前的恢复码全部失效
+func (s *MFAService) RegenerateRecoveryCodes(userID uint, code string) ([]string, error) {
+	if err := s.Verify(userID, code, ""); err != nil {
+		return nil, err
+	}
+	var codes []string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		codes, err = replaceRecoveryCodes(tx, userID)
+		return err
+	})
+	return codes, err
+}
+
+// Reset 管理员为丢失身份验证器和恢复码的用户关闭两步验证，写入审计日志；所属角色要求时下次登录需要重新绑定
+func (s *MFAService) Reset(actx AuditContext, userID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.First(&user, userID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrUserNotFound
+			}
+			return err
+		}
+		var mfa models.UserMFA
+		if err := tx.Where("user_id = ?", userID).First(&mfa).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrMFANotEnabled
+			}
+			return err
+		}
+		if err := removeMFA(tx, userID); err != nil {
+			return err
+		}
+		before := map[string]interface{}{"mfa_confirmed_at": mfa.ConfirmedAt}
+		return RecordAudit(tx, actx, "mfa_reset", auditResourceUser, userID, before, nil)
+	})
+}
+
+// SetRoleRequirement 设置角色是否要求两步验证，写入审计日志；
+// 开启时该角色中尚未启用两步验证的用户的登录会话随即失效，下次登录需要先完成绑定
+func (s *MFAService) SetRoleRequirement(actx AuditContext, name string, require bool) (*models.Role, error) {
+	var role models.Role
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("name = ?", name).First(&role).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrRoleNotFound
+			}
+			return err
+		}
+		if role.RequireMFA == require {
+			return nil
+		}
+		before := map[string]interface{}{"require_mfa": role.RequireMFA}
+		if err := tx.Model(&role).Update("require_mfa", require).Error; err != nil {
+			return err
+		}
+		if err := RecordAudit(tx, actx, "require_mfa", auditResourceRole, role.ID, before, map[string]interface{}{"require_mfa": require}); err != nil {
+			return err
+		}
+		if !require {
+			return nil
+		}
+		return revokeWithoutMFA(tx, tx.Model(&models.User{}).Select("id").Where("role = ?", name))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// enabled 用户是否已启用两步验证
+func (s *MFAService) enabled(tx *gorm.DB, userID uint) (bool, error) {
+	var count int64
+	err := tx.Model(&models.UserMFA{}).Where("user_id = ? AND confirmed_at IS NOT NULL", userID).Count(&count).Error
+	return count > 0, err
+}
+
+// validate 解密密钥并校验验证码，返回匹配的时间步；已使用过的时间步视为错误
+func (s *MFAService) validate(mfa *models.UserMFA, code string) (int64, error) {
+	secret, err := s.open(mfa.Secret)
+	if err != nil {
+		return 0, err
+	}
+	step, ok := totp.Validate(secret, code, time.Now(), totpSkew)
+	if !ok || step <= mfa.LastUsedStep {
+		return 0, ErrInvalidMFACode
+	}
+	return step, nil
+}
+
+// useRecoveryCode 使用一个恢复码
+func (s *MFAService) useRecoveryCode(userID uint, code string) error {
+	result := s.db.Model(&models.MFARecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hashToken(normalizeRecoveryCode(code))).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidMFACode
+	}
+	return nil
+}
+
+// seal 用AES-GCM加密TOTP密钥
+func (s *MFAService) seal(secret string) (string, error) {
+	gcm, err := s.cipher()
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, []byte(secret), nil)), nil
+}
+
+// open 解密TOTP密钥
+func (s *MFAService) open(sealed string) (string, error) {
+	gcm, err := s.cipher()
+	if err != nil {
+		return "", err
+	}
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return "", err
+	}
+	if len(data) < gcm.NonceSize() {
+		return "", errors.New("两步验证密钥格式错误")
+	}
+	plain, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", errors.New("两步验证密钥解密失败，请检查MFA_ENCRYPTION_KEY")
+	}
+	return string(plain), nil
+}
+
+func (s *MFAService) cipher() (cipher.AEAD, error) {
+	if !s.keySet {
+		return nil, ErrMFAKeyMissing
+	}
+	block, err := aes.NewCipher(s.key[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// replaceRecoveryCodes 删除旧的恢复码并生成新的一组，返回明文
+func replaceRecoveryCodes(tx *gorm.DB, userID uint) ([]string, error) {
+	if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&models.MFARecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+	codes := make([]string, 0, recoveryCodeCount)
+	rows := make([]models.MFARecoveryCode, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+		rows = append(rows, models.MFARecoveryCode{UserID: userID, CodeHash: hashToken(normalizeRecoveryCode(code))})
+	}
+	if err := tx.Create(&rows).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// removeMFA 删除用户的两步验证密钥和恢复码
+func removeMFA(tx *gorm.DB, userID uint) error {
+	if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&models.UserMFA{}).Error; err != nil {
+		return err
+	}
+	return tx.Unscoped().Where("user_id = ?", userID).Delete(&models.MFARecoveryCode{}).Error
+}
+
+// revokeWithoutMFA 吊销给定用户中尚未启用两步验证者的会话，users为用户ID的子查询
+func revokeWithoutMFA(tx *gorm.DB, users *gorm.DB) error {
+	enrolled := tx.Model(&models.UserMFA{}).Select("user_id").Where("confirmed_at IS NOT NULL")
+	return revokeSessions(tx, models.RevokeReasonMFA, "user_id IN (?) AND user_id NOT IN (?)", users, enrolled)
+}
+
+// newRecoveryCode 生成恢复码，格式为xxxxx-xxxxx（50位随机数）
+func newRecoveryCode() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	code := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(buf))[:10]
+	return code[:5] + "-" + code[5:], nil
+}
+
+// normalizeRecoveryCode 忽略大小写、连字符和空格
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
@@ -0,0 +1,395 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"depression_go/configs"
+	"depression_go/internal/models"
+	"depression_go/pkg/oidc"
+	"depression_go/pkg/utils"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrOIDCProviderNotFound = errors.New("不支持的单点登录提供方")
+	ErrOIDCUnavailable      = errors.New("单点登录提供方暂时不可用，请稍后再试")
+	ErrOIDCInvalidState     = errors.New("登录请求无效或已过期，请重新发起单点登录")
+	ErrOIDCLoginFailed      = errors.New("单点登录校验失败")
+	ErrOIDCEmailRequired    = errors.New("提供方未返回邮箱，无法登录")
+	ErrOIDCEmailNotVerified = errors.New("该邮箱已注册，但提供方未验证该邮箱，请使用密码登录")
+	ErrOIDCEmailNotAllowed  = errors.New("该邮箱域名不允许通过单点登录")
+	ErrOIDCAccountNotFound  = errors.New("没有与该身份关联的账户，请联系管理员")
+	ErrOIDCLocalUnverified  = errors.New("该邮箱已注册但尚未验证，请先用密码登录并验证邮箱后再使用单点登录")
+)
+
+const (
+	// oidcStateTTL 发起单点登录到回调的最长时间
+	oidcStateTTL = 10 * time.Minute
+	// oidcTimeout 与提供方交互（服务发现、换取令牌）的超时时间
+	oidcTimeout = 15 * time.Second
+)
+
+// oidcProviders 已完成服务发现的提供方，进程内共享；发现失败时不缓存，下次请求重试
+var oidcProviders = struct {
+	sync.Mutex
+	m map[string]*oidc.Provider
+}{m: make(map[string]*oidc.Provider)}
+
+// OIDCLogin 单点登录的结果
+type OIDCLogin struct {
+	User    *models.User
+	Email   string // 提供方返回的邮箱，用于登录记录
+	Created bool   // 本次登录自动创建了账户
+	Linked  bool   // 本次登录按邮箱关联了已有账户
+}
+
+// OIDCService OpenID Connect单点登录：授权码流程（PKCE），按外部身份或已验证的邮箱关联账户
+type OIDCService struct {
+	db        *gorm.DB
+	providers map[string]configs.OIDCProviderConfig
+	order     []string
+}
+
+// NewOIDCService 创建单点登录服务
+func NewOIDCService(db *gorm.DB) *OIDCService {
+	s := &OIDCService{db: db, providers: make(map[string]configs.OIDCProviderConfig)}
+	if configs.GlobalConfig != nil {
+		for _, provider := range configs.GlobalConfig.OIDC.Providers {
+			s.providers[provider.Name] = provider
+			s.order = append(s.order, provider.Name)
+		}
+	}
+	return s
+}
+
+// Providers 已配置的提供方，按配置顺序
+func (s *OIDCService) Providers() []models.OIDCProviderResponse {
+	list := make([]models.OIDCProviderResponse, 0, len(s.order))
+	for _, name := range s.order {
+		list = append(list, models.OIDCProviderResponse{Name: name, DisplayName: s.providers[name].DisplayName})
+	}
+	return list
+}
+
+// Begin 发起单点登录：保存state、nonce和PKCE校验码，返回提供方的授权地址；reauth要求提供方重新认证本人
+func (s *OIDCService) Begin(ctx context.Context, name string, reauth bool) (*models.OIDCAuthorizeResponse, error) {
+	provider, err := s.provider(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := oidc.NewState()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := oidc.NewState()
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := oidc.NewState()
+	if err != nil {
+		return nil, err
+	}
+
+	record := models.OIDCState{
+		StateHash:    hashToken(state),
+		Provider:     name,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    time.Now().Add(oidcStateTTL),
+	}
+	if err := s.db.Create(&record).Error; err != nil {
+		return nil, err
+	}
+
+	return &models.OIDCAuthorizeResponse{
+		AuthorizationURL: provider.AuthCodeURL(state, nonce, oidc.CodeChallenge(verifier), reauth),
+		State:            state,
+		ExpiresIn:        int(oidcStateTTL.Seconds()),
+	}, nil
+}
+
+// Complete 回调：校验state，用授权码换取并校验ID令牌，然后找到或创建对应的账户
+func (s *OIDCService) Complete(ctx context.Context, name, code, state string) (*OIDCLogin, error) {
+	cfg, ok := s.providers[name]
+	if !ok {
+		return nil, ErrOIDCProviderNotFound
+	}
+	record, err := s.consumeState(name, state)
+	if err != nil {
+		return nil, err
+	}
+	provider, err := s.provider(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, oidcTimeout)
+	defer cancel()
+	tokens, err := provider.Exchange(ctx, code, record.CodeVerifier)
+	if err != nil {
+		log.Printf("OIDC换取令牌失败(%s): %v", name, err)
+		return nil, ErrOIDCLoginFailed
+	}
+	claims, err := provider.VerifyIDToken(ctx, tokens.IDToken, record.Nonce)
+	if err != nil {
+		log.Printf("OIDC校验ID令牌失败(%s): %v", name, err)
+		return nil, ErrOIDCLoginFailed
+	}
+	// 部分提供方只在userinfo中返回邮箱
+	if claims.Email == "" && tokens.AccessToken != "" {
+		if info, err := provider.UserInfo(ctx, tokens.AccessToken, claims.Subject); err != nil {
+			log.Printf("OIDC读取userinfo失败(%s): %v", name, err)
+		} else {
+			claims.Email, claims.EmailVerified = info.Email, info.EmailVerified
+			if claims.PreferredUsername == "" {
+				claims.PreferredUsername = info.PreferredUsername
+			}
+		}
+	}
+	claims.Email = strings.ToLower(strings.TrimSpace(claims.Email))
+
+	return s.login(cfg, claims)
+}
+
+// login 按外部身份找到账户；首次登录时按已验证的邮箱关联已有账户，或自动创建账户
+func (s *OIDCService) login(cfg configs.OIDCProviderConfig, claims *oidc.Claims) (*OIDCLogin, error) {
+	result := &OIDCLogin{Email: claims.Email}
+	if claims.Email != "" && !emailDomainAllowed(claims.Email, cfg.AllowedDomains) {
+		return nil, ErrOIDCEmailNotAllowed
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		authenticatedAt := authTime(claims, now)
+		var identity models.UserIdentity
+		err := tx.Where("provider = ? AND subject = ?", cfg.Name, claims.Subject).First(&identity).Error
+		if err == nil {
+			var user models.User
+			if err := tx.First(&user, identity.UserID).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return ErrOIDCAccountNotFound
+				}
+				return err
+			}
+			result.User = &user
+			return tx.Model(&identity).Updates(map[string]interface{}{
+				"email":            claims.Email,
+				"last_login_at":    now,
+				"authenticated_at": authenticatedAt,
+			}).Error
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		// 首次使用该身份登录
+		if claims.Email == "" {
+			return ErrOIDCEmailRequired
+		}
+		if len(cfg.AllowedDomains) > 0 && !claims.EmailVerified {
+			return ErrOIDCEmailNotAllowed
+		}
+		var user models.User
+		err = tx.Where("email = ?", claims.Email).First(&user).Error
+		switch {
+		case err == nil:
+			// 邮箱已注册：只有提供方确认过该邮箱才能关联，否则可能被冒用
+			if !cfg.LinkByEmail {
+				return ErrOIDCAccountNotFound
+			}
+			if !claims.EmailVerified {
+				return ErrOIDCEmailNotVerified
+			}
+			// 本地账户也必须证明过拥有该邮箱，否则他人可以抢先用该邮箱注册，等本人单点登录后共用账户
+			if user.EmailVerifiedAt == nil {
+				return ErrOIDCLocalUnverified
+			}
+			result.Linked = true
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			if !cfg.AutoCreate {
+				return ErrOIDCAccountNotFound
+			}
+			created, err := s.createUser(tx, claims)
+			if err != nil {
+				return err
+			}
+			user = *created
+			result.Created = true
+		default:
+			return err
+		}
+
+		result.User = &user
+		return tx.Create(&models.UserIdentity{
+			UserID:          user.ID,
+			Provider:        cfg.Name,
+			Subject:         claims.Subject,
+			Email:           claims.Email,
+			LastLoginAt:     &now,
+			AuthenticatedAt: authenticatedAt,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// authTime ID令牌中的auth_time，未返回时为nil；晚于当前时间的按当前时间算
+func authTime(claims *oidc.Claims, now time.Time) *time.Time {
+	if claims.AuthTime <= 0 {
+		return nil
+	}
+	at := time.Unix(claims.AuthTime, 0)
+	if at.After(now) {
+		at = now
+	}
+	return &at
+}
+
+// createUser 自动创建账户：用户名取自preferred_username或邮箱前缀，密码随机（需要时可通过找回密码设置）
+func (s *OIDCService) createUser(tx *gorm.DB, claims *oidc.Claims) (*models.User, error) {
+	username, err := availableUsername(tx, claims)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := newRandomToken()
+	if err != nil {
+		return nil, err
+	}
+	hashedPassword, err := utils.HashPassword(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	user := models.User{
+		Username:       username,
+		Email:          claims.Email,
+		Password:       hashedPassword,
+		RandomPassword: true,
+		Gender:         models.GenderUnknown,
+		Status:         1,
+		Role:           models.RoleUser,
+	}
+	if claims.EmailVerified {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+	if err := tx.Create(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// consumeState 取出并删除state，过期或已使用时返回ErrOIDCInvalidState
+func (s *OIDCService) consumeState(name, state string) (*models.OIDCState, error) {
+	var record models.OIDCState
+	err := s.db.Where("state_hash = ? AND provider = ?", hashToken(state), name).First(&record).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrOIDCInvalidState
+	}
+	if err != nil {
+		return nil, err
+	}
+	// 并发回调时只有一个能删除成功
+	deleted := s.db.Delete(&models.OIDCState{}, record.ID)
+	if deleted.Error != nil {
+		return nil, deleted.Error
+	}
+	if deleted.RowsAffected == 0 || time.Now().After(record.ExpiresAt) {
+		return nil, ErrOIDCInvalidState
+	}
+	return &record, nil
+}
+
+// provider 返回已完成服务发现的提供方，首次使用时才请求发现文档
+func (s *OIDCService) provider(ctx context.Context, name string) (*oidc.Provider, error) {
+	cfg, ok := s.providers[name]
+	if !ok {
+		return nil, ErrOIDCProviderNotFound
+	}
+
+	oidcProviders.Lock()
+	defer oidcProviders.Unlock()
+	if provider, ok := oidcProviders.m[name]; ok {
+		return provider, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, oidcTimeout)
+	defer cancel()
+	provider, err := oidc.Discover(ctx, oidc.Config{
+		Issuer:       cfg.Issuer,
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		RedirectURL:  cfg.RedirectURL,
+		Scopes:       cfg.Scopes,
+	}, nil)
+	if err != nil {
+		log.Printf("OIDC提供方%s不可用: %v", name, err)
+		return nil, ErrOIDCUnavailable
+	}
+	oidcProviders.m[name] = provider
+	return provider, nil
+}
+
+// availableUsername 生成未被占用的用户名，重名时追加随机后缀
+func availableUsername(tx *gorm.DB, claims *oidc.Claims) (string, error) {
+	base := sanitizeUsername(claims.PreferredUsername)
+	if base == "" {
+		base = sanitizeUsername(strings.SplitN(claims.Email, "@", 2)[0])
+	}
+	if len([]rune(base)) < 3 {
+		base = "user" + base
+	}
+
+	candidate := base
+	for i := 0; i < 5; i++ {
+		var count int64
+		if err := tx.Unscoped().Model(&models.User{}).Where("username = ?", candidate).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return candidate, nil
+		}
+		suffix := make([]byte, 3)
+		if _, err := rand.Read(suffix); err != nil {
+			return "", err
+		}
+		candidate = base + "_" + hex.EncodeToString(suffix)
+	}
+	return "", fmt.Errorf("无法为%s生成可用的用户名", claims.Email)
+}
+
+// sanitizeUsername 只保留字母、数字和 _ . -，最长40个字符（留出重名后缀的位置）
+func sanitizeUsername(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.' || r == '-' {
+			return r
+		}
+		return -1
+	}, strings.TrimSpace(name))
+	return truncate(name, 40)
+}
+
+// emailDomainAllowed 邮箱域名是否在允许列表中，列表为空时不限制
+func emailDomainAllowed(email string, domains []string) bool {
+	if len(domains) == 0 {
+		return true
+	}
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	return containsString(domains, email[at+1:])
+}
@@ -0,0 +1,245 @@
+package services
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+
+	"depression_go/configs"
+	"depression_go/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// 作答质量检查项
+const (
+	QualityReasonStraightLining = "straight_lining"    // 选择题几乎全部选同一选项
+	QualityReasonTooFast        = "too_fast"           // 每题作答用时过短
+	QualityReasonInconsistent   = "inconsistent_pairs" // 配对题目前后矛盾
+)
+
+// qualityLowThreshold 随意作答可能性达到该值时标记为低质量
+const qualityLowThreshold = 0.5
+
+// QualitySettings 作答质量检查参数
+type QualitySettings struct {
+	MinMsPerItem         int
+	StraightLineMinItems int
+	ConsistencyTolerance int
+}
+
+// QualityItem 参与质量检查的一道已作答题目
+type QualityItem struct {
+	Question       models.Question
+	AnswerValue    int
+	ResponseTimeMs int
+}
+
+// QualityResult 作答质量检查结果
+type QualityResult struct {
+	Flag       string   `json:"flag"`       // ok 或 low
+	Confidence float64  `json:"confidence"` // 判定结果的置信度（0-1）
+	Reasons    []string `json:"reasons"`    // 触发的检查项
+}
+
+// qualitySettings 读取作答质量检查配置
+func qualitySettings() QualitySettings {
+	settings := QualitySettings{MinMsPerItem: 1000, StraightLineMinItems: 5, ConsistencyTolerance: 1}
+	if configs.GlobalConfig != nil {
+		cfg := configs.GlobalConfig.Quality
+		if cfg.MinMsPerItem > 0 {
+			settings.MinMsPerItem = cfg.MinMsPerItem
+		}
+		if cfg.StraightLineMinItems > 0 {
+			settings.StraightLineMinItems = cfg.StraightLineMinItems
+		}
+		if cfg.ConsistencyTolerance > 0 {
+			settings.ConsistencyTolerance = cfg.ConsistencyTolerance
+		}
+	}
+	return settings
+}
+
+// EvaluateQuality 检查一次评估的作答质量：一律选同一选项、作答过快、配对题目前后矛盾。
+// 每项检查给出随意作答的证据强度，合并为随意作答可能性，达到阈值时标记为低质量
+func EvaluateQuality(items []QualityItem, settings QualitySettings) QualityResult {
+	var reasons []string
+	notCareless := 1.0
+
+	if weight := straightLiningWeight(items, settings); weight > 0 {
+		reasons = append(reasons, QualityReasonStraightLining)
+		notCareless *= 1 - weight
+	}
+	if weight := tooFastWeight(items, settings); weight > 0 {
+		reasons = append(reasons, QualityReasonTooFast)
+		notCareless *= 1 - weight
+	}
+	if weight := inconsistencyWeight(items, settings); weight > 0 {
+		reasons = append(reasons, QualityReasonInconsistent)
+		notCareless *= 1 - weight
+	}
+
+	careless := 1 - notCareless
+	result := QualityResult{Flag: models.QualityFlagOK, Confidence: notCareless, Reasons: reasons}
+	if careless >= qualityLowThreshold {
+		result.Flag = models.QualityFlagLow
+		result.Confidence = careless
+	}
+	result.Confidence = math.Round(result.Confidence*100) / 100
+	return result
+}
+
+// straightLiningUncorroborated 没有反向配对题时一律选同一选项的证据上限：单维度量表上全部选"没有"或全部选最重一项
+// 都可能是真实作答，单凭此项不能判为低质量，只有与其他检查项同时出现时才会达到阈值
+const straightLiningUncorroborated = 0.4
+
+// straightLiningWeight 选择题中同一选项的占比达到90%以上视为一律选同一选项；
+// 作答中包含反向配对题时，同一选项必然与之矛盾，才作为较强的证据
+func straightLiningWeight(items []QualityItem, settings QualitySettings) float64 {
+	answered := make(map[uint]bool, len(items))
+	for _, item := range items {
+		if item.AnswerValue > 0 {
+			answered[item.Question.ID] = true
+		}
+	}
+
+	counts := map[int]int{}
+	total := 0
+	reversed := false
+	for _, item := range items {
+		if item.Question.Type != models.QuestionTypeSingle || item.AnswerValue <= 0 {
+			continue
+		}
+		counts[item.AnswerValue]++
+		total++
+		if item.Question.ConsistencyReversed && answered[item.Question.ConsistencyPairID] {
+			reversed = true
+		}
+	}
+	if total < settings.StraightLineMinItems {
+		return 0
+	}
+
+	most := 0
+	for _, count := range counts {
+		if count > most {
+			most = count
+		}
+	}
+	share := float64(most) / float64(total)
+	weight := 0.0
+	switch {
+	case share == 1:
+		weight = 0.7
+	case share >= 0.9:
+		weight = 0.5
+	}
+	if !reversed {
+		weight = math.Min(weight, straightLiningUncorroborated)
+	}
+	return weight
+}
+
+// tooFastWeight 一半以上题目有作答用时时，按用时中位数判断是否作答过快
+func tooFastWeight(items []QualityItem, settings QualitySettings) float64 {
+	var times []int
+	for _, item := range items {
+		if item.ResponseTimeMs > 0 {
+			times = append(times, item.ResponseTimeMs)
+		}
+	}
+	if len(times) == 0 || len(times)*2 < len(items) {
+		return 0
+	}
+
+	sort.Ints(times)
+	median := times[len(times)/2]
+	if len(times)%2 == 0 {
+		median = (times[len(times)/2-1] + times[len(times)/2]) / 2
+	}
+	switch {
+	case median < settings.MinMsPerItem/2:
+		return 0.8
+	case median < settings.MinMsPerItem:
+		return 0.6
+	default:
+		return 0
+	}
+}
+
+// inconsistencyWeight 配对题目的选项值（反向题先换算为同向）相差超过容差视为前后矛盾，
+// 矛盾的配对占比越高证据越强；只有一两组配对时证据有限，单凭此项不足以判为低质量
+func inconsistencyWeight(items []QualityItem, settings QualitySettings) float64 {
+	byID := make(map[uint]QualityItem, len(items))
+	for _, item := range items {
+		byID[item.Question.ID] = item
+	}
+
+	checked := map[[2]uint]bool{}
+	pairs, inconsistent := 0, 0
+	for _, item := range items {
+		pairID := item.Question.ConsistencyPairID
+		paired, ok := byID[pairID]
+		if pairID == 0 || !ok || item.AnswerValue <= 0 || paired.AnswerValue <= 0 {
+			continue
+		}
+		key := [2]uint{item.Question.ID, pairID}
+		if key[0] > key[1] {
+			key[0], key[1] = key[1], key[0]
+		}
+		if checked[key] {
+			continue
+		}
+		checked[key] = true
+
+		expected := paired.AnswerValue
+		if item.Question.ConsistencyReversed {
+			expected = optionCount(paired.Question) + 1 - paired.AnswerValue
+		}
+		pairs++
+		if diff := item.AnswerValue - expected; diff > settings.ConsistencyTolerance || -diff > settings.ConsistencyTolerance {
+			inconsistent++
+		}
+	}
+	if inconsistent == 0 {
+		return 0
+	}
+	return float64(inconsistent) / float64(pairs) * math.Min(0.2+0.2*float64(pairs), 0.8)
+}
+
+// optionCount 题目的选项数量
+func optionCount(question models.Question) int {
+	var options []string
+	_ = json.Unmarshal([]byte(question.Options), &options)
+	return len(options)
+}
+
+// evaluateQuality 按作答时的题目内容检查评估的作答质量，结果写入assessment（由调用方持久化）
+func (s *AssessmentService) evaluateQuality(tx *gorm.DB, assessment *models.Assessment, answers []models.Answer) (*QualityResult, error) {
+	questions, err := s.versions.AnsweredQuestions(tx, answers)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]QualityItem, 0, len(answers))
+	for i, answer := range answers {
+		items = append(items, QualityItem{
+			Question:       questions[i],
+			AnswerValue:    answer.AnswerValue,
+			ResponseTimeMs: answer.ResponseTimeMs,
+		})
+	}
+	quality := EvaluateQuality(items, qualitySettings())
+	if quality.Reasons == nil {
+		quality.Reasons = []string{}
+	}
+
+	reasons, err := json.Marshal(quality.Reasons)
+	if err != nil {
+		return nil, err
+	}
+	assessment.QualityFlag = quality.Flag
+	assessment.QualityConfidence = quality.Confidence
+	assessment.QualityReasons = string(reasons)
+	return &quality, nil
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"depression_go/internal/models"
+)
+
+// qualityItems 按选项值和作答用时生成单选题，题目ID从1开始
+func qualityItems(values []int, ms int) []QualityItem {
+	items := make([]QualityItem, len(values))
+	for i, value := range values {
+		question := models.Question{Type: models.QuestionTypeSingle, Options: `["没有","有几天","一半以上","几乎每天"]`}
+		question.ID = uint(i + 1)
+		items[i] = QualityItem{Question: question, AnswerValue: value, ResponseTimeMs: ms}
+	}
+	return items
+}
+
+// reversedPair 将第j题设为第i题的反向配对题
+func reversedPair(items []QualityItem, i, j int) []QualityItem {
+	items[j].Question.ConsistencyPairID = items[i].Question.ID
+	items[j].Question.ConsistencyReversed = true
+	return items
+}
+
+func TestEvaluateQuality(t *testing.T) {
+	settings := QualitySettings{MinMsPerItem: 1000, StraightLineMinItems: 5, ConsistencyTolerance: 1}
+	tests := []struct {
+		name       string
+		items      []QualityItem
+		wantFlag   string
+		wantReason []string
+		wantConf   float64
+	}{
+		{
+			name:     "正常作答",
+			items:    qualityItems([]int{1, 2, 3, 2, 1, 4}, 3000),
+			wantFlag: models.QualityFlagOK, wantReason: nil, wantConf: 1,
+		},
+		{
+			name:     "单凭一律选同一选项不判为低质量",
+			items:    qualityItems([]int{1, 1, 1, 1, 1, 1}, 3000),
+			wantFlag: models.QualityFlagOK, wantReason: []string{QualityReasonStraightLining}, wantConf: 0.6,
+		},
+		{
+			name:     "题数不足时不检查一律选同一选项",
+			items:    qualityItems([]int{1, 1, 1, 1}, 3000),
+			wantFlag: models.QualityFlagOK, wantReason: nil, wantConf: 1,
+		},
+		{
+			name:     "一律选同一选项且与反向配对题矛盾",
+			items:    reversedPair(qualityItems([]int{1, 1, 1, 1, 1, 1}, 3000), 0, 1),
+			wantFlag: models.QualityFlagLow, wantReason: []string{QualityReasonStraightLining, QualityReasonInconsistent}, wantConf: 0.82,
+		},
+		{
+			name:     "一律选同一选项且作答过快",
+			items:    qualityItems([]int{2, 2, 2, 2, 2, 2}, 300),
+			wantFlag: models.QualityFlagLow, wantReason: []string{QualityReasonStraightLining, QualityReasonTooFast}, wantConf: 0.88,
+		},
+		{
+			name:     "作答偏快",
+			items:    qualityItems([]int{1, 2, 3, 2, 1, 4}, 800),
+			wantFlag: models.QualityFlagLow, wantReason: []string{QualityReasonTooFast}, wantConf: 0.6,
+		},
+		{
+			name:     "没有作答用时不检查作答速度",
+			items:    qualityItems([]int{1, 2, 3, 2, 1, 4}, 0),
+			wantFlag: models.QualityFlagOK, wantReason: nil, wantConf: 1,
+		},
+		{
+			name:     "反向配对题前后一致",
+			items:    reversedPair(qualityItems([]int{1, 4, 2, 3, 2, 1}, 3000), 0, 1),
+			wantFlag: models.QualityFlagOK, wantReason: nil, wantConf: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := EvaluateQuality(tt.items, settings)
+			if got.Flag != tt.wantFlag {
+				t.Errorf("Flag = %q, want %q", got.Flag, tt.wantFlag)
+			}
+			if !reflect.DeepEqual(got.Reasons, tt.wantReason) {
+				t.Errorf("Reasons = %v, want %v", got.Reasons, tt.wantReason)
+			}
+			if got.Confidence != tt.wantConf {
+				t.Errorf("Confidence = %v, want %v", got.Confidence, tt.wantConf)
+			}
+		})
+	}
+}
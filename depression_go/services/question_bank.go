@@ -0,0 +1,422 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"depression_go/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// auditResourceQuestion 审计日志中的问题资源类型
+const auditResourceQuestion = "question"
+
+var (
+	ErrQuestionNotDeleted = errors.New("问题未被删除，无需恢复")
+	ErrInvalidQuestion    = errors.New("问题数据不合法")
+)
+
+// QuestionBankService 题库管理服务，所有变更都会写入审计日志并发布新的问卷版本
+type QuestionBankService struct {
+	db       *gorm.DB
+	versions *QuestionnaireVersionService
+}
+
+// NewQuestionBankService 创建题库管理服务
+func NewQuestionBankService(db *gorm.DB) *QuestionBankService {
+	return &QuestionBankService{
+		db:       db,
+		versions: NewQuestionnaireVersionService(db),
+	}
+}
+
+// QuestionFilter 题库查询条件
+type QuestionFilter struct {
+	Category       string
+	Status         *int
+	IncludeDeleted bool
+}
+
+// List 查询题库，可包含已删除的问题
+func (s *QuestionBankService) List(filter QuestionFilter) ([]models.Question, error) {
+	query := s.db.Model(&models.Question{})
+	if filter.IncludeDeleted {
+		query = query.Unscoped()
+	}
+	if filter.Category != "" {
+		query = query.Where("category = ?", filter.Category)
+	}
+	if filter.Status != nil {
+		query = query.Where("status = ?", *filter.Status)
+	}
+
+	var questions []models.Question
+	err := query.Order("order_num ASC, id ASC").Find(&questions).Error
+	return questions, err
+}
+
+// Create 创建问题
+func (s *QuestionBankService) Create(actx AuditContext, req models.QuestionCreateRequest) (*models.Question, error) {
+	question := models.Question{
+		Title:        req.Title,
+		Description:  req.Description,
+		Type:         req.Type,
+		Category:     req.Category,
+		Options:      req.Options,
+		Score:        req.Score,
+		OrderNum:     req.OrderNum,
+		Status:       1,
+		RiskItem:     req.RiskItem,
+		RiskBaseline: models.DefaultRiskBaseline,
+
+		IRTDiscrimination: req.IRTDiscrimination,
+		IRTThresholds:     req.IRTThresholds,
+
+		ConsistencyPairID:   req.ConsistencyPairID,
+		ConsistencyReversed: req.ConsistencyReversed,
+	}
+	// 0表示任何作答均触发，只有未传时才使用默认值
+	if req.RiskBaseline != nil {
+		question.RiskBaseline = *req.RiskBaseline
+	}
+	if err := ValidateQuestion(question); err != nil {
+		return nil, err
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.checkPair(tx, question); err != nil {
+			return err
+		}
+		if err := tx.Create(&question).Error; err != nil {
+			return err
+		}
+		if err := RecordAudit(tx, actx, "create", auditResourceQuestion, question.ID, nil, question); err != nil {
+			return err
+		}
+		return s.publish(tx, actx, fmt.Sprintf("创建问题#%d", question.ID))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &question, nil
+}
+
+// Update 更新问题，仅修改请求中给出的字段
+func (s *QuestionBankService) Update(actx AuditContext, id uint, req models.QuestionUpdateRequest) (*models.Question, error) {
+	var question models.Question
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		before, err := s.find(tx, id, false)
+		if err != nil {
+			return err
+		}
+		question = *before
+
+		if req.Title != nil {
+			question.Title = *req.Title
+		}
+		if req.Description != nil {
+			question.Description = *req.Description
+		}
+		if req.Type != nil {
+			question.Type = *req.Type
+		}
+		if req.Category != nil {
+			question.Category = *req.Category
+		}
+		if req.Options != nil {
+			question.Options = *req.Options
+		}
+		if req.Score != nil {
+			question.Score = *req.Score
+		}
+		if req.OrderNum != nil {
+			question.OrderNum = *req.OrderNum
+		}
+		if req.Status != nil {
+			question.Status = *req.Status
+		}
+		if req.RiskItem != nil {
+			question.RiskItem = *req.RiskItem
+		}
+		if req.RiskBaseline != nil {
+			question.RiskBaseline = *req.RiskBaseline
+		}
+		if req.IRTDiscrimination != nil {
+			question.IRTDiscrimination = *req.IRTDiscrimination
+		}
+		if req.IRTThresholds != nil {
+			question.IRTThresholds = *req.IRTThresholds
+		}
+		if req.ConsistencyPairID != nil {
+			question.ConsistencyPairID = *req.ConsistencyPairID
+		}
+		if req.ConsistencyReversed != nil {
+			question.ConsistencyReversed = *req.ConsistencyReversed
+		}
+		if err := ValidateQuestion(question); err != nil {
+			return err
+		}
+		if err := s.checkPair(tx, question); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&question).Updates(map[string]interface{}{
+			"title":         question.Title,
+			"description":   question.Description,
+			"type":          question.Type,
+			"category":      question.Category,
+			"options":       question.Options,
+			"score":         question.Score,
+			"order_num":     question.OrderNum,
+			"status":        question.Status,
+			"risk_item":     question.RiskItem,
+			"risk_baseline": question.RiskBaseline,
+
+			"irt_discrimination": question.IRTDiscrimination,
+			"irt_thresholds":     question.IRTThresholds,
+
+			"consistency_pair_id":  question.ConsistencyPairID,
+			"consistency_reversed": question.ConsistencyReversed,
+		}).Error; err != nil {
+			return err
+		}
+		if err := RecordAudit(tx, actx, "update", auditResourceQuestion, question.ID, before, question); err != nil {
+			return err
+		}
+		return s.publish(tx, actx, fmt.Sprintf("更新问题#%d", question.ID))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &question, nil
+}
+
+// SetStatus 启用或禁用问题
+func (s *QuestionBankService) SetStatus(actx AuditContext, id uint, status int) (*models.Question, error) {
+	if status != 0 && status != 1 {
+		return nil, fmt.Errorf("%w: 状态只能为0或1", ErrInvalidQuestion)
+	}
+
+	var question *models.Question
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		question, err = s.find(tx, id, false)
+		if err != nil {
+			return err
+		}
+		before := *question
+		if err := tx.Model(question).Update("status", status).Error; err != nil {
+			return err
+		}
+		question.Status = status
+		if err := RecordAudit(tx, actx, "status", auditResourceQuestion, id, before, question); err != nil {
+			return err
+		}
+		return s.publish(tx, actx, fmt.Sprintf("调整问题#%d状态", id))
+	})
+	return question, err
+}
+
+// Delete 软删除问题，历史答案仍可关联
+func (s *QuestionBankService) Delete(actx AuditContext, id uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		question, err := s.find(tx, id, false)
+		if err != nil {
+			return err
+		}
+		if err := tx.Delete(question).Error; err != nil {
+			return err
+		}
+		if err := RecordAudit(tx, actx, "delete", auditResourceQuestion, id, question, nil); err != nil {
+			return err
+		}
+		return s.publish(tx, actx, fmt.Sprintf("删除问题#%d", id))
+	})
+}
+
+// Restore 恢复已软删除的问题
+func (s *QuestionBankService) Restore(actx AuditContext, id uint) (*models.Question, error) {
+	var question *models.Question
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		question, err = s.find(tx, id, true)
+		if err != nil {
+			return err
+		}
+		if !question.DeletedAt.Valid {
+			return ErrQuestionNotDeleted
+		}
+		if err := tx.Unscoped().Model(question).Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		question.DeletedAt = gorm.DeletedAt{}
+		if err := RecordAudit(tx, actx, "restore", auditResourceQuestion, id, nil, question); err != nil {
+			return err
+		}
+		return s.publish(tx, actx, fmt.Sprintf("恢复问题#%d", id))
+	})
+	return question, err
+}
+
+// Reorder 批量调整问题排序号
+func (s *QuestionBankService) Reorder(actx AuditContext, items []models.QuestionOrderItem) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		before := make([]models.QuestionOrderItem, 0, len(items))
+		for _, item := range items {
+			question, err := s.find(tx, item.ID, false)
+			if err != nil {
+				return err
+			}
+			before = append(before, models.QuestionOrderItem{ID: question.ID, OrderNum: question.OrderNum})
+			if err := tx.Model(question).Update("order_num", item.OrderNum).Error; err != nil {
+				return err
+			}
+		}
+		if err := RecordAudit(tx, actx, "reorder", auditResourceQuestion, 0, before, items); err != nil {
+			return err
+		}
+		return s.publish(tx, actx, "调整题目排序")
+	})
+}
+
+// publish 题库变更后发布新的问卷版本，已有评估仍按各自记录的版本渲染和计分
+func (s *QuestionBankService) publish(tx *gorm.DB, actx AuditContext, note string) error {
+	_, err := s.versions.Publish(tx, actx.ActorID, note)
+	return err
+}
+
+// find 查找问题，includeDeleted为true时包含已软删除的问题
+func (s *QuestionBankService) find(tx *gorm.DB, id uint, includeDeleted bool) (*models.Question, error) {
+	if includeDeleted {
+		tx = tx.Unscoped()
+	}
+	var question models.Question
+	if err := tx.First(&question, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %d", ErrQuestionNotFound, id)
+		}
+		return nil, err
+	}
+	return &question, nil
+}
+
+// ValidateQuestion 校验问题类型、分类和选项格式
+func ValidateQuestion(question models.Question) error {
+	if strings.TrimSpace(question.Title) == "" {
+		return fmt.Errorf("%w: 标题不能为空", ErrInvalidQuestion)
+	}
+	if strings.TrimSpace(question.Category) == "" {
+		return fmt.Errorf("%w: 分类不能为空", ErrInvalidQuestion)
+	}
+	if question.Score < 0 {
+		return fmt.Errorf("%w: 权重分数不能为负数", ErrInvalidQuestion)
+	}
+	if question.Status != 0 && question.Status != 1 {
+		return fmt.Errorf("%w: 状态只能为0或1", ErrInvalidQuestion)
+	}
+	if question.RiskBaseline < 0 {
+		return fmt.Errorf("%w: 风险基线不能为负数", ErrInvalidQuestion)
+	}
+
+	var options []string
+	switch question.Type {
+	case models.QuestionTypeText:
+	case models.QuestionTypeSingle, models.QuestionTypeMultiple:
+		if err := json.Unmarshal([]byte(question.Options), &options); err != nil || len(options) == 0 {
+			return fmt.Errorf("%w: 选项必须是非空的JSON字符串数组", ErrInvalidQuestion)
+		}
+	default:
+		return fmt.Errorf("%w: 不支持的问题类型%s", ErrInvalidQuestion, question.Type)
+	}
+
+	if err := validateIRT(question, len(options)); err != nil {
+		return err
+	}
+	if err := validateCheckin(question); err != nil {
+		return err
+	}
+	return validatePair(question)
+}
+
+// validateCheckin 校验每日打卡题目：只能是单选题，不作为风险条目、不参与自适应测验和一致性检查
+func validateCheckin(question models.Question) error {
+	if question.Category != models.QuestionCategoryCheckin {
+		return nil
+	}
+	if question.Type != models.QuestionTypeSingle {
+		return fmt.Errorf("%w: 打卡题目只能是单选题", ErrInvalidQuestion)
+	}
+	if question.RiskItem || question.IRTDiscrimination != 0 || question.ConsistencyPairID != 0 {
+		return fmt.Errorf("%w: 打卡题目不能设置风险条目、IRT参数或一致性配对", ErrInvalidQuestion)
+	}
+	return nil
+}
+
+// validatePair 校验一致性配对：只有单选题可以配对，且不能与自身配对
+func validatePair(question models.Question) error {
+	if question.ConsistencyPairID == 0 {
+		if question.ConsistencyReversed {
+			return fmt.Errorf("%w: 未设置配对题目时不能标记为反向", ErrInvalidQuestion)
+		}
+		return nil
+	}
+	if question.Type != models.QuestionTypeSingle {
+		return fmt.Errorf("%w: 只有单选题可以设置一致性配对", ErrInvalidQuestion)
+	}
+	if question.ID != 0 && question.ConsistencyPairID == question.ID {
+		return fmt.Errorf("%w: 不能与自身配对", ErrInvalidQuestion)
+	}
+	return nil
+}
+
+// checkPair 检查配对的题目存在且为单选题
+func (s *QuestionBankService) checkPair(tx *gorm.DB, question models.Question) error {
+	if question.ConsistencyPairID == 0 {
+		return nil
+	}
+	var paired models.Question
+	if err := tx.First(&paired, question.ConsistencyPairID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: 配对题目%d不存在", ErrInvalidQuestion, question.ConsistencyPairID)
+		}
+		return err
+	}
+	if paired.Type != models.QuestionTypeSingle {
+		return fmt.Errorf("%w: 配对题目%d不是单选题", ErrInvalidQuestion, question.ConsistencyPairID)
+	}
+	if paired.Category == models.QuestionCategoryCheckin {
+		return fmt.Errorf("%w: 配对题目%d是打卡题目", ErrInvalidQuestion, question.ConsistencyPairID)
+	}
+	return nil
+}
+
+// validateIRT 校验IRT参数：区分度为0表示不参与自适应测验；
+// 大于0时必须是单选题，阈值为长度等于选项数-1的递增数组
+func validateIRT(question models.Question, optionCount int) error {
+	if question.IRTDiscrimination < 0 {
+		return fmt.Errorf("%w: IRT区分度不能为负数", ErrInvalidQuestion)
+	}
+	if question.IRTDiscrimination == 0 {
+		return nil
+	}
+	if question.Type != models.QuestionTypeSingle {
+		return fmt.Errorf("%w: 只有单选题可以设置IRT参数", ErrInvalidQuestion)
+	}
+
+	var thresholds []float64
+	if err := json.Unmarshal([]byte(question.IRTThresholds), &thresholds); err != nil {
+		return fmt.Errorf("%w: IRT阈值必须是JSON数字数组", ErrInvalidQuestion)
+	}
+	if len(thresholds) != optionCount-1 {
+		return fmt.Errorf("%w: IRT阈值数量(%d)必须等于选项数-1(%d)", ErrInvalidQuestion, len(thresholds), optionCount-1)
+	}
+	for i := 1; i < len(thresholds); i++ {
+		if thresholds[i] <= thresholds[i-1] {
+			return fmt.Errorf("%w: IRT阈值必须递增", ErrInvalidQuestion)
+		}
+	}
+	return nil
+}
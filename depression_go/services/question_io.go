@@ -0,0 +1,676 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"depression_go/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// 题库导入导出格式
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+	FormatFHIR = "fhir"
+)
+
+// FHIR扩展地址
+const (
+	fhirOrdinalValueURL  = "http://hl7.org/fhir/StructureDefinition/ordinalValue"
+	fhirExtensionBase    = "urn:depression-ai:fhir:extension:"
+	fhirCategorySystem   = "urn:depression-ai:question-category"
+	fhirExtWeight        = fhirExtensionBase + "question-weight"
+	fhirExtOrder         = fhirExtensionBase + "order-num"
+	fhirExtStatus        = fhirExtensionBase + "question-status"
+	fhirExtRiskItem      = fhirExtensionBase + "risk-item"
+	fhirExtRiskBaseline  = fhirExtensionBase + "risk-baseline"
+	fhirExtDescription   = fhirExtensionBase + "question-description"
+	fhirExtIRTA          = fhirExtensionBase + "irt-discrimination"
+	fhirExtIRTB          = fhirExtensionBase + "irt-thresholds"
+	fhirExtPair          = fhirExtensionBase + "consistency-pair"
+	fhirExtPairReversed  = fhirExtensionBase + "consistency-reversed"
+	fhirQuestionnaireURL = "urn:depression-ai:questionnaire:question-bank"
+)
+
+// csvHeader CSV导入导出的列，options、irt_thresholds列内各项以"|"分隔
+var csvHeader = []string{"id", "title", "description", "type", "category", "options", "score", "order_num", "status", "risk_item", "risk_baseline", "irt_discrimination", "irt_thresholds", "consistency_pair_id", "consistency_reversed"}
+
+var (
+	ErrUnsupportedFormat = errors.New("不支持的格式，仅支持json、csv、fhir")
+	ErrInvalidImportFile = errors.New("导入文件格式错误")
+)
+
+// QuestionRecord 题库导入导出的通用记录
+type QuestionRecord struct {
+	ID           uint     `json:"id,omitempty"` // 为空或不存在时新建，存在时更新
+	Title        string   `json:"title"`
+	Description  string   `json:"description,omitempty"`
+	Type         string   `json:"type"`
+	Category     string   `json:"category"`
+	Options      []string `json:"options,omitempty"`
+	Score        int      `json:"score"`
+	OrderNum     int      `json:"order_num"`
+	Status       int      `json:"status"`
+	RiskItem     bool     `json:"risk_item"`
+	RiskBaseline *int     `json:"risk_baseline"` // 导入时不填为1，0表示任何作答均触发
+
+	IRTDiscrimination float64   `json:"irt_discrimination,omitempty"`
+	IRTThresholds     []float64 `json:"irt_thresholds,omitempty"`
+
+	ConsistencyPairID   uint `json:"consistency_pair_id,omitempty"`
+	ConsistencyReversed bool `json:"consistency_reversed,omitempty"`
+
+	row int // 在导入文件中的行号，用于报告错误
+}
+
+// ImportRowError 导入时某一行的校验错误，行号从1开始（CSV包含表头行）
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Message string `json:"message"`
+}
+
+// ImportReport 导入结果
+type ImportReport struct {
+	Total   int              `json:"total"`
+	Created int              `json:"created"`
+	Updated int              `json:"updated"`
+	Errors  []ImportRowError `json:"errors,omitempty"`
+}
+
+// Export 导出题库（不含已删除的问题）
+func (s *QuestionBankService) Export(format string, w io.Writer) error {
+	questions, err := s.List(QuestionFilter{})
+	if err != nil {
+		return err
+	}
+	records := make([]QuestionRecord, 0, len(questions))
+	for _, question := range questions {
+		records = append(records, toQuestionRecord(question))
+	}
+
+	switch format {
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(records)
+	case FormatCSV:
+		return writeQuestionCSV(w, records)
+	case FormatFHIR:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(toFHIRQuestionnaire(records))
+	default:
+		return ErrUnsupportedFormat
+	}
+}
+
+// Import 导入题库：全部行校验通过后才在同一事务内写入，否则返回逐行错误且不做任何修改
+func (s *QuestionBankService) Import(actx AuditContext, format string, r io.Reader) (*ImportReport, error) {
+	var records []QuestionRecord
+	var rowErrors []ImportRowError
+	var err error
+
+	switch format {
+	case FormatJSON:
+		records, rowErrors, err = readQuestionJSON(r)
+	case FormatCSV:
+		records, rowErrors, err = readQuestionCSV(r)
+	case FormatFHIR:
+		records, rowErrors, err = readFHIRQuestionnaire(r)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ImportReport{Total: len(records) + len(rowErrors)}
+	questions := make([]models.Question, 0, len(records))
+	rows := make([]int, 0, len(records))
+	for _, record := range records {
+		question := record.toQuestion()
+		if err := ValidateQuestion(question); err != nil {
+			rowErrors = append(rowErrors, ImportRowError{Row: record.row, Message: err.Error()})
+			continue
+		}
+		questions = append(questions, question)
+		rows = append(rows, record.row)
+	}
+	if len(rowErrors) > 0 {
+		sort.Slice(rowErrors, func(i, j int) bool { return rowErrors[i].Row < rowErrors[j].Row })
+		report.Errors = rowErrors
+		return report, nil
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		for i := range questions {
+			question := &questions[i]
+			var existing models.Question
+			if question.ID != 0 {
+				err := tx.Unscoped().First(&existing, question.ID).Error
+				if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+					return err
+				}
+				if err == nil {
+					if err := tx.Unscoped().Model(&existing).Updates(map[string]interface{}{
+						"title":         question.Title,
+						"description":   question.Description,
+						"type":          question.Type,
+						"category":      question.Category,
+						"options":       question.Options,
+						"score":         question.Score,
+						"order_num":     question.OrderNum,
+						"status":        question.Status,
+						"risk_item":     question.RiskItem,
+						"risk_baseline": question.RiskBaseline,
+
+						"irt_discrimination": question.IRTDiscrimination,
+						"irt_thresholds":     question.IRTThresholds,
+
+						"consistency_pair_id":  question.ConsistencyPairID,
+						"consistency_reversed": question.ConsistencyReversed,
+						"deleted_at":           nil,
+					}).Error; err != nil {
+						return fmt.Errorf("第%d行写入失败: %v", rows[i], err)
+					}
+					report.Updated++
+					continue
+				}
+			}
+
+			// 新建时保留导入文件中的状态（BeforeCreate会把0改为1）
+			status := question.Status
+			if err := tx.Create(question).Error; err != nil {
+				return fmt.Errorf("第%d行写入失败: %v", rows[i], err)
+			}
+			if status == 0 {
+				if err := tx.Model(question).Update("status", 0).Error; err != nil {
+					return err
+				}
+			}
+			report.Created++
+		}
+
+		// 配对题目可能在同一文件中新建，全部写入后再检查
+		for i, question := range questions {
+			if err := s.checkPair(tx, question); err != nil {
+				return fmt.Errorf("第%d行: %w", rows[i], err)
+			}
+		}
+
+		summary := map[string]interface{}{"format": format, "created": report.Created, "updated": report.Updated}
+		if err := RecordAudit(tx, actx, "import", auditResourceQuestion, 0, nil, summary); err != nil {
+			return err
+		}
+		return s.publish(tx, actx, fmt.Sprintf("导入题库（新增%d，更新%d）", report.Created, report.Updated))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// toQuestionRecord 转换为导出记录
+func toQuestionRecord(question models.Question) QuestionRecord {
+	var options []string
+	if question.Options != "" {
+		_ = json.Unmarshal([]byte(question.Options), &options)
+	}
+	var thresholds []float64
+	if question.IRTThresholds != "" {
+		_ = json.Unmarshal([]byte(question.IRTThresholds), &thresholds)
+	}
+	baseline := question.RiskBaseline
+	return QuestionRecord{
+		ID:           question.ID,
+		Title:        question.Title,
+		Description:  question.Description,
+		Type:         question.Type,
+		Category:     question.Category,
+		Options:      options,
+		Score:        question.Score,
+		OrderNum:     question.OrderNum,
+		Status:       question.Status,
+		RiskItem:     question.RiskItem,
+		RiskBaseline: &baseline,
+
+		IRTDiscrimination: question.IRTDiscrimination,
+		IRTThresholds:     thresholds,
+
+		ConsistencyPairID:   question.ConsistencyPairID,
+		ConsistencyReversed: question.ConsistencyReversed,
+	}
+}
+
+// riskBaseline 风险基线，未填时为默认值
+func (r QuestionRecord) riskBaseline() int {
+	if r.RiskBaseline == nil {
+		return models.DefaultRiskBaseline
+	}
+	return *r.RiskBaseline
+}
+
+// toQuestion 转换为问题模型
+func (r QuestionRecord) toQuestion() models.Question {
+	options := ""
+	if len(r.Options) > 0 {
+		data, _ := json.Marshal(r.Options)
+		options = string(data)
+	}
+	thresholds := ""
+	if len(r.IRTThresholds) > 0 {
+		data, _ := json.Marshal(r.IRTThresholds)
+		thresholds = string(data)
+	}
+	question := models.Question{
+		Title:        strings.TrimSpace(r.Title),
+		Description:  strings.TrimSpace(r.Description),
+		Type:         strings.TrimSpace(r.Type),
+		Category:     strings.TrimSpace(r.Category),
+		Options:      options,
+		Score:        r.Score,
+		OrderNum:     r.OrderNum,
+		Status:       r.Status,
+		RiskItem:     r.RiskItem,
+		RiskBaseline: r.riskBaseline(),
+
+		IRTDiscrimination: r.IRTDiscrimination,
+		IRTThresholds:     thresholds,
+
+		ConsistencyPairID:   r.ConsistencyPairID,
+		ConsistencyReversed: r.ConsistencyReversed,
+	}
+	question.ID = r.ID
+	return question
+}
+
+// readQuestionJSON 读取JSON数组格式的题库，单条记录解析失败时记为该行的错误
+func readQuestionJSON(r io.Reader) ([]QuestionRecord, []ImportRowError, error) {
+	var items []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&items); err != nil {
+		return nil, nil, fmt.Errorf("%w: 解析JSON失败: %v", ErrInvalidImportFile, err)
+	}
+
+	records := make([]QuestionRecord, 0, len(items))
+	var rowErrors []ImportRowError
+	for i, item := range items {
+		record := QuestionRecord{Status: 1, row: i + 1}
+		decoder := json.NewDecoder(bytes.NewReader(item))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&record); err != nil {
+			rowErrors = append(rowErrors, ImportRowError{Row: i + 1, Message: err.Error()})
+			continue
+		}
+		records = append(records, record)
+	}
+	return records, rowErrors, nil
+}
+
+// writeQuestionCSV 写出CSV格式的题库
+func writeQuestionCSV(w io.Writer, records []QuestionRecord) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, record := range records {
+		row := []string{
+			strconv.FormatUint(uint64(record.ID), 10),
+			record.Title,
+			record.Description,
+			record.Type,
+			record.Category,
+			strings.Join(record.Options, "|"),
+			strconv.Itoa(record.Score),
+			strconv.Itoa(record.OrderNum),
+			strconv.Itoa(record.Status),
+			strconv.FormatBool(record.RiskItem),
+			strconv.Itoa(record.riskBaseline()),
+			strconv.FormatFloat(record.IRTDiscrimination, 'f', -1, 64),
+			joinFloats(record.IRTThresholds),
+			strconv.FormatUint(uint64(record.ConsistencyPairID), 10),
+			strconv.FormatBool(record.ConsistencyReversed),
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// readQuestionCSV 读取CSV格式的题库，表头必须包含title、type、category列
+func readQuestionCSV(r io.Reader) ([]QuestionRecord, []ImportRowError, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: 解析CSV失败: %v", ErrInvalidImportFile, err)
+	}
+	if len(rows) == 0 {
+		return nil, nil, fmt.Errorf("%w: CSV文件为空", ErrInvalidImportFile)
+	}
+
+	columns := map[string]int{}
+	for i, name := range rows[0] {
+		columns[strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))] = i
+	}
+	for _, required := range []string{"title", "type", "category"} {
+		if _, ok := columns[required]; !ok {
+			return nil, nil, fmt.Errorf("%w: CSV缺少必需的列%s", ErrInvalidImportFile, required)
+		}
+	}
+
+	var records []QuestionRecord
+	var rowErrors []ImportRowError
+	for i, row := range rows[1:] {
+		rowNum := i + 2
+		get := func(name string) string {
+			if idx, ok := columns[name]; ok && idx < len(row) {
+				return strings.TrimSpace(row[idx])
+			}
+			return ""
+		}
+
+		record := QuestionRecord{
+			Title:       get("title"),
+			Description: get("description"),
+			Type:        get("type"),
+			Category:    get("category"),
+			Status:      1,
+			row:         rowNum,
+		}
+		var parseErrors []string
+		if value := get("id"); value != "" && value != "0" {
+			id, err := strconv.ParseUint(value, 10, 32)
+			if err != nil {
+				parseErrors = append(parseErrors, "id不是有效的数字")
+			}
+			record.ID = uint(id)
+		}
+		if value := get("options"); value != "" {
+			for _, option := range strings.Split(value, "|") {
+				record.Options = append(record.Options, strings.TrimSpace(option))
+			}
+		}
+		var baseline int
+		for name, target := range map[string]*int{"score": &record.Score, "order_num": &record.OrderNum, "status": &record.Status, "risk_baseline": &baseline} {
+			if value := get(name); value != "" {
+				number, err := strconv.Atoi(value)
+				if err != nil {
+					parseErrors = append(parseErrors, name+"不是有效的数字")
+					continue
+				}
+				*target = number
+			}
+		}
+		if get("risk_baseline") != "" {
+			record.RiskBaseline = &baseline
+		}
+		if value := get("risk_item"); value != "" {
+			riskItem, err := strconv.ParseBool(value)
+			if err != nil {
+				parseErrors = append(parseErrors, "risk_item必须是true或false")
+			}
+			record.RiskItem = riskItem
+		}
+		if value := get("irt_discrimination"); value != "" {
+			discrimination, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				parseErrors = append(parseErrors, "irt_discrimination不是有效的数字")
+			}
+			record.IRTDiscrimination = discrimination
+		}
+		if value := get("irt_thresholds"); value != "" {
+			for _, part := range strings.Split(value, "|") {
+				threshold, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+				if err != nil {
+					parseErrors = append(parseErrors, "irt_thresholds必须是以|分隔的数字")
+					break
+				}
+				record.IRTThresholds = append(record.IRTThresholds, threshold)
+			}
+		}
+		if value := get("consistency_pair_id"); value != "" && value != "0" {
+			pairID, err := strconv.ParseUint(value, 10, 32)
+			if err != nil {
+				parseErrors = append(parseErrors, "consistency_pair_id不是有效的数字")
+			}
+			record.ConsistencyPairID = uint(pairID)
+		}
+		if value := get("consistency_reversed"); value != "" {
+			reversed, err := strconv.ParseBool(value)
+			if err != nil {
+				parseErrors = append(parseErrors, "consistency_reversed必须是true或false")
+			}
+			record.ConsistencyReversed = reversed
+		}
+
+		if len(parseErrors) > 0 {
+			rowErrors = append(rowErrors, ImportRowError{Row: rowNum, Message: strings.Join(parseErrors, "; ")})
+			continue
+		}
+		records = append(records, record)
+	}
+	return records, rowErrors, nil
+}
+
+// FHIR R4 Questionnaire 资源中用到的结构
+type fhirQuestionnaire struct {
+	ResourceType string     `json:"resourceType"`
+	URL          string     `json:"url,omitempty"`
+	Name         string     `json:"name,omitempty"`
+	Title        string     `json:"title,omitempty"`
+	Status       string     `json:"status"`
+	Date         string     `json:"date,omitempty"`
+	Item         []fhirItem `json:"item"`
+}
+
+type fhirItem struct {
+	LinkID       string             `json:"linkId"`
+	Text         string             `json:"text"`
+	Type         string             `json:"type"`
+	Required     bool               `json:"required,omitempty"`
+	Repeats      bool               `json:"repeats,omitempty"`
+	Code         []fhirCoding       `json:"code,omitempty"`
+	Extension    []fhirExtension    `json:"extension,omitempty"`
+	AnswerOption []fhirAnswerOption `json:"answerOption,omitempty"`
+}
+
+type fhirCoding struct {
+	System  string `json:"system,omitempty"`
+	Code    string `json:"code"`
+	Display string `json:"display,omitempty"`
+}
+
+type fhirAnswerOption struct {
+	ValueCoding fhirCoding      `json:"valueCoding"`
+	Extension   []fhirExtension `json:"extension,omitempty"`
+}
+
+type fhirExtension struct {
+	URL          string   `json:"url"`
+	ValueInteger *int     `json:"valueInteger,omitempty"`
+	ValueDecimal *float64 `json:"valueDecimal,omitempty"`
+	ValueBoolean *bool    `json:"valueBoolean,omitempty"`
+	ValueString  *string  `json:"valueString,omitempty"`
+}
+
+// toFHIRQuestionnaire 将题库转换为FHIR R4 Questionnaire资源，
+// 权重、排序、状态、风险条目等本系统字段以扩展形式保存，选项序号以ordinalValue表示
+func toFHIRQuestionnaire(records []QuestionRecord) fhirQuestionnaire {
+	questionnaire := fhirQuestionnaire{
+		ResourceType: "Questionnaire",
+		URL:          fhirQuestionnaireURL,
+		Name:         "DepressionAIQuestionBank",
+		Title:        "抑郁倾向检测问卷",
+		Status:       "active",
+		Date:         time.Now().Format(time.RFC3339),
+		Item:         make([]fhirItem, 0, len(records)),
+	}
+
+	for _, record := range records {
+		item := fhirItem{
+			LinkID:   fmt.Sprintf("q%d", record.ID),
+			Text:     record.Title,
+			Type:     "choice",
+			Required: true,
+			Code:     []fhirCoding{{System: fhirCategorySystem, Code: record.Category}},
+		}
+		switch record.Type {
+		case models.QuestionTypeMultiple:
+			item.Repeats = true
+		case models.QuestionTypeText:
+			item.Type = "text"
+		}
+
+		score, order, status, baseline, riskItem := record.Score, record.OrderNum, record.Status, record.riskBaseline(), record.RiskItem
+		item.Extension = []fhirExtension{
+			{URL: fhirExtWeight, ValueInteger: &score},
+			{URL: fhirExtOrder, ValueInteger: &order},
+			{URL: fhirExtStatus, ValueInteger: &status},
+			{URL: fhirExtRiskItem, ValueBoolean: &riskItem},
+			{URL: fhirExtRiskBaseline, ValueInteger: &baseline},
+		}
+		if record.Description != "" {
+			description := record.Description
+			item.Extension = append(item.Extension, fhirExtension{URL: fhirExtDescription, ValueString: &description})
+		}
+		if record.IRTDiscrimination > 0 {
+			discrimination := record.IRTDiscrimination
+			data, _ := json.Marshal(record.IRTThresholds)
+			thresholds := string(data)
+			item.Extension = append(item.Extension,
+				fhirExtension{URL: fhirExtIRTA, ValueDecimal: &discrimination},
+				fhirExtension{URL: fhirExtIRTB, ValueString: &thresholds},
+			)
+		}
+		if record.ConsistencyPairID != 0 {
+			pairID, reversed := int(record.ConsistencyPairID), record.ConsistencyReversed
+			item.Extension = append(item.Extension,
+				fhirExtension{URL: fhirExtPair, ValueInteger: &pairID},
+				fhirExtension{URL: fhirExtPairReversed, ValueBoolean: &reversed},
+			)
+		}
+
+		for i, option := range record.Options {
+			ordinal := float64(i + 1)
+			item.AnswerOption = append(item.AnswerOption, fhirAnswerOption{
+				ValueCoding: fhirCoding{Code: strconv.Itoa(i + 1), Display: option},
+				Extension:   []fhirExtension{{URL: fhirOrdinalValueURL, ValueDecimal: &ordinal}},
+			})
+		}
+		questionnaire.Item = append(questionnaire.Item, item)
+	}
+	return questionnaire
+}
+
+// readFHIRQuestionnaire 读取FHIR R4 Questionnaire资源，linkId形如q12时按ID更新对应问题
+func readFHIRQuestionnaire(r io.Reader) ([]QuestionRecord, []ImportRowError, error) {
+	var questionnaire fhirQuestionnaire
+	if err := json.NewDecoder(r).Decode(&questionnaire); err != nil {
+		return nil, nil, fmt.Errorf("%w: 解析FHIR资源失败: %v", ErrInvalidImportFile, err)
+	}
+	if questionnaire.ResourceType != "Questionnaire" {
+		return nil, nil, fmt.Errorf("%w: FHIR资源类型必须是Questionnaire，实际为%s", ErrInvalidImportFile, questionnaire.ResourceType)
+	}
+
+	var records []QuestionRecord
+	var rowErrors []ImportRowError
+	for i, item := range questionnaire.Item {
+		record := QuestionRecord{
+			Title:    item.Text,
+			Status:   1,
+			OrderNum: i + 1,
+			row:      i + 1,
+		}
+		if strings.HasPrefix(item.LinkID, "q") {
+			if id, err := strconv.ParseUint(strings.TrimPrefix(item.LinkID, "q"), 10, 32); err == nil {
+				record.ID = uint(id)
+			}
+		}
+
+		switch item.Type {
+		case "choice", "open-choice":
+			record.Type = models.QuestionTypeSingle
+			if item.Repeats {
+				record.Type = models.QuestionTypeMultiple
+			}
+		case "text", "string":
+			record.Type = models.QuestionTypeText
+		default:
+			rowErrors = append(rowErrors, ImportRowError{Row: i + 1, Message: fmt.Sprintf("不支持的FHIR题目类型: %s", item.Type)})
+			continue
+		}
+
+		for _, code := range item.Code {
+			if code.System == fhirCategorySystem || record.Category == "" {
+				record.Category = code.Code
+			}
+		}
+		invalid := false
+		for _, ext := range item.Extension {
+			switch {
+			case ext.URL == fhirExtWeight && ext.ValueInteger != nil:
+				record.Score = *ext.ValueInteger
+			case ext.URL == fhirExtOrder && ext.ValueInteger != nil:
+				record.OrderNum = *ext.ValueInteger
+			case ext.URL == fhirExtStatus && ext.ValueInteger != nil:
+				record.Status = *ext.ValueInteger
+			case ext.URL == fhirExtRiskItem && ext.ValueBoolean != nil:
+				record.RiskItem = *ext.ValueBoolean
+			case ext.URL == fhirExtRiskBaseline && ext.ValueInteger != nil:
+				baseline := *ext.ValueInteger
+				record.RiskBaseline = &baseline
+			case ext.URL == fhirExtDescription && ext.ValueString != nil:
+				record.Description = *ext.ValueString
+			case ext.URL == fhirExtIRTA && ext.ValueDecimal != nil:
+				record.IRTDiscrimination = *ext.ValueDecimal
+			case ext.URL == fhirExtIRTB && ext.ValueString != nil:
+				if err := json.Unmarshal([]byte(*ext.ValueString), &record.IRTThresholds); err != nil {
+					rowErrors = append(rowErrors, ImportRowError{Row: i + 1, Message: "IRT阈值扩展必须是JSON数字数组"})
+					invalid = true
+				}
+			case ext.URL == fhirExtPair && ext.ValueInteger != nil:
+				if *ext.ValueInteger < 0 {
+					rowErrors = append(rowErrors, ImportRowError{Row: i + 1, Message: "一致性配对扩展必须是题目ID"})
+					invalid = true
+					continue
+				}
+				record.ConsistencyPairID = uint(*ext.ValueInteger)
+			case ext.URL == fhirExtPairReversed && ext.ValueBoolean != nil:
+				record.ConsistencyReversed = *ext.ValueBoolean
+			}
+		}
+		if invalid {
+			continue
+		}
+		for _, option := range item.AnswerOption {
+			display := option.ValueCoding.Display
+			if display == "" {
+				display = option.ValueCoding.Code
+			}
+			record.Options = append(record.Options, display)
+		}
+		records = append(records, record)
+	}
+	return records, rowErrors, nil
+}
+
+// joinFloats 以"|"连接数字，用于CSV
+func joinFloats(values []float64) string {
+	parts := make([]string, 0, len(values))
+	for _, value := range values {
+		parts = append(parts, strconv.FormatFloat(value, 'f', -1, 64))
+	}
+	return strings.Join(parts, "|")
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"time"
+
+	"depression_go/internal/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidOrderMode  = errors.New("不支持的题目顺序，仅支持fixed、random、block、counterbalanced")
+	ErrAdaptiveOrderMode = errors.New("自适应测验由服务端选题，不支持指定题目顺序")
+	ErrOrderSeedRequired = errors.New("按随机顺序作答时必须提供order_seed")
+)
+
+// ValidOrderMode 判断题目顺序是否受支持，空值视为fixed
+func ValidOrderMode(mode string) bool {
+	switch mode {
+	case "", models.QuestionOrderFixed, models.QuestionOrderRandom, models.QuestionOrderBlock, models.QuestionOrderCounterbalanced:
+		return true
+	}
+	return false
+}
+
+// NewOrderSeed 生成新的随机种子
+func NewOrderSeed() int64 {
+	return time.Now().UnixNano()
+}
+
+// OrderQuestions 按题目顺序和种子排列题目，相同的题目、顺序和种子总是得到相同的结果。
+// 分块按分类首次出现的顺序划分；counterbalanced的块顺序按种子对块数取模轮换
+func OrderQuestions(questions []models.Question, mode string, seed int64) []models.Question {
+	ordered := make([]models.Question, len(questions))
+	copy(ordered, questions)
+	if mode == "" || mode == models.QuestionOrderFixed || len(ordered) < 2 {
+		return ordered
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	if mode == models.QuestionOrderRandom {
+		rng.Shuffle(len(ordered), func(i, j int) { ordered[i], ordered[j] = ordered[j], ordered[i] })
+		return ordered
+	}
+
+	var categories []string
+	blocks := map[string][]models.Question{}
+	for _, question := range ordered {
+		if _, ok := blocks[question.Category]; !ok {
+			categories = append(categories, question.Category)
+		}
+		blocks[question.Category] = append(blocks[question.Category], question)
+	}
+	if mode == models.QuestionOrderCounterbalanced {
+		rotation := int(uint64(seed) % uint64(len(categories)))
+		categories = append(categories[rotation:], categories[:rotation]...)
+	}
+
+	ordered = ordered[:0]
+	for _, category := range categories {
+		block := blocks[category]
+		rng.Shuffle(len(block), func(i, j int) { block[i], block[j] = block[j], block[i] })
+		ordered = append(ordered, block...)
+	}
+	return ordered
+}
+
+// counterbalancedSeed 为counterbalanced生成种子：块的轮换按已有评估数量依次循环，
+// 使各种块顺序在评估之间均衡出现，块内顺序仍随机
+func counterbalancedSeed(tx *gorm.DB, versionID uint, blocks int) (int64, error) {
+	var count int64
+	if err := tx.Model(&models.Assessment{}).
+		Where("questionnaire_version_id = ? AND order_mode = ?", versionID, models.QuestionOrderCounterbalanced).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	seed := NewOrderSeed()
+	if seed < 0 {
+		seed = -seed
+	}
+	if blocks > 1 {
+		seed = seed - seed%int64(blocks) + count%int64(blocks)
+	}
+	return seed, nil
+}
+
+// countBlocks 题目按分类划分的块数
+func countBlocks(questions []models.Question) int {
+	categories := map[string]bool{}
+	for _, question := range questions {
+		categories[question.Category] = true
+	}
+	return len(categories)
+}
+
+// questionOrder 解析评估记录的题目顺序
+func questionOrder(assessment *models.Assessment) []uint {
+	var order []uint
+	if assessment.QuestionOrder != "" {
+		_ = json.Unmarshal([]byte(assessment.QuestionOrder), &order)
+	}
+	return order
+}
+
+// orderPosition 题目在评估题目顺序中的位置（从1开始），不在其中时返回0
+func orderPosition(order []uint, questionID uint) int {
+	for i, id := range order {
+		if id == questionID {
+			return i + 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"depression_go/internal/models"
+)
+
+// orderTestQuestions 三个分类各三道题，ID从1开始
+func orderTestQuestions() []models.Question {
+	categories := []string{"情绪", "睡眠", "精力"}
+	var questions []models.Question
+	for _, category := range categories {
+		for i := 0; i < 3; i++ {
+			question := models.Question{Category: category}
+			question.ID = uint(len(questions) + 1)
+			questions = append(questions, question)
+		}
+	}
+	return questions
+}
+
+func questionIDs(questions []models.Question) []uint {
+	ids := make([]uint, len(questions))
+	for i, question := range questions {
+		ids[i] = question.ID
+	}
+	return ids
+}
+
+// categoryRuns 按出现顺序列出连续的分类块
+func categoryRuns(questions []models.Question) []string {
+	var runs []string
+	for _, question := range questions {
+		if len(runs) == 0 || runs[len(runs)-1] != question.Category {
+			runs = append(runs, question.Category)
+		}
+	}
+	return runs
+}
+
+func TestOrderQuestionsDeterministic(t *testing.T) {
+	modes := []string{"", models.QuestionOrderFixed, models.QuestionOrderRandom, models.QuestionOrderBlock, models.QuestionOrderCounterbalanced}
+	seeds := []int64{0, 1, 42, 1700000000000000000}
+	for _, mode := range modes {
+		for _, seed := range seeds {
+			questions := orderTestQuestions()
+			original := questionIDs(questions)
+			first := questionIDs(OrderQuestions(questions, mode, seed))
+			second := questionIDs(OrderQuestions(orderTestQuestions(), mode, seed))
+			if !reflect.DeepEqual(first, second) {
+				t.Errorf("mode=%q seed=%d: 两次结果不同 %v / %v", mode, seed, first, second)
+			}
+			if !reflect.DeepEqual(questionIDs(questions), original) {
+				t.Errorf("mode=%q seed=%d: 修改了传入的切片", mode, seed)
+			}
+			if len(first) != len(original) {
+				t.Errorf("mode=%q seed=%d: 题数 %d, want %d", mode, seed, len(first), len(original))
+			}
+		}
+	}
+}
+
+func TestOrderQuestionsModes(t *testing.T) {
+	input := orderTestQuestions()
+	tests := []struct {
+		name     string
+		mode     string
+		seed     int64
+		wantRuns []string
+	}{
+		{"fixed保持原顺序", models.QuestionOrderFixed, 7, []string{"情绪", "睡眠", "精力"}},
+		{"block块顺序不变", models.QuestionOrderBlock, 7, []string{"情绪", "睡眠", "精力"}},
+		{"counterbalanced种子为0不轮换", models.QuestionOrderCounterbalanced, 0, []string{"情绪", "睡眠", "精力"}},
+		{"counterbalanced种子为1轮换一块", models.QuestionOrderCounterbalanced, 1, []string{"睡眠", "精力", "情绪"}},
+		{"counterbalanced种子对块数取模", models.QuestionOrderCounterbalanced, 5, []string{"精力", "情绪", "睡眠"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := OrderQuestions(input, tt.mode, tt.seed)
+			if runs := categoryRuns(got); !reflect.DeepEqual(runs, tt.wantRuns) {
+				t.Errorf("分类块 = %v, want %v", runs, tt.wantRuns)
+			}
+		})
+	}
+
+	if got := questionIDs(OrderQuestions(input, models.QuestionOrderFixed, 7)); !reflect.DeepEqual(got, questionIDs(input)) {
+		t.Errorf("fixed顺序 = %v, want %v", got, questionIDs(input))
+	}
+}
+
+func TestOrderQuestionsRandomVariesBySeed(t *testing.T) {
+	input := orderTestQuestions()
+	first := questionIDs(OrderQuestions(input, models.QuestionOrderRandom, 1))
+	for seed := int64(2); seed < 10; seed++ {
+		if !reflect.DeepEqual(questionIDs(OrderQuestions(input, models.QuestionOrderRandom, seed)), first) {
+			return
+		}
+	}
+	t.Errorf("不同的种子总是得到相同的顺序 %v", first)
+}
@@ -0,0 +1,324 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"depression_go/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrVersionNotFound      = errors.New("问卷版本不存在")
+	ErrQuestionNotInVersion = errors.New("问题不属于该评估的问卷版本")
+)
+
+// VersionedQuestion 问卷版本中的一道题：修订内容还原的问题及排序
+type VersionedQuestion struct {
+	Question   models.Question
+	RevisionID uint
+	OrderNum   int
+}
+
+// QuestionnaireVersionService 问卷版本服务：维护问题修订并发布不可变的问卷版本
+type QuestionnaireVersionService struct {
+	db *gorm.DB
+}
+
+// NewQuestionnaireVersionService 创建问卷版本服务
+func NewQuestionnaireVersionService(db *gorm.DB) *QuestionnaireVersionService {
+	return &QuestionnaireVersionService{db: db}
+}
+
+// ensureRevision 确保问题当前内容有对应的修订，内容变化时生成新修订
+func (s *QuestionnaireVersionService) ensureRevision(tx *gorm.DB, question *models.Question) (*models.QuestionRevision, error) {
+	var latest models.QuestionRevision
+	err := tx.Where("question_id = ?", question.ID).Order("revision DESC").First(&latest).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if err == nil && latest.SameContent(*question) {
+		if question.CurrentRevisionID != latest.ID {
+			if err := tx.Model(question).UpdateColumn("current_revision_id", latest.ID).Error; err != nil {
+				return nil, err
+			}
+			question.CurrentRevisionID = latest.ID
+		}
+		return &latest, nil
+	}
+
+	revision := models.NewQuestionRevision(*question)
+	revision.Revision = latest.Revision + 1
+	if err := tx.Create(&revision).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Model(question).UpdateColumn("current_revision_id", revision.ID).Error; err != nil {
+		return nil, err
+	}
+	question.CurrentRevisionID = revision.ID
+	return &revision, nil
+}
+
+// Publish 以题库当前启用的问题（不含每日打卡题目）发布新版本；与最新版本内容完全一致时直接返回最新版本
+func (s *QuestionnaireVersionService) Publish(tx *gorm.DB, actorID uint, note string) (*models.QuestionnaireVersion, error) {
+	// 并发发布依次进行，之后按最新提交的数据计算版本号
+	if err := lockPublish(tx, models.PublishLockQuestionnaire); err != nil {
+		return nil, err
+	}
+
+	var questions []models.Question
+	if err := tx.Where("status = ? AND category <> ?", 1, models.QuestionCategoryCheckin).Order("order_num ASC, id ASC").Find(&questions).Error; err != nil {
+		return nil, err
+	}
+
+	items := make([]models.QuestionnaireVersionItem, 0, len(questions))
+	for i := range questions {
+		revision, err := s.ensureRevision(tx, &questions[i])
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, models.QuestionnaireVersionItem{
+			QuestionID: questions[i].ID,
+			RevisionID: revision.ID,
+			OrderNum:   questions[i].OrderNum,
+		})
+	}
+
+	latest, err := s.latest(tx.Clauses(clause.Locking{Strength: "UPDATE"}))
+	if err != nil {
+		return nil, err
+	}
+	if latest != nil {
+		var latestItems []models.QuestionnaireVersionItem
+		if err := tx.Where("version_id = ?", latest.ID).Order("order_num ASC, question_id ASC").Find(&latestItems).Error; err != nil {
+			return nil, err
+		}
+		if sameItems(latestItems, items) {
+			return latest, nil
+		}
+	}
+
+	version := models.QuestionnaireVersion{
+		Version:   1,
+		Note:      note,
+		CreatedBy: actorID,
+	}
+	if latest != nil {
+		version.Version = latest.Version + 1
+	}
+	if err := tx.Create(&version).Error; err != nil {
+		return nil, err
+	}
+	for i := range items {
+		items[i].VersionID = version.ID
+	}
+	if len(items) > 0 {
+		if err := tx.Create(&items).Error; err != nil {
+			return nil, err
+		}
+	}
+	version.Items = items
+	return &version, nil
+}
+
+// lockPublish 在事务内锁定发布锁行（不存在时先创建），同名的发布在事务提交前依次等待
+func lockPublish(tx *gorm.DB, name string) error {
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.PublishLock{Name: name}).Error; err != nil {
+		return err
+	}
+	var lock models.PublishLock
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("name = ?", name).First(&lock).Error
+}
+
+// Current 获取当前问卷版本，尚无版本时以题库现状发布初始版本
+func (s *QuestionnaireVersionService) Current(tx *gorm.DB) (*models.QuestionnaireVersion, error) {
+	latest, err := s.latest(tx)
+	if err != nil {
+		return nil, err
+	}
+	if latest != nil {
+		return latest, nil
+	}
+	return s.Publish(tx, 0, "初始版本")
+}
+
+// latest 获取最新版本，没有任何版本时返回nil
+func (s *QuestionnaireVersionService) latest(tx *gorm.DB) (*models.QuestionnaireVersion, error) {
+	var version models.QuestionnaireVersion
+	err := tx.Order("version DESC").First(&version).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &version, nil
+}
+
+// List 列出所有问卷版本（不含题目）
+func (s *QuestionnaireVersionService) List() ([]models.QuestionnaireVersion, error) {
+	var versions []models.QuestionnaireVersion
+	err := s.db.Order("version DESC").Find(&versions).Error
+	return versions, err
+}
+
+// Get 获取指定版本
+func (s *QuestionnaireVersionService) Get(versionID uint) (*models.QuestionnaireVersion, error) {
+	var version models.QuestionnaireVersion
+	if err := s.db.First(&version, versionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrVersionNotFound
+		}
+		return nil, err
+	}
+	return &version, nil
+}
+
+// Questions 按版本中保存的修订还原该版本的全部题目
+func (s *QuestionnaireVersionService) Questions(tx *gorm.DB, versionID uint) ([]VersionedQuestion, error) {
+	var items []models.QuestionnaireVersionItem
+	if err := tx.Preload("Revision").
+		Where("version_id = ?", versionID).
+		Order("order_num ASC, question_id ASC").
+		Find(&items).Error; err != nil {
+		return nil, err
+	}
+
+	questions := make([]VersionedQuestion, 0, len(items))
+	for _, item := range items {
+		questions = append(questions, VersionedQuestion{
+			Question:   item.Revision.AsQuestion(),
+			RevisionID: item.RevisionID,
+			OrderNum:   item.OrderNum,
+		})
+	}
+	return questions, nil
+}
+
+// ResolveQuestion 获取某道题在指定版本中的内容；versionID为0（版本化之前的评估）时使用题库当前内容
+func (s *QuestionnaireVersionService) ResolveQuestion(tx *gorm.DB, versionID, questionID uint) (models.Question, uint, error) {
+	if versionID == 0 {
+		var question models.Question
+		if err := tx.Where("id = ?", questionID).First(&question).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return models.Question{}, 0, fmt.Errorf("%w: %d", ErrQuestionNotFound, questionID)
+			}
+			return models.Question{}, 0, err
+		}
+		return question, question.CurrentRevisionID, nil
+	}
+
+	var item models.QuestionnaireVersionItem
+	err := tx.Preload("Revision").Where("version_id = ? AND question_id = ?", versionID, questionID).First(&item).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.Question{}, 0, fmt.Errorf("%w: %d", ErrQuestionNotInVersion, questionID)
+	}
+	if err != nil {
+		return models.Question{}, 0, err
+	}
+	return item.Revision.AsQuestion(), item.RevisionID, nil
+}
+
+// RenderAnswers 按作答时的修订渲染答案；旧答案没有修订记录时回退到题库中的问题（含已删除）
+func (s *QuestionnaireVersionService) RenderAnswers(tx *gorm.DB, answers []models.Answer) ([]models.AnswerWithQuestion, error) {
+	questions, err := s.AnsweredQuestions(tx, answers)
+	if err != nil {
+		return nil, err
+	}
+
+	rendered := make([]models.AnswerWithQuestion, 0, len(answers))
+	for i, answer := range answers {
+		question := questions[i]
+		rendered = append(rendered, models.AnswerWithQuestion{
+			ID:           answer.ID,
+			UserID:       answer.UserID,
+			QuestionID:   answer.QuestionID,
+			AssessmentID: answer.AssessmentID,
+			Content:      answer.Content,
+			Score:        answer.Score,
+			AnswerValue:  answer.AnswerValue,
+			CreatedAt:    answer.CreatedAt,
+			UpdatedAt:    answer.UpdatedAt,
+
+			ResponseTimeMs: answer.ResponseTimeMs,
+			Position:       answer.Position,
+			Question: models.PublicQuestionResponse{
+				ID:           question.ID,
+				Title:        question.Title,
+				Description:  question.Description,
+				Type:         question.Type,
+				Category:     question.Category,
+				Options:      question.Options,
+				Score:        question.Score,
+				OrderNum:     question.OrderNum,
+				Status:       question.Status,
+				RiskItem:     question.RiskItem,
+				RiskBaseline: question.RiskBaseline,
+				CreatedAt:    question.CreatedAt,
+				UpdatedAt:    question.UpdatedAt,
+			},
+		})
+	}
+	return rendered, nil
+}
+
+// AnsweredQuestions 按作答时的修订取每个答案对应的题目，与answers一一对应；
+// 旧答案没有修订记录时回退到题库中的问题（含已删除）
+func (s *QuestionnaireVersionService) AnsweredQuestions(tx *gorm.DB, answers []models.Answer) ([]models.Question, error) {
+	revisionIDs := make([]uint, 0, len(answers))
+	questionIDs := make([]uint, 0, len(answers))
+	for _, answer := range answers {
+		if answer.QuestionRevisionID != 0 {
+			revisionIDs = append(revisionIDs, answer.QuestionRevisionID)
+		} else {
+			questionIDs = append(questionIDs, answer.QuestionID)
+		}
+	}
+
+	revisions := map[uint]models.QuestionRevision{}
+	if len(revisionIDs) > 0 {
+		var list []models.QuestionRevision
+		if err := tx.Where("id IN ?", revisionIDs).Find(&list).Error; err != nil {
+			return nil, err
+		}
+		for _, revision := range list {
+			revisions[revision.ID] = revision
+		}
+	}
+
+	live := map[uint]models.Question{}
+	if len(questionIDs) > 0 {
+		var list []models.Question
+		if err := tx.Unscoped().Where("id IN ?", questionIDs).Find(&list).Error; err != nil {
+			return nil, err
+		}
+		for _, question := range list {
+			live[question.ID] = question
+		}
+	}
+
+	questions := make([]models.Question, len(answers))
+	for i, answer := range answers {
+		if revision, ok := revisions[answer.QuestionRevisionID]; ok {
+			questions[i] = revision.AsQuestion()
+		} else {
+			questions[i] = live[answer.QuestionID]
+		}
+	}
+	return questions, nil
+}
+
+// sameItems 判断两个版本的题目和修订是否完全一致
+func sameItems(a, b []models.QuestionnaireVersionItem) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].QuestionID != b[i].QuestionID || a[i].RevisionID != b[i].RevisionID || a[i].OrderNum != b[i].OrderNum {
+			return false
+		}
+	}
+	return true
+}
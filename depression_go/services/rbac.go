@@ -0,0 +1,151 @@
+package services
+
+import (
+	"errors"
+
+	"depression_go/internal/models"
+
+	"gorm.io/gorm"
+)
+
+var ErrRoleNotFound = errors.New("角色不存在")
+
+// defaultPermissions 内置权限
+var defaultPermissions = []models.Permission{
+	{Code: models.PermQuestionManage, Description: "管理题库、问卷版本、翻译和结果文本"},
+	{Code: models.PermScoringManage, Description: "管理计分方案"},
+	{Code: models.PermAssessmentRescore, Description: "重新计分"},
+	{Code: models.PermUserRead, Description: "查看用户信息"},
+	{Code: models.PermUserManage, Description: "修改用户角色和状态"},
+	{Code: models.PermTrendRead, Description: "查看用户的纵向趋势"},
+	{Code: models.PermAuditRead, Description: "查看审计日志"},
+	{Code: models.PermRiskManage, Description: "查看和处理风险事件"},
+}
+
+// defaultRoles 内置角色及其初始权限；admin始终拥有全部权限
+var defaultRoles = []struct {
+	name        string
+	description string
+	permissions []string
+}{
+	{models.RoleUser, "普通用户", nil},
+	{models.RoleClinician, "咨询师", []string{models.PermUserRead, models.PermTrendRead, models.PermRiskManage}},
+	{models.RoleAdmin, "管理员", nil},
+}
+
+// RBACService 基于角色的权限服务：角色和权限保存在数据库中
+type RBACService struct {
+	db *gorm.DB
+}
+
+// NewRBACService 创建权限服务
+func NewRBACService(db *gorm.DB) *RBACService {
+	return &RBACService{db: db}
+}
+
+// EnsureDefaults 补齐内置的权限和角色，新建的角色授予初始权限，新增的内置权限授予其内置角色，
+// admin补齐全部权限；已有角色的其他权限不做修改
+func (s *RBACService) EnsureDefaults() error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var permissions []models.Permission
+		added := map[string]bool{}
+		for _, def := range defaultPermissions {
+			permission := def
+			result := tx.Where("code = ?", permission.Code).
+				Attrs(models.Permission{Description: permission.Description}).
+				FirstOrCreate(&permission)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected > 0 {
+				added[permission.Code] = true
+			}
+			permissions = append(permissions, permission)
+		}
+		byCode := make(map[string]models.Permission, len(permissions))
+		for _, permission := range permissions {
+			byCode[permission.Code] = permission
+		}
+
+		for _, def := range defaultRoles {
+			var role models.Role
+			err := tx.Where("name = ?", def.name).First(&role).Error
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			created := errors.Is(err, gorm.ErrRecordNotFound)
+			if created {
+				role = models.Role{Name: def.name, Description: def.description}
+				if err := tx.Create(&role).Error; err != nil {
+					return err
+				}
+			}
+
+			var grant []models.Permission
+			switch {
+			case def.name == models.RoleAdmin:
+				grant = permissions
+			default:
+				for _, code := range def.permissions {
+					if created || added[code] {
+						grant = append(grant, byCode[code])
+					}
+				}
+			}
+			if len(grant) > 0 {
+				if err := tx.Model(&role).Association("Permissions").Append(grant); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// HasPermissions 判断角色是否拥有全部给定权限
+func (s *RBACService) HasPermissions(role string, codes ...string) (bool, error) {
+	if len(codes) == 0 {
+		return true, nil
+	}
+	var count int64
+	err := s.db.Table("role_permissions").
+		Joins("JOIN roles ON roles.id = role_permissions.role_id AND roles.deleted_at IS NULL").
+		Joins("JOIN permissions ON permissions.id = role_permissions.permission_id AND permissions.deleted_at IS NULL").
+		Where("roles.name = ? AND permissions.code IN ?", role, codes).
+		Distinct("permissions.code").
+		Count(&count).Error
+	return count == int64(len(codes)), err
+}
+
+// Roles 列出所有角色及其权限
+func (s *RBACService) Roles() ([]models.RoleResponse, error) {
+	var roles []models.Role
+	if err := s.db.Preload("Permissions").Order("id ASC").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	result := make([]models.RoleResponse, 0, len(roles))
+	for _, role := range roles {
+		item := models.RoleResponse{Name: role.Name, Description: role.Description, RequireMFA: role.RequireMFA, Permissions: []string{}}
+		for _, permission := range role.Permissions {
+			item.Permissions = append(item.Permissions, permission.Code)
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+// RequiresMFA 角色是否要求两步验证
+func (s *RBACService) RequiresMFA(role string) (bool, error) {
+	var roles []models.Role
+	if err := s.db.Select("require_mfa").Where("name = ?", role).Limit(1).Find(&roles).Error; err != nil {
+		return false, err
+	}
+	return len(roles) > 0 && roles[0].RequireMFA, nil
+}
+
+// roleExists 判断角色是否存在
+func (s *RBACService) roleExists(tx *gorm.DB, name string) (bool, error) {
+	var count int64
+	err := tx.Model(&models.Role{}).Where("name = ?", name).Count(&count).Error
+	return count > 0, err
+}
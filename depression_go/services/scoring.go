@@ -0,0 +1,262 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"depression_go/internal/models"
+	"depression_go/pkg/i18n"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// auditResourceScoringProfile 审计日志中的计分方案资源类型
+const auditResourceScoringProfile = "scoring_profile"
+
+var (
+	ErrScoringProfileNotFound = errors.New("计分方案不存在")
+	ErrInvalidScoringProfile  = errors.New("计分方案不合法")
+)
+
+// defaultScoreBands 初始计分方案，与原先按100分满分划分的等级一致
+var defaultScoreBands = []models.ScoreBand{
+	{Level: "normal", MinPercentage: 0},
+	{Level: "mild", MinPercentage: 40},
+	{Level: "moderate", MinPercentage: 60},
+	{Level: "severe", MinPercentage: 80},
+}
+
+// ScoringService 计分方案服务：等级划分及各等级的描述和建议，修改时发布新版本
+type ScoringService struct {
+	db *gorm.DB
+}
+
+// NewScoringService 创建计分方案服务
+func NewScoringService(db *gorm.DB) *ScoringService {
+	return &ScoringService{db: db}
+}
+
+// Current 获取当前生效的计分方案，尚无方案时发布初始方案
+func (s *ScoringService) Current(tx *gorm.DB) (*models.ScoringProfile, error) {
+	latest, err := s.latest(tx)
+	if err != nil || latest != nil {
+		return latest, err
+	}
+	return s.initial(tx)
+}
+
+// initial 发布初始方案；并发请求依次进行，已被其他请求发布时返回该方案
+func (s *ScoringService) initial(db *gorm.DB) (*models.ScoringProfile, error) {
+	var profile *models.ScoringProfile
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := lockPublish(tx, models.PublishLockScoringProfile); err != nil {
+			return err
+		}
+		latest, err := s.latest(tx.Clauses(clause.Locking{Strength: "UPDATE"}))
+		if err != nil || latest != nil {
+			profile = latest
+			return err
+		}
+
+		bands, err := json.Marshal(defaultScoreBands)
+		if err != nil {
+			return err
+		}
+		profile = &models.ScoringProfile{Version: 1, Bands: string(bands), Note: "初始方案"}
+		return tx.Create(profile).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// latest 获取最新的计分方案，没有任何方案时返回nil
+func (s *ScoringService) latest(tx *gorm.DB) (*models.ScoringProfile, error) {
+	var profile models.ScoringProfile
+	err := tx.Order("version DESC").First(&profile).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// List 列出所有计分方案版本
+func (s *ScoringService) List() ([]models.ScoringProfile, error) {
+	var profiles []models.ScoringProfile
+	err := s.db.Order("version DESC").Find(&profiles).Error
+	return profiles, err
+}
+
+// Get 获取指定的计分方案
+func (s *ScoringService) Get(tx *gorm.DB, id uint) (*models.ScoringProfile, error) {
+	var profile models.ScoringProfile
+	if err := tx.First(&profile, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrScoringProfileNotFound
+		}
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// Publish 发布新的计分方案，之后完成的评估按新方案划分等级（已完成的评估需重新计分才会更新）
+func (s *ScoringService) Publish(actx AuditContext, req models.ScoringProfileRequest) (*models.ScoringProfile, error) {
+	if err := ValidateScoreBands(req.Bands); err != nil {
+		return nil, err
+	}
+	if err := validateChangeParams(req); err != nil {
+		return nil, err
+	}
+	bands, err := json.Marshal(req.Bands)
+	if err != nil {
+		return nil, err
+	}
+
+	var profile models.ScoringProfile
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// 并发发布依次进行，之后按最新提交的数据计算版本号
+		if err := lockPublish(tx, models.PublishLockScoringProfile); err != nil {
+			return err
+		}
+		before, err := s.latest(tx.Clauses(clause.Locking{Strength: "UPDATE"}))
+		if err != nil {
+			return err
+		}
+		if before == nil {
+			if before, err = s.initial(tx); err != nil {
+				return err
+			}
+		}
+		profile = models.ScoringProfile{
+			Version:   before.Version + 1,
+			Bands:     string(bands),
+			Note:      strings.TrimSpace(req.Note),
+			CreatedBy: actx.ActorID,
+
+			NormSD:         req.NormSD,
+			Reliability:    req.Reliability,
+			ClinicalCutoff: req.ClinicalCutoff,
+		}
+		if err := tx.Create(&profile).Error; err != nil {
+			return err
+		}
+		return RecordAudit(tx, actx, "publish", auditResourceScoringProfile, profile.ID, before, profile)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// ValidateScoreBands 校验等级划分：第一个等级从0开始，下限严格递增且不超过100，等级不重复；
+// 没有内置文本的等级必须给出默认语言的描述
+func ValidateScoreBands(bands []models.ScoreBand) error {
+	if len(bands) == 0 {
+		return fmt.Errorf("%w: 至少需要一个等级", ErrInvalidScoringProfile)
+	}
+	if bands[0].MinPercentage != 0 {
+		return fmt.Errorf("%w: 第一个等级的下限必须为0", ErrInvalidScoringProfile)
+	}
+
+	levels := map[string]bool{}
+	for i, band := range bands {
+		if strings.TrimSpace(band.Level) == "" {
+			return fmt.Errorf("%w: 第%d个等级的名称不能为空", ErrInvalidScoringProfile, i+1)
+		}
+		if levels[band.Level] {
+			return fmt.Errorf("%w: 等级%s重复", ErrInvalidScoringProfile, band.Level)
+		}
+		levels[band.Level] = true
+		if band.MinPercentage < 0 || band.MinPercentage > 100 {
+			return fmt.Errorf("%w: 等级%s的下限必须在0-100之间", ErrInvalidScoringProfile, band.Level)
+		}
+		if i > 0 && band.MinPercentage <= bands[i-1].MinPercentage {
+			return fmt.Errorf("%w: 等级下限必须递增", ErrInvalidScoringProfile)
+		}
+		for locale := range band.Texts {
+			if i18n.Normalize(locale) != locale {
+				return fmt.Errorf("%w: 等级%s的文本使用了不支持的语言%s", ErrInvalidScoringProfile, band.Level, locale)
+			}
+		}
+		if !i18n.HasKey("assessment."+band.Level+".description") && band.Texts[i18n.Default].Description == "" {
+			return fmt.Errorf("%w: 等级%s没有内置文本，必须提供%s的描述", ErrInvalidScoringProfile, band.Level, i18n.Default)
+		}
+	}
+	return nil
+}
+
+// validateChangeParams 校验可靠变化指数的量表参数，0表示使用默认值
+func validateChangeParams(req models.ScoringProfileRequest) error {
+	if req.NormSD < 0 || req.NormSD > 100 {
+		return fmt.Errorf("%w: 常模标准差必须在0-100之间", ErrInvalidScoringProfile)
+	}
+	if req.Reliability < 0 || req.Reliability >= 1 {
+		return fmt.Errorf("%w: 信度必须在0-1之间（不含1）", ErrInvalidScoringProfile)
+	}
+	if req.ClinicalCutoff < 0 || req.ClinicalCutoff > 100 {
+		return fmt.Errorf("%w: 临床界值必须在0-100之间", ErrInvalidScoringProfile)
+	}
+	return nil
+}
+
+// ParseScoreBands 解析计分方案的等级划分
+func ParseScoreBands(profile *models.ScoringProfile) []models.ScoreBand {
+	if profile == nil {
+		return defaultScoreBands
+	}
+	var bands []models.ScoreBand
+	if err := json.Unmarshal([]byte(profile.Bands), &bands); err != nil || len(bands) == 0 {
+		return defaultScoreBands
+	}
+	return bands
+}
+
+// findBand 按得分百分比查找所在等级
+func findBand(bands []models.ScoreBand, percentage float64) models.ScoreBand {
+	band := bands[0]
+	for _, b := range bands {
+		if percentage >= b.MinPercentage {
+			band = b
+		}
+	}
+	return band
+}
+
+// bandText 等级在指定语言下的文本，方案中没有该语言的文本时返回false
+func bandText(bands []models.ScoreBand, level, locale string) (models.ScoreBandText, bool) {
+	for _, band := range bands {
+		if band.Level == level {
+			text, ok := band.Texts[locale]
+			return text, ok && text.Description != ""
+		}
+	}
+	return models.ScoreBandText{}, false
+}
+
+// MaxScore 按题目集合计算满分：选择题为最大选项序号乘以权重，文本题不计分
+func MaxScore(questions []models.Question) int {
+	var max int
+	for _, question := range questions {
+		if question.Type == models.QuestionTypeText {
+			continue
+		}
+		max += CalculateQuestionScore(question.Score, optionCount(question))
+	}
+	return max
+}
+
+// scorePercentage 得分占满分的百分比（保留一位小数），满分为0时返回0
+func scorePercentage(score, maxScore int) float64 {
+	if maxScore <= 0 {
+		return 0
+	}
+	return math.Round(float64(score)/float64(maxScore)*1000) / 10
+}
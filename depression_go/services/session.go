@@ -0,0 +1,165 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"depression_go/internal/models"
+
+	"gorm.io/gorm"
+)
+
+var ErrSessionNotFound = errors.New("会话不存在或已失效")
+
+// SessionService 登录会话管理：列出用户在哪些设备上登录，移除指定会话或其他全部会话
+type SessionService struct {
+	db *gorm.DB
+}
+
+// NewSessionService 创建会话管理服务
+func NewSessionService(db *gorm.DB) *SessionService {
+	return &SessionService{db: db}
+}
+
+// List 列出用户未失效的会话，按最近活跃时间倒序，currentID为发起请求的会话
+func (s *SessionService) List(userID uint, currentID string) ([]models.SessionResponse, error) {
+	var sessions []models.UserSession
+	if err := s.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]models.SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		result = append(result, models.SessionResponse{
+			ID:         session.ID,
+			Device:     session.Device,
+			UserAgent:  session.UserAgent,
+			IP:         session.IP,
+			Current:    session.ID == currentID,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			ExpiresAt:  session.ExpiresAt,
+		})
+	}
+	return result, nil
+}
+
+// Revoke 移除用户的一个会话，移除当前会话等同于注销
+func (s *SessionService) Revoke(userID uint, sessionID string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.UserSession{}).
+			Where("id = ? AND user_id = ? AND revoked_at IS NULL", sessionID, userID).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return ErrSessionNotFound
+		}
+		return revokeSessions(tx, models.RevokeReasonRevoked, "id = ?", sessionID)
+	})
+}
+
+// RevokeOthers 移除除当前会话以外的所有会话，返回移除的数量
+func (s *SessionService) RevokeOthers(userID uint, currentID string) (int, error) {
+	var count int
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var ids []string
+		if err := tx.Model(&models.UserSession{}).
+			Where("user_id = ? AND id <> ? AND revoked_at IS NULL", userID, currentID).
+			Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		count = len(ids)
+		if count == 0 {
+			return nil
+		}
+		return revokeSessions(tx, models.RevokeReasonRevoked, "id IN ?", ids)
+	})
+	return count, err
+}
+
+// revokeSessions 吊销符合条件且未失效的会话及其刷新令牌
+func revokeSessions(tx *gorm.DB, reason string, query string, args ...interface{}) error {
+	var ids []string
+	if err := tx.Model(&models.UserSession{}).
+		Where(query, args...).
+		Where("revoked_at IS NULL").
+		Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	if err := tx.Model(&models.UserSession{}).
+		Where("id IN ?", ids).
+		Updates(map[string]interface{}{"revoked_at": now, "revoke_reason": reason}).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.RefreshToken{}).
+		Where("session_id IN ? AND revoked_at IS NULL", ids).
+		Updates(map[string]interface{}{"revoked_at": now, "revoke_reason": reason}).Error
+}
+
+// DeviceName 由User-Agent粗略识别浏览器和操作系统，如 Chrome / Windows；无法识别时返回空字符串
+func DeviceName(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	if ua == "" {
+		return ""
+	}
+
+	browser := ""
+	switch {
+	case strings.Contains(ua, "micromessenger"):
+		browser = "微信"
+	case strings.Contains(ua, "edg/"):
+		browser = "Edge"
+	case strings.Contains(ua, "opr/") || strings.Contains(ua, "opera"):
+		browser = "Opera"
+	case strings.Contains(ua, "firefox/"):
+		browser = "Firefox"
+	case strings.Contains(ua, "chrome/") || strings.Contains(ua, "crios/"):
+		browser = "Chrome"
+	case strings.Contains(ua, "safari/"):
+		browser = "Safari"
+	case strings.Contains(ua, "curl/"):
+		browser = "curl"
+	}
+
+	system := ""
+	switch {
+	case strings.Contains(ua, "iphone") || strings.Contains(ua, "ipad"):
+		system = "iOS"
+	case strings.Contains(ua, "android"):
+		system = "Android"
+	case strings.Contains(ua, "windows"):
+		system = "Windows"
+	case strings.Contains(ua, "mac os x") || strings.Contains(ua, "macintosh"):
+		system = "macOS"
+	case strings.Contains(ua, "linux"):
+		system = "Linux"
+	}
+
+	switch {
+	case browser != "" && system != "":
+		return browser + " / " + system
+	case browser != "":
+		return browser
+	default:
+		return system
+	}
+}
+
+// truncate 按字符截断字符串
+func truncate(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	return string([]rune(s)[:max])
+}
@@ -0,0 +1,250 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"time"
+
+	"depression_go/configs"
+	"depression_go/internal/models"
+	"depression_go/pkg/token"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("刷新令牌无效或已过期")
+	ErrRefreshTokenReused  = errors.New("刷新令牌已被使用，请重新登录")
+	ErrUserDisabled        = errors.New("账户已被禁用")
+)
+
+// tokenPurgeInterval 清理过期的刷新令牌和会话的间隔
+const tokenPurgeInterval = time.Hour
+
+// ClientInfo 发起登录或刷新的客户端
+type ClientInfo struct {
+	UserAgent string
+	IP        string
+}
+
+// TokenService 令牌服务：登录时创建会话，签发短期访问令牌和可轮换的刷新令牌
+type TokenService struct {
+	db         *gorm.DB
+	refreshTTL time.Duration
+}
+
+// NewTokenService 创建令牌服务
+func NewTokenService(db *gorm.DB) *TokenService {
+	s := &TokenService{db: db, refreshTTL: 30 * 24 * time.Hour}
+	if configs.GlobalConfig != nil && configs.GlobalConfig.JWT.RefreshTTLHours > 0 {
+		s.refreshTTL = time.Duration(configs.GlobalConfig.JWT.RefreshTTLHours) * time.Hour
+	}
+	return s
+}
+
+// Issue 登录时创建会话，签发访问令牌和刷新令牌
+func (s *TokenService) Issue(user *models.User, client ClientInfo) (*models.TokenPair, error) {
+	var pair *models.TokenPair
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		session := models.UserSession{
+			ID:         uuid.New().String(),
+			UserID:     user.ID,
+			UserAgent:  truncate(client.UserAgent, 255),
+			Device:     DeviceName(client.UserAgent),
+			IP:         client.IP,
+			LastSeenAt: now,
+			ExpiresAt:  now.Add(s.refreshTTL),
+		}
+		if err := tx.Create(&session).Error; err != nil {
+			return err
+		}
+
+		var err error
+		pair, err = s.issue(tx, user, &session)
+		return err
+	})
+	return pair, err
+}
+
+// Refresh 用刷新令牌换取新的令牌对，旧的刷新令牌随即失效；
+// 已轮换的刷新令牌再次出现说明可能被盗用，吊销整个会话
+func (s *TokenService) Refresh(raw string, client ClientInfo) (*models.TokenPair, error) {
+	var pair *models.TokenPair
+	var reused bool
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var current models.RefreshToken
+		if err := tx.Where("token_hash = ?", hashToken(raw)).First(&current).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvalidRefreshToken
+			}
+			return err
+		}
+		if current.RevokedAt != nil {
+			if current.RevokeReason == models.RevokeReasonRotated {
+				reused = true
+				return revokeSessions(tx, models.RevokeReasonReuse, "id = ?", current.SessionID)
+			}
+			return ErrInvalidRefreshToken
+		}
+		if time.Now().After(current.ExpiresAt) {
+			return ErrInvalidRefreshToken
+		}
+
+		var session models.UserSession
+		if err := tx.Where("id = ? AND revoked_at IS NULL", current.SessionID).First(&session).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvalidRefreshToken
+			}
+			return err
+		}
+		var user models.User
+		if err := tx.First(&user, current.UserID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvalidRefreshToken
+			}
+			return err
+		}
+		if user.Status == 0 {
+			return ErrUserDisabled
+		}
+
+		// 只有一个并发请求能完成轮换，其余按重复使用处理
+		result := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND revoked_at IS NULL", current.ID).
+			Updates(map[string]interface{}{"revoked_at": time.Now(), "revoke_reason": models.RevokeReasonRotated})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			reused = true
+			return revokeSessions(tx, models.RevokeReasonReuse, "id = ?", session.ID)
+		}
+
+		session.LastSeenAt = time.Now()
+		session.ExpiresAt = session.LastSeenAt.Add(s.refreshTTL)
+		if client.IP != "" {
+			session.IP = client.IP
+		}
+		if err := tx.Model(&session).Updates(map[string]interface{}{
+			"last_seen_at": session.LastSeenAt,
+			"expires_at":   session.ExpiresAt,
+			"ip":           session.IP,
+		}).Error; err != nil {
+			return err
+		}
+
+		var err error
+		pair, err = s.issue(tx, &user, &session)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if reused {
+		return nil, ErrRefreshTokenReused
+	}
+	return pair, nil
+}
+
+// Logout 吊销当前会话，该会话的访问令牌和刷新令牌随即失效
+func (s *TokenService) Logout(claims *token.Claims) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return revokeSessions(tx, models.RevokeReasonLogout, "id = ? AND user_id = ?", claims.ID, claims.UserID)
+	})
+}
+
+// RevokeUser 吊销用户的所有会话（修改密码、禁用账户时使用）
+func (s *TokenService) RevokeUser(tx *gorm.DB, userID uint, reason string) error {
+	return revokeSessions(tx, reason, "user_id = ?", userID)
+}
+
+// PurgeExpired 删除已过期的刷新令牌、会话、邮件链接令牌和单点登录state
+func (s *TokenService) PurgeExpired() (int64, error) {
+	now := time.Now()
+	refresh := s.db.Unscoped().Where("expires_at < ?", now).Delete(&models.RefreshToken{})
+	if refresh.Error != nil {
+		return 0, refresh.Error
+	}
+	sessions := s.db.Where("expires_at < ?", now).Delete(&models.UserSession{})
+	if sessions.Error != nil {
+		return 0, sessions.Error
+	}
+	userTokens := s.db.Unscoped().Where("expires_at < ?", now).Delete(&models.UserToken{})
+	if userTokens.Error != nil {
+		return 0, userTokens.Error
+	}
+	states := s.db.Where("expires_at < ?", now).Delete(&models.OIDCState{})
+	return refresh.RowsAffected + sessions.RowsAffected + userTokens.RowsAffected + states.RowsAffected, states.Error
+}
+
+// StartPurge 后台定期清理过期令牌，ctx取消时退出
+func (s *TokenService) StartPurge(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(tokenPurgeInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				count, err := s.PurgeExpired()
+				if err != nil {
+					log.Printf("清理过期令牌失败: %v", err)
+				} else if count > 0 {
+					log.Printf("已清理%d条过期令牌记录", count)
+				}
+			}
+		}
+	}()
+}
+
+// issue 签发该会话的访问令牌，并保存新的刷新令牌
+func (s *TokenService) issue(tx *gorm.DB, user *models.User, session *models.UserSession) (*models.TokenPair, error) {
+	accessToken, err := token.GenerateToken(user.ID, user.Username, user.Role, user.TokenVersion, session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := newRandomToken()
+	if err != nil {
+		return nil, err
+	}
+	refresh := models.RefreshToken{
+		UserID:    user.ID,
+		TokenHash: hashToken(raw),
+		SessionID: session.ID,
+		ExpiresAt: session.ExpiresAt,
+	}
+	if err := tx.Create(&refresh).Error; err != nil {
+		return nil, err
+	}
+
+	return &models.TokenPair{
+		Token:            accessToken,
+		ExpiresIn:        int(token.AccessTTL().Seconds()),
+		RefreshToken:     raw,
+		RefreshExpiresAt: refresh.ExpiresAt,
+	}, nil
+}
+
+// newRandomToken 生成随机令牌（32字节，base64url编码），用于刷新令牌和邮件链接
+func newRandomToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// hashToken 令牌在数据库中只保存SHA-256
+func hashToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
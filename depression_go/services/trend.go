@@ -0,0 +1,309 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"depression_go/configs"
+	"depression_go/internal/models"
+
+	"gorm.io/gorm"
+)
+
+var ErrInstrumentMismatch = errors.New("两次评估不是同一量表，无法比较")
+
+const (
+	// rciThreshold |RCI|不低于该值（95%置信）视为可靠变化
+	rciThreshold = 1.96
+	// defaultNormSD 计分方案未设置时使用的常模标准差（得分百分比）
+	defaultNormSD = 20
+	// defaultReliability 计分方案未设置时使用的信度
+	defaultReliability = 0.85
+)
+
+// TrendService 纵向趋势服务：按量表和分量表给出得分序列，
+// 用可靠变化指数（Jacobson-Truax）判断两次评估间的变化，并标记最近几次评估的持续恶化
+type TrendService struct {
+	db       *gorm.DB
+	window   int
+	scoring  *ScoringService
+	versions *QuestionnaireVersionService
+}
+
+// NewTrendService 创建纵向趋势服务
+func NewTrendService(db *gorm.DB) *TrendService {
+	s := &TrendService{
+		db:       db,
+		window:   3,
+		scoring:  NewScoringService(db),
+		versions: NewQuestionnaireVersionService(db),
+	}
+	if configs.GlobalConfig != nil && configs.GlobalConfig.Trend.DeteriorationWindow > 1 {
+		s.window = configs.GlobalConfig.Trend.DeteriorationWindow
+	}
+	return s
+}
+
+// Params 当前计分方案中的量表参数，未设置的参数使用默认值；临床界值默认为第一个非最低等级的下限
+func (s *TrendService) Params() (models.ChangeParams, error) {
+	profile, err := s.scoring.Current(s.db)
+	if err != nil {
+		return models.ChangeParams{}, err
+	}
+
+	params := models.ChangeParams{
+		ScoringProfileID: profile.ID,
+		NormSD:           profile.NormSD,
+		Reliability:      profile.Reliability,
+		ClinicalCutoff:   profile.ClinicalCutoff,
+	}
+	if params.NormSD <= 0 {
+		params.NormSD = defaultNormSD
+	}
+	if params.Reliability <= 0 {
+		params.Reliability = defaultReliability
+	}
+	if params.ClinicalCutoff <= 0 {
+		if bands := ParseScoreBands(profile); len(bands) > 1 {
+			params.ClinicalCutoff = bands[1].MinPercentage
+		}
+	}
+	params.SDiff = roundTo(math.Sqrt2*params.NormSD*math.Sqrt(1-params.Reliability), 3)
+	return params, nil
+}
+
+// Trends 用户已完成评估的纵向趋势，默认跳过低质量评估
+func (s *TrendService) Trends(userID uint, includeLowQuality bool) (*models.TrendResponse, error) {
+	params, err := s.Params()
+	if err != nil {
+		return nil, err
+	}
+
+	query := s.db.Where("user_id = ? AND status = ? AND type NOT IN ?",
+		userID, models.AssessmentStatusCompleted, nonQuestionnaireTypes)
+	if !includeLowQuality {
+		query = query.Where("(quality_flag IS NULL OR quality_flag <> ?)", models.QualityFlagLow)
+	}
+	var assessments []models.Assessment
+	if err := query.Order("created_at ASC, id ASC").Find(&assessments).Error; err != nil {
+		return nil, err
+	}
+
+	subscales, err := s.subscales(assessments)
+	if err != nil {
+		return nil, err
+	}
+
+	// 按量表分组，保持首次出现的顺序
+	var order []string
+	groups := make(map[string][]models.Assessment)
+	for _, assessment := range assessments {
+		instrument := instrumentOf(assessment)
+		if _, ok := groups[instrument]; !ok {
+			order = append(order, instrument)
+		}
+		groups[instrument] = append(groups[instrument], assessment)
+	}
+
+	result := &models.TrendResponse{UserID: userID, Params: params, Instruments: make([]models.InstrumentTrend, 0, len(order))}
+	for _, instrument := range order {
+		result.Instruments = append(result.Instruments, s.instrumentTrend(params, instrument, groups[instrument], subscales))
+	}
+	return result, nil
+}
+
+// Compare 比较用户的两次评估，两次评估必须已完成且属于同一量表
+func (s *TrendService) Compare(userID, fromID, toID uint) (*models.ReliableChange, *models.ChangeParams, error) {
+	var pair [2]models.Assessment
+	for i, id := range []uint{fromID, toID} {
+		if err := s.db.Where("id = ? AND user_id = ?", id, userID).First(&pair[i]).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, nil, ErrAssessmentNotFound
+			}
+			return nil, nil, err
+		}
+		if pair[i].Status != models.AssessmentStatusCompleted {
+			return nil, nil, ErrAssessmentNotCompleted
+		}
+		if !isQuestionnaireType(pair[i].Type) {
+			return nil, nil, ErrNotQuestionnaire
+		}
+	}
+	if instrumentOf(pair[0]) != instrumentOf(pair[1]) {
+		return nil, nil, ErrInstrumentMismatch
+	}
+
+	params, err := s.Params()
+	if err != nil {
+		return nil, nil, err
+	}
+	change := ReliableChangeBetween(params, trendPoint(pair[0]), trendPoint(pair[1]))
+	return &change, &params, nil
+}
+
+// instrumentTrend 单个量表的序列、相邻变化、基线变化和持续恶化标记
+func (s *TrendService) instrumentTrend(params models.ChangeParams, instrument string, assessments []models.Assessment, subscales map[uint]map[string]models.TrendPoint) models.InstrumentTrend {
+	trend := models.InstrumentTrend{
+		Instrument: instrument,
+		Series:     make([]models.TrendPoint, 0, len(assessments)),
+		Changes:    []models.ReliableChange{},
+	}
+	for i, assessment := range assessments {
+		trend.Series = append(trend.Series, trendPoint(assessment))
+		if i > 0 {
+			trend.Changes = append(trend.Changes, ReliableChangeBetween(params, trend.Series[i-1], trend.Series[i]))
+		}
+	}
+	if len(trend.Series) > 1 {
+		baseline := ReliableChangeBetween(params, trend.Series[0], trend.Series[len(trend.Series)-1])
+		trend.Baseline = &baseline
+	}
+
+	// 分量表序列，按分类名排序
+	byCategory := make(map[string][]models.TrendPoint)
+	for _, assessment := range assessments {
+		for category, point := range subscales[assessment.ID] {
+			byCategory[category] = append(byCategory[category], point)
+		}
+	}
+	for category, points := range byCategory {
+		trend.Subscales = append(trend.Subscales, models.SubscaleSeries{Category: category, Points: points})
+	}
+	sort.Slice(trend.Subscales, func(i, j int) bool { return trend.Subscales[i].Category < trend.Subscales[j].Category })
+
+	trend.Deterioration = s.deterioration(params, trend.Series)
+	return trend
+}
+
+// deterioration 最近window次评估得分没有下降，且首尾之间是可靠恶化时标记为持续恶化
+func (s *TrendService) deterioration(params models.ChangeParams, series []models.TrendPoint) models.Deterioration {
+	result := models.Deterioration{Window: s.window, AssessmentIDs: []uint{}}
+	recent := series
+	if len(recent) > s.window {
+		recent = recent[len(recent)-s.window:]
+	}
+	for _, point := range recent {
+		result.AssessmentIDs = append(result.AssessmentIDs, point.AssessmentID)
+	}
+	if len(recent) < s.window {
+		return result
+	}
+
+	for i := 1; i < len(recent); i++ {
+		if recent[i].Percentage < recent[i-1].Percentage {
+			return result
+		}
+	}
+	change := ReliableChangeBetween(params, recent[0], recent[len(recent)-1])
+	result.Change = &change
+	result.Flagged = change.Classification == models.ChangeDeteriorated
+	return result
+}
+
+// subscales 按作答时的题目分类汇总每次评估的分量表得分
+func (s *TrendService) subscales(assessments []models.Assessment) (map[uint]map[string]models.TrendPoint, error) {
+	result := make(map[uint]map[string]models.TrendPoint, len(assessments))
+	if len(assessments) == 0 {
+		return result, nil
+	}
+	ids := make([]uint, 0, len(assessments))
+	byID := make(map[uint]models.Assessment, len(assessments))
+	for _, assessment := range assessments {
+		ids = append(ids, assessment.ID)
+		byID[assessment.ID] = assessment
+	}
+
+	var answers []models.Answer
+	if err := s.db.Where("assessment_id IN ?", ids).Order("id ASC").Find(&answers).Error; err != nil {
+		return nil, err
+	}
+	questions, err := s.versions.AnsweredQuestions(s.db, answers)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, answer := range answers {
+		question := questions[i]
+		if question.Type == models.QuestionTypeText || question.Category == "" {
+			continue
+		}
+		if result[answer.AssessmentID] == nil {
+			result[answer.AssessmentID] = make(map[string]models.TrendPoint)
+		}
+		point, ok := result[answer.AssessmentID][question.Category]
+		if !ok {
+			assessment := byID[answer.AssessmentID]
+			point = models.TrendPoint{AssessmentID: assessment.ID, Date: assessmentDate(assessment)}
+		}
+		point.Score += answer.Score
+		point.MaxScore += CalculateQuestionScore(question.Score, optionCount(question))
+		point.Percentage = scorePercentage(point.Score, point.MaxScore)
+		result[answer.AssessmentID][question.Category] = point
+	}
+	return result, nil
+}
+
+// ReliableChangeBetween 计算两次评估之间的可靠变化指数：RCI = (后 - 前) / Sdiff；
+// 可靠改善且从临床界值以上降到界值以下为recovered，可靠恶化且从界值以下升到界值以上为临床显著恶化
+func ReliableChangeBetween(params models.ChangeParams, from, to models.TrendPoint) models.ReliableChange {
+	change := models.ReliableChange{
+		FromAssessmentID: from.AssessmentID,
+		ToAssessmentID:   to.AssessmentID,
+		FromPercentage:   from.Percentage,
+		ToPercentage:     to.Percentage,
+		Difference:       roundTo(to.Percentage-from.Percentage, 1),
+		Classification:   models.ChangeUnchanged,
+	}
+	if params.SDiff > 0 {
+		change.RCI = roundTo((to.Percentage-from.Percentage)/params.SDiff, 2)
+	}
+	change.Reliable = math.Abs(change.RCI) >= rciThreshold
+
+	switch {
+	case change.Reliable && change.RCI < 0:
+		change.Classification = models.ChangeImproved
+		if from.Percentage >= params.ClinicalCutoff && to.Percentage < params.ClinicalCutoff {
+			change.ClinicallySignificant = true
+			change.Classification = models.ChangeRecovered
+		}
+	case change.Reliable && change.RCI > 0:
+		change.Classification = models.ChangeDeteriorated
+		change.ClinicallySignificant = from.Percentage < params.ClinicalCutoff && to.Percentage >= params.ClinicalCutoff
+	}
+	return change
+}
+
+// instrumentOf 评估所属的量表：按评估类型区分，自适应测验的得分尺度不同，单独成列
+func instrumentOf(assessment models.Assessment) string {
+	if assessment.Mode == models.AssessmentModeAdaptive {
+		return assessment.Type + ":" + models.AssessmentModeAdaptive
+	}
+	return assessment.Type
+}
+
+// trendPoint 评估的总分，满分未记录的历史评估按原分数作为百分比
+func trendPoint(assessment models.Assessment) models.TrendPoint {
+	percentage := float64(assessment.TotalScore)
+	if assessment.MaxScore > 0 {
+		percentage = scorePercentage(assessment.TotalScore, assessment.MaxScore)
+	}
+	return models.TrendPoint{
+		AssessmentID: assessment.ID,
+		Date:         assessmentDate(assessment),
+		Score:        assessment.TotalScore,
+		MaxScore:     assessment.MaxScore,
+		Percentage:   percentage,
+		Level:        assessment.Level,
+		QualityFlag:  assessment.QualityFlag,
+	}
+}
+
+// assessmentDate 评估完成时间，未记录时使用创建时间
+func assessmentDate(assessment models.Assessment) time.Time {
+	if assessment.CompletedAt != nil {
+		return *assessment.CompletedAt
+	}
+	return assessment.CreatedAt
+}
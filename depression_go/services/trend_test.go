@@ -0,0 +1,53 @@
+package services
+
+import (
+	"testing"
+
+	"depression_go/internal/models"
+)
+
+func TestReliableChangeBetween(t *testing.T) {
+	params := models.ChangeParams{SDiff: 10, ClinicalCutoff: 50}
+	tests := []struct {
+		name         string
+		params       models.ChangeParams
+		from, to     float64
+		wantRCI      float64
+		wantReliable bool
+		wantClinical bool
+		wantClass    string
+	}{
+		{"改善并降到界值以下", params, 60, 30, -3, true, true, models.ChangeRecovered},
+		{"改善但仍在界值以上", params, 80, 55, -2.5, true, false, models.ChangeImproved},
+		{"变化在测量误差内", params, 40, 55, 1.5, false, false, models.ChangeUnchanged},
+		{"恶化并升到界值以上", params, 40, 70, 3, true, true, models.ChangeDeteriorated},
+		{"恶化但原本已在界值以上", params, 60, 85, 2.5, true, false, models.ChangeDeteriorated},
+		{"恰好达到1.96", params, 50, 69.6, 1.96, true, false, models.ChangeDeteriorated},
+		{"没有量表参数时不判断", models.ChangeParams{ClinicalCutoff: 50}, 80, 10, 0, false, false, models.ChangeUnchanged},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from := models.TrendPoint{AssessmentID: 1, Percentage: tt.from}
+			to := models.TrendPoint{AssessmentID: 2, Percentage: tt.to}
+			got := ReliableChangeBetween(tt.params, from, to)
+			if got.RCI != tt.wantRCI {
+				t.Errorf("RCI = %v, want %v", got.RCI, tt.wantRCI)
+			}
+			if got.Reliable != tt.wantReliable {
+				t.Errorf("Reliable = %v, want %v", got.Reliable, tt.wantReliable)
+			}
+			if got.ClinicallySignificant != tt.wantClinical {
+				t.Errorf("ClinicallySignificant = %v, want %v", got.ClinicallySignificant, tt.wantClinical)
+			}
+			if got.Classification != tt.wantClass {
+				t.Errorf("Classification = %q, want %q", got.Classification, tt.wantClass)
+			}
+			if got.Difference != roundTo(tt.to-tt.from, 1) {
+				t.Errorf("Difference = %v, want %v", got.Difference, roundTo(tt.to-tt.from, 1))
+			}
+			if got.FromAssessmentID != 1 || got.ToAssessmentID != 2 {
+				t.Errorf("评估ID = %d -> %d, want 1 -> 2", got.FromAssessmentID, got.ToAssessmentID)
+			}
+		})
+	}
+}
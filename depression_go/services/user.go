@@ -0,0 +1,335 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"depression_go/internal/models"
+	"depression_go/pkg/utils"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUserNotFound   = errors.New("用户不存在")
+	ErrSelfManagement = errors.New("不能修改自己的角色或状态")
+	ErrInvalidProfile = errors.New("用户信息不合法")
+	ErrWrongPassword  = errors.New("当前密码错误")
+	ErrWeakPassword   = errors.New("新密码不符合要求")
+)
+
+const auditResourceUser = "user"
+
+// phonePattern 中国大陆手机号
+var phonePattern = regexp.MustCompile(`^1[3-9]\d{9}$`)
+
+var genders = []string{models.GenderMale, models.GenderFemale, models.GenderOther, models.GenderUnknown}
+
+const (
+	minAge = 1
+	maxAge = 120
+)
+
+// UserService 用户资料服务：修改资料、头像和密码
+type UserService struct {
+	db     *gorm.DB
+	tokens *TokenService
+	rbac   *RBACService
+}
+
+// UserFilter 用户列表的筛选条件
+type UserFilter struct {
+	Role    string
+	Status  *int
+	Keyword string // 按用户名或邮箱模糊匹配
+}
+
+// NewUserService 创建用户资料服务
+func NewUserService(db *gorm.DB) *UserService {
+	return &UserService{db: db, tokens: NewTokenService(db), rbac: NewRBACService(db)}
+}
+
+// Get 获取用户
+func (s *UserService) Get(userID uint) (*models.User, error) {
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// UpdateProfile 校验并更新用户资料，只修改请求中给出的字段；age为0、phone和avatar为空表示清除
+func (s *UserService) UpdateProfile(userID uint, req models.UserUpdateRequest) (*models.User, error) {
+	user, err := s.Get(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := make(map[string]interface{})
+	if req.Age != nil {
+		if *req.Age != 0 && (*req.Age < minAge || *req.Age > maxAge) {
+			return nil, fmt.Errorf("%w: 年龄必须在%d-%d之间", ErrInvalidProfile, minAge, maxAge)
+		}
+		updates["age"] = *req.Age
+	}
+	if req.Gender != nil {
+		gender := strings.TrimSpace(*req.Gender)
+		if !containsString(genders, gender) {
+			return nil, fmt.Errorf("%w: 性别可选: %s", ErrInvalidProfile, strings.Join(genders, "、"))
+		}
+		updates["gender"] = gender
+	}
+	if req.Phone != nil {
+		phone := strings.TrimSpace(*req.Phone)
+		if phone != "" && !phonePattern.MatchString(phone) {
+			return nil, fmt.Errorf("%w: 手机号格式不正确", ErrInvalidProfile)
+		}
+		updates["phone"] = phone
+	}
+	if req.Avatar != nil {
+		avatar := strings.TrimSpace(*req.Avatar)
+		if avatar != "" && !validAvatar(userID, avatar) {
+			return nil, fmt.Errorf("%w: 头像必须是本人上传后返回的地址或http(s)链接", ErrInvalidProfile)
+		}
+		updates["avatar"] = avatar
+	}
+	if len(updates) == 0 {
+		return user, nil
+	}
+
+	oldAvatar := user.Avatar
+	if err := s.db.Model(user).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+	if avatar, ok := updates["avatar"].(string); ok {
+		removeOldAvatar(userID, oldAvatar, avatar)
+	}
+	return s.Get(userID)
+}
+
+// SetAvatar 设置头像地址（头像图片由上传接口保存）
+func (s *UserService) SetAvatar(userID uint, avatarURL string) (*models.User, error) {
+	user, err := s.Get(userID)
+	if err != nil {
+		return nil, err
+	}
+	oldAvatar := user.Avatar
+	if err := s.db.Model(user).Update("avatar", avatarURL).Error; err != nil {
+		return nil, err
+	}
+	removeOldAvatar(userID, oldAvatar, avatarURL)
+	user.Avatar = avatarURL
+	return user, nil
+}
+
+// ChangePassword 校验当前密码后修改密码，递增令牌版本并吊销刷新令牌，使之前签发的令牌全部失效
+func (s *UserService) ChangePassword(userID uint, req models.UserPasswordRequest) (*models.User, error) {
+	user, err := s.Get(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !utils.CheckPassword(req.OldPassword, user.Password) {
+		return nil, ErrWrongPassword
+	}
+	if valid, msg := utils.ValidatePassword(req.NewPassword, user.Username, user.Email); !valid {
+		return nil, fmt.Errorf("%w: %s", ErrWeakPassword, msg)
+	}
+	if req.NewPassword == req.OldPassword {
+		return nil, fmt.Errorf("%w: 新密码不能与当前密码相同", ErrWeakPassword)
+	}
+
+	hashedPassword, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		return nil, err
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(user).Updates(map[string]interface{}{
+			"password":      hashedPassword,
+			"token_version": gorm.Expr("token_version + 1"),
+		}).Error; err != nil {
+			return err
+		}
+		return s.tokens.RevokeUser(tx, userID, models.RevokeReasonPassword)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(userID)
+}
+
+// UpgradePasswordHash 登录时密码校验通过后，旧格式（bcrypt）或旧参数的哈希用明文重新加密；失败只记录日志，不影响登录
+func (s *UserService) UpgradePasswordHash(user *models.User, password string) {
+	if !utils.NeedsRehash(user.Password) {
+		return
+	}
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		log.Printf("升级密码哈希失败(用户%d): %v", user.ID, err)
+		return
+	}
+	// 只在哈希未被并发修改时更新，不递增令牌版本
+	result := s.db.Model(&models.User{}).
+		Where("id = ? AND password = ?", user.ID, user.Password).
+		Update("password", hashedPassword)
+	if result.Error != nil {
+		log.Printf("升级密码哈希失败(用户%d): %v", user.ID, result.Error)
+		return
+	}
+	user.Password = hashedPassword
+}
+
+// List 分页列出用户，按注册时间倒序
+func (s *UserService) List(filter UserFilter, page, pageSize int) ([]models.User, int64, error) {
+	query := s.db.Model(&models.User{})
+	if filter.Role != "" {
+		query = query.Where("role = ?", filter.Role)
+	}
+	if filter.Status != nil {
+		query = query.Where("status = ?", *filter.Status)
+	}
+	if filter.Keyword != "" {
+		like := "%" + filter.Keyword + "%"
+		query = query.Where("username LIKE ? OR email LIKE ?", like, like)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var users []models.User
+	err := query.Order("created_at DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&users).Error
+	return users, total, err
+}
+
+// SetRole 修改用户角色，并递增令牌版本使新角色在下次刷新令牌时生效
+func (s *UserService) SetRole(actx AuditContext, userID uint, role string) (*models.User, error) {
+	if userID == actx.ActorID {
+		return nil, ErrSelfManagement
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		exists, err := s.rbac.roleExists(tx, role)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrRoleNotFound
+		}
+
+		var user models.User
+		if err := tx.First(&user, userID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrUserNotFound
+			}
+			return err
+		}
+		if user.Role == role {
+			return nil
+		}
+		before := user
+		if err := tx.Model(&user).Updates(map[string]interface{}{
+			"role":          role,
+			"token_version": gorm.Expr("token_version + 1"),
+		}).Error; err != nil {
+			return err
+		}
+		user.Role = role
+		if err := RecordAudit(tx, actx, "role", auditResourceUser, userID, before, user); err != nil {
+			return err
+		}
+
+		// 新角色要求两步验证而该用户尚未启用时，登录随即失效，下次登录需要先完成绑定
+		var target models.Role
+		if err := tx.Select("require_mfa").Where("name = ?", role).First(&target).Error; err != nil {
+			return err
+		}
+		if !target.RequireMFA {
+			return nil
+		}
+		return revokeWithoutMFA(tx, tx.Model(&models.User{}).Select("id").Where("id = ?", userID))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(userID)
+}
+
+// SetStatus 启用或禁用用户，禁用时吊销其所有会话
+func (s *UserService) SetStatus(actx AuditContext, userID uint, status int) (*models.User, error) {
+	if userID == actx.ActorID {
+		return nil, ErrSelfManagement
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.First(&user, userID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrUserNotFound
+			}
+			return err
+		}
+		if user.Status == status {
+			return nil
+		}
+		before := user
+		if err := tx.Model(&user).Update("status", status).Error; err != nil {
+			return err
+		}
+		user.Status = status
+		if status == 0 {
+			if err := s.tokens.RevokeUser(tx, userID, models.RevokeReasonDisabled); err != nil {
+				return err
+			}
+		}
+		return RecordAudit(tx, actx, "status", auditResourceUser, userID, before, user)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(userID)
+}
+
+// avatarPrefix 用户头像文件名前缀，上传接口按avatar_<userID>_<时间>_<随机串>命名
+func avatarPrefix(userID uint) string {
+	return fmt.Sprintf("avatar_%d_", userID)
+}
+
+// validAvatar 头像只接受本人上传到本站的文件或http(s)链接，不能引用他人上传的文件
+func validAvatar(userID uint, avatar string) bool {
+	if name, ok := strings.CutPrefix(avatar, "/uploads/"); ok {
+		return !strings.ContainsAny(name, `/\`) && strings.HasPrefix(name, avatarPrefix(userID))
+	}
+	u, err := url.Parse(avatar)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// removeOldAvatar 头像被替换后删除本人之前上传的头像文件，失败只记录日志
+func removeOldAvatar(userID uint, oldAvatar, newAvatar string) {
+	if oldAvatar == newAvatar || !strings.HasPrefix(oldAvatar, "/uploads/") || !validAvatar(userID, oldAvatar) {
+		return
+	}
+	path := filepath.Join(UploadDir(), filepath.Base(oldAvatar))
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		log.Printf("删除旧头像失败: user=%d path=%s err=%v", userID, path, err)
+	}
+}
+
+// containsString 判断切片中是否包含该字符串
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}